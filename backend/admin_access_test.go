@@ -0,0 +1,180 @@
+package main
+
+import (
+	"ai-project-backend/models"
+	"ai-project-backend/policy"
+	"ai-project-backend/utils"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// adminAccessCases covers callers with and without global visibility
+var adminAccessCases = []struct {
+	name      string
+	claims    *utils.JWTClaims
+	require2F bool
+	global    bool
+}{
+	{
+		name:   "user",
+		claims: &utils.JWTClaims{UserID: 7, Role: policy.RoleUser},
+	},
+	{
+		name:   "admin",
+		claims: &utils.JWTClaims{UserID: 1, Role: policy.RoleAdmin},
+		global: true,
+	},
+	{
+		name:      "admin with second factor when required",
+		claims:    &utils.JWTClaims{UserID: 1, Role: policy.RoleAdmin, MFA: true},
+		require2F: true,
+		global:    true,
+	},
+	{
+		name:      "admin without second factor when required",
+		claims:    &utils.JWTClaims{UserID: 1, Role: policy.RoleAdmin},
+		require2F: true,
+	},
+}
+
+// newAdminAccessApp creates an application with the given two-factor policy
+func newAdminAccessApp(t *testing.T, require2F bool) (*Application, *fakeDB) {
+	t.Helper()
+
+	db := newFakeDB()
+	settings := &models.SecuritySettings{RequireTwoFactorForAdmins: require2F}
+	if err := db.system.SetSetting(context.Background(), settingSecurity, settings, nil); err != nil {
+		t.Fatalf("SetSetting returned error: %v", err)
+	}
+	return newTestApplication(db), db
+}
+
+func TestGetProjectsAdminAccess(t *testing.T) {
+	for _, tt := range adminAccessCases {
+		t.Run(tt.name, func(t *testing.T) {
+			app, db := newAdminAccessApp(t, tt.require2F)
+
+			recorder := serve(t, tt.claims, http.MethodGet, "/projects", nil, app.getProjectsHandler)
+			requireStatus(t, recorder, http.StatusOK)
+
+			if db.projects.listedAll != tt.global {
+				t.Errorf("listed all projects = %v, want %v", db.projects.listedAll, tt.global)
+			}
+			if !tt.global && (db.projects.listedMember == nil || *db.projects.listedMember != tt.claims.UserID) {
+				t.Errorf("projects not listed for member %d", tt.claims.UserID)
+			}
+		})
+	}
+}
+
+func TestSearchAdminAccess(t *testing.T) {
+	for _, tt := range adminAccessCases {
+		t.Run(tt.name, func(t *testing.T) {
+			app, db := newAdminAccessApp(t, tt.require2F)
+
+			recorder := serve(t, tt.claims, http.MethodGet, "/search?q=model", nil, app.searchHandler)
+			requireStatus(t, recorder, http.StatusOK)
+
+			memberID := db.search.filter.MemberID
+			if tt.global && memberID != nil {
+				t.Errorf("search limited to member %d, want every project", *memberID)
+			}
+			if !tt.global && (memberID == nil || *memberID != tt.claims.UserID) {
+				t.Errorf("search not limited to member %d", tt.claims.UserID)
+			}
+		})
+	}
+}
+
+func TestGetMyTasksAdminAccess(t *testing.T) {
+	for _, tt := range adminAccessCases {
+		t.Run(tt.name, func(t *testing.T) {
+			app, db := newAdminAccessApp(t, tt.require2F)
+
+			recorder := serve(t, tt.claims, http.MethodGet, "/me/tasks", nil, app.getMyTasksHandler)
+			requireStatus(t, recorder, http.StatusOK)
+
+			if memberOnly := *db.tasks.assignedMemberOnly; memberOnly == tt.global {
+				t.Errorf("memberOnly = %v, want %v", memberOnly, !tt.global)
+			}
+		})
+	}
+}
+
+func TestTimelineAdminAccess(t *testing.T) {
+	for _, tt := range adminAccessCases {
+		t.Run(tt.name, func(t *testing.T) {
+			app, db := newAdminAccessApp(t, tt.require2F)
+			subjectID := db.users.add(&models.User{Username: "bob", Role: policy.RoleUser, IsActive: true})
+
+			requests := []struct {
+				name     string
+				recorder func() *httptest.ResponseRecorder
+				userID   int
+			}{
+				{
+					name: "me",
+					recorder: func() *httptest.ResponseRecorder {
+						return serve(t, tt.claims, http.MethodGet, "/me/timeline", nil, app.getMyTimelineHandler)
+					},
+					userID: tt.claims.UserID,
+				},
+				{
+					name: "user",
+					recorder: func() *httptest.ResponseRecorder {
+						target := fmt.Sprintf("/users/%d/timeline", subjectID)
+						return serveRoute(t, tt.claims, http.MethodGet, "/users/:id/timeline", target, nil, app.getUserTimelineHandler)
+					},
+					userID: subjectID,
+				},
+			}
+			for _, request := range requests {
+				requireStatus(t, request.recorder(), http.StatusOK)
+
+				filter := db.timeline.filter
+				if filter.UserID == nil || *filter.UserID != request.userID {
+					t.Errorf("%s: timeline not limited to the events of user %d", request.name, request.userID)
+				}
+				if tt.global && filter.MemberID != nil {
+					t.Errorf("%s: timeline limited to the projects of member %d, want every project", request.name, *filter.MemberID)
+				}
+				if !tt.global && (filter.MemberID == nil || *filter.MemberID != tt.claims.UserID) {
+					t.Errorf("%s: timeline not limited to the projects of member %d", request.name, tt.claims.UserID)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthorizeProjectAdminAccess(t *testing.T) {
+	for _, tt := range adminAccessCases {
+		if tt.claims.Role != policy.RoleAdmin {
+			continue
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			app, _ := newAdminAccessApp(t, tt.require2F)
+
+			var role string
+			recorder := serve(t, tt.claims, http.MethodGet, "/projects/1", nil, func(c *gin.Context) {
+				if granted, ok := app.authorizeProject(c, 1, policy.ProjectRoleViewer); ok {
+					role = granted
+					c.Status(http.StatusOK)
+				}
+			})
+
+			if tt.global {
+				requireStatus(t, recorder, http.StatusOK)
+				if role != policy.ProjectRoleOwner {
+					t.Errorf("role = %q, want owner", role)
+				}
+			} else {
+				requireStatus(t, recorder, http.StatusForbidden)
+			}
+		})
+	}
+}
\ No newline at end of file
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"ai-project-backend/models"
+	"ai-project-backend/policy"
+	"ai-project-backend/utils"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// apiTokenPrefix marks bearer tokens that are personal API tokens rather than JWTs
+	apiTokenPrefix = "pat_"
+
+	// defaultAPITokenDays is used when a token request does not set an expiry
+	defaultAPITokenDays = 90
+
+	// maxAPITokenDays is the longest lifetime a token may be created with
+	maxAPITokenDays = 365
+
+	// apiTokenUsageInterval is how often token use is recorded; last_used_at
+	// and the TOKEN_USE audit entries are written at most once per interval
+	apiTokenUsageInterval = 5 * time.Minute
+
+	// contextKeyAPIToken is the gin.Context key holding the API token used for the request
+	contextKeyAPIToken = "api_token"
+)
+
+// authenticateAPIToken resolves a personal API token into claims for the token's user.
+// The token's scopes must cover the route being called. It writes the error
+// response and returns false when the token is rejected.
+func (app *Application) authenticateAPIToken(c *gin.Context, tokenString string) (*utils.JWTClaims, bool) {
+	token, err := app.db.APITokens().GetByHash(c.Request.Context(), utils.HashToken(tokenString))
+	if err != nil {
+		if err.Error() == "API token not found" {
+			response := models.NewErrorResponse(models.ErrCodeAuthentication, "Invalid or expired token", nil)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, response)
+			return nil, false
+		}
+		app.logger.Printf("Error getting API token: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to verify token", nil)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response)
+		return nil, false
+	}
+
+	required, allowed := policy.RequiredScope(c.Request.Method, c.FullPath())
+	if !allowed || !policy.HasScope(token.Scopes, required) {
+		response := models.NewErrorResponse(
+			models.ErrCodeAuthorization,
+			"API token scope does not allow this request",
+			map[string]interface{}{"required_scope": required, "scopes": token.Scopes},
+		)
+		c.AbortWithStatusJSON(http.StatusForbidden, response)
+		return nil, false
+	}
+
+	user, err := app.db.Users().GetByID(c.Request.Context(), token.UserID)
+	if err == nil && !user.IsActive {
+		err = fmt.Errorf("user %d is deactivated", user.ID)
+	}
+	if err != nil {
+		app.logger.Printf("Error getting API token user: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeAuthentication, "Invalid or expired token", nil)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, response)
+		return nil, false
+	}
+
+	// Token use is not a login; record it once per interval so that scripts
+	// calling the API in a loop do not flood the audit log
+	if token.LastUsedAt == nil || time.Since(*token.LastUsedAt) >= apiTokenUsageInterval {
+		if err := app.db.APITokens().TouchLastUsed(c.Request.Context(), token.ID); err != nil {
+			app.logger.Printf("Error updating API token: %v", err)
+		}
+
+		entityData := map[string]interface{}{
+			"api_token_id": token.ID,
+			"method":       c.Request.Method,
+			"path":         c.Request.URL.Path,
+		}
+		if err := app.db.System().LogAction(c.Request.Context(), &user.ID, "TOKEN_USE", "user", user.ID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+			app.logger.Printf("Error writing audit log: %v", err)
+		}
+	}
+
+	c.Set(contextKeyAPIToken, token)
+
+	return &utils.JWTClaims{
+		UserID:   user.ID,
+		Username: user.Username,
+		Role:     user.Role,
+	}, true
+}
+
+func (app *Application) getAPITokensHandler(c *gin.Context) {
+	claims := currentUser(c)
+
+	tokens, err := app.db.APITokens().ListByUser(c.Request.Context(), claims.UserID)
+	if err != nil {
+		app.logger.Printf("Error getting API tokens: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to retrieve API tokens", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := models.NewSuccessResponse(tokens, "API tokens retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) createAPITokenHandler(c *gin.Context) {
+	var req models.APITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid request body", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validate required fields
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > 100 {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Token name is required (max 100 characters)", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	if len(req.Scopes) == 0 {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "At least one scope is required", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	for _, scope := range req.Scopes {
+		if !policy.IsValidScope(scope) {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid scope: "+scope, nil)
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+	}
+	if req.ExpiresInDays == 0 {
+		req.ExpiresInDays = defaultAPITokenDays
+	}
+	if req.ExpiresInDays < 0 || req.ExpiresInDays > maxAPITokenDays {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "expires_in_days must be between 1 and 365", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	secret, err := utils.GenerateRandomString(20)
+	if err != nil {
+		app.logger.Printf("Error generating API token: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to create API token", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	plainToken := apiTokenPrefix + secret
+
+	claims := currentUser(c)
+	token := &models.APIToken{
+		UserID:      claims.UserID,
+		Name:        req.Name,
+		TokenPrefix: plainToken[:len(apiTokenPrefix)+8],
+		TokenHash:   utils.HashToken(plainToken),
+		Scopes:      req.Scopes,
+		ExpiresAt:   time.Now().AddDate(0, 0, req.ExpiresInDays),
+	}
+
+	createdToken, err := app.db.APITokens().Create(c.Request.Context(), token)
+	if err != nil {
+		app.logger.Printf("Error creating API token: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to create API token", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	entityData := map[string]interface{}{"api_token_id": createdToken.ID, "name": createdToken.Name, "scopes": createdToken.Scopes}
+	if err := app.db.System().LogAction(c.Request.Context(), &claims.UserID, "CREATE", "user", claims.UserID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
+	result := models.APITokenCreateResponse{
+		APIToken: *createdToken,
+		Token:    plainToken,
+	}
+
+	response := models.NewSuccessResponse(result, "API token created successfully; store it now, it will not be shown again")
+	c.JSON(http.StatusCreated, response)
+}
+
+func (app *Application) revokeAPITokenHandler(c *gin.Context) {
+	tokenIDStr := c.Param("id")
+	tokenID, err := strconv.Atoi(tokenIDStr)
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid token ID", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	claims := currentUser(c)
+	err = app.db.APITokens().Revoke(c.Request.Context(), claims.UserID, tokenID)
+	if err != nil {
+		if err.Error() == "API token not found" {
+			response := models.NewErrorResponse(models.ErrCodeNotFound, "API token not found", nil)
+			c.JSON(http.StatusNotFound, response)
+			return
+		}
+		app.logger.Printf("Error revoking API token: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to revoke API token", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	entityData := map[string]interface{}{"api_token_id": tokenID}
+	if err := app.db.System().LogAction(c.Request.Context(), &claims.UserID, "DELETE", "user", claims.UserID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
+	response := models.NewSuccessResponse(nil, "API token revoked successfully")
+	c.JSON(http.StatusOK, response)
+}
@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -15,6 +16,9 @@ type Config struct {
 	Server   ServerConfig   `json:"server"`
 	Database DatabaseConfig `json:"database"`
 	JWT      JWTConfig      `json:"jwt"`
+	OIDC     OIDCConfig     `json:"oidc"`
+	Login    LoginConfig    `json:"login"`
+	Mail     MailConfig     `json:"mail"`
 	App      AppConfig      `json:"app"`
 }
 
@@ -42,8 +46,51 @@ type DatabaseConfig struct {
 
 // JWTConfig holds JWT configuration
 type JWTConfig struct {
-	Secret     string        `json:"secret"`
-	Expiration time.Duration `json:"expiration"`
+	Secret            string        `json:"secret"`
+	Expiration        time.Duration `json:"expiration"`
+	RefreshExpiration time.Duration `json:"refresh_expiration"`
+}
+
+// OIDCConfig holds OpenID Connect single sign-on configuration
+type OIDCConfig struct {
+	Enabled              bool     `json:"enabled"`
+	IssuerURL            string   `json:"issuer_url"`
+	ClientID             string   `json:"client_id"`
+	ClientSecret         string   `json:"-"`
+	RedirectURL          string   `json:"redirect_url"`
+	Scopes               []string `json:"scopes"`
+	GroupsClaim          string   `json:"groups_claim"`
+	AdminGroups          []string `json:"admin_groups"`
+	PostLoginRedirectURL string   `json:"post_login_redirect_url"`
+}
+
+// LoginConfig holds brute-force protection settings for password logins.
+// Each failure delays the next attempt by BackoffBase doubled per failure, up
+// to BackoffMax; reaching MaxFailures locks the username for LockoutDuration.
+type LoginConfig struct {
+	ProtectionEnabled bool          `json:"protection_enabled"`
+	MaxFailures       int           `json:"max_failures"`
+	IPMaxFailures     int           `json:"ip_max_failures"`
+	FailureWindow     time.Duration `json:"failure_window"`
+	LockoutDuration   time.Duration `json:"lockout_duration"`
+	BackoffBase       time.Duration `json:"backoff_base"`
+	BackoffMax        time.Duration `json:"backoff_max"`
+}
+
+// MailConfig holds outgoing email configuration. Driver is "smtp" to deliver
+// through an SMTP server, "file" to append messages to FilePath, or "log" to
+// write them to the application log.
+type MailConfig struct {
+	Driver           string        `json:"driver"`
+	From             string        `json:"from"`
+	SMTPHost         string        `json:"smtp_host"`
+	SMTPPort         int           `json:"smtp_port"`
+	SMTPUsername     string        `json:"smtp_username"`
+	SMTPPassword     string        `json:"-"`
+	FilePath         string        `json:"file_path"`
+	LinkBaseURL      string        `json:"link_base_url"`
+	VerificationTTL  time.Duration `json:"verification_ttl"`
+	PasswordResetTTL time.Duration `json:"password_reset_ttl"`
 }
 
 // AppConfig holds application configuration
@@ -81,8 +128,41 @@ func LoadConfig() (*Config, error) {
 			ConnMaxLifetime: getDurationEnv("DB_CONN_MAX_LIFETIME", 5*time.Minute),
 		},
 		JWT: JWTConfig{
-			Secret:     getEnv("JWT_SECRET", "dev-secret-key"),
-			Expiration: getDurationEnv("JWT_EXPIRATION", 24*time.Hour),
+			Secret:            getEnv("JWT_SECRET", "dev-secret-key"),
+			Expiration:        getDurationEnv("JWT_EXPIRATION", 15*time.Minute),
+			RefreshExpiration: getDurationEnv("JWT_REFRESH_EXPIRATION", 30*24*time.Hour),
+		},
+		OIDC: OIDCConfig{
+			Enabled:              getBoolEnv("OIDC_ENABLED", false),
+			IssuerURL:            getEnv("OIDC_ISSUER_URL", ""),
+			ClientID:             getEnv("OIDC_CLIENT_ID", ""),
+			ClientSecret:         getEnv("OIDC_CLIENT_SECRET", ""),
+			RedirectURL:          getEnv("OIDC_REDIRECT_URL", "http://localhost:8080/api/v1/auth/oidc/callback"),
+			Scopes:               getListEnv("OIDC_SCOPES", []string{"openid", "profile", "email", "groups"}),
+			GroupsClaim:          getEnv("OIDC_GROUPS_CLAIM", "groups"),
+			AdminGroups:          getListEnv("OIDC_ADMIN_GROUPS", nil),
+			PostLoginRedirectURL: getEnv("OIDC_POST_LOGIN_REDIRECT_URL", ""),
+		},
+		Login: LoginConfig{
+			ProtectionEnabled: getBoolEnv("LOGIN_PROTECTION_ENABLED", true),
+			MaxFailures:       getIntEnv("LOGIN_MAX_FAILURES", 5),
+			IPMaxFailures:     getIntEnv("LOGIN_IP_MAX_FAILURES", 50),
+			FailureWindow:     getDurationEnv("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+			LockoutDuration:   getDurationEnv("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+			BackoffBase:       getDurationEnv("LOGIN_BACKOFF_BASE", time.Second),
+			BackoffMax:        getDurationEnv("LOGIN_BACKOFF_MAX", time.Minute),
+		},
+		Mail: MailConfig{
+			Driver:           getEnv("MAIL_DRIVER", "log"),
+			From:             getEnv("MAIL_FROM", "no-reply@localhost"),
+			SMTPHost:         getEnv("SMTP_HOST", ""),
+			SMTPPort:         getIntEnv("SMTP_PORT", 587),
+			SMTPUsername:     getEnv("SMTP_USERNAME", ""),
+			SMTPPassword:     getEnv("SMTP_PASSWORD", ""),
+			FilePath:         getEnv("MAIL_FILE_PATH", "mail.log"),
+			LinkBaseURL:      getEnv("MAIL_LINK_BASE_URL", "http://localhost:3000"),
+			VerificationTTL:  getDurationEnv("EMAIL_VERIFICATION_TTL", 48*time.Hour),
+			PasswordResetTTL: getDurationEnv("PASSWORD_RESET_TTL", time.Hour),
 		},
 		App: AppConfig{
 			Name:        getEnv("APP_NAME", "AI Project Management Backend"),
@@ -147,4 +227,26 @@ func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 		}
 	}
 	return defaultValue
+}
+
+func getBoolEnv(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+func getListEnv(key string, defaultValue []string) []string {
+	if value := os.Getenv(key); value != "" {
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items
+	}
+	return defaultValue
 }
\ No newline at end of file
@@ -0,0 +1,674 @@
+package main
+
+import (
+	"ai-project-backend/database"
+	"ai-project-backend/models"
+	"ai-project-backend/policy"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+// validCustomFieldTypes lists the types allowed by chk_custom_field_definitions_type
+var validCustomFieldTypes = map[string]bool{
+	models.CustomFieldString: true,
+	models.CustomFieldNumber: true,
+	models.CustomFieldBool:   true,
+	models.CustomFieldDate:   true,
+	models.CustomFieldEnum:   true,
+	models.CustomFieldUser:   true,
+}
+
+// reservedCustomFields are the keys promoteTaskFields moves out of
+// custom_fields, so they cannot be defined as custom fields
+var reservedCustomFields = map[string]bool{
+	"priority":        true,
+	"estimated_hours": true,
+	"actual_hours":    true,
+	"progress":        true,
+	"tags":            true,
+}
+
+// valueText returns the text form of a JSON value, used in value maps and
+// validation errors
+func valueText(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	valueJSON, _ := json.Marshal(value)
+	return string(valueJSON)
+}
+
+// checkCustomFieldValue checks a non-nil value against a field definition
+// and returns it in its stored form. The message is empty when the value is
+// valid.
+func (app *Application) checkCustomFieldValue(ctx context.Context, field *models.CustomFieldDefinition, value interface{}) (interface{}, string, error) {
+	switch field.Type {
+	case models.CustomFieldString:
+		if s, ok := value.(string); ok {
+			return s, "", nil
+		}
+		return nil, "must be a string", nil
+
+	case models.CustomFieldNumber:
+		if f, ok := value.(float64); ok {
+			return f, "", nil
+		}
+		if i, ok := value.(int); ok {
+			return float64(i), "", nil
+		}
+		return nil, "must be a number", nil
+
+	case models.CustomFieldBool:
+		if b, ok := value.(bool); ok {
+			return b, "", nil
+		}
+		return nil, "must be true or false", nil
+
+	case models.CustomFieldDate:
+		if s, ok := value.(string); ok {
+			if t, err := time.Parse("2006-01-02", s); err == nil {
+				return t.Format("2006-01-02"), "", nil
+			}
+			if t, err := time.Parse(time.RFC3339, s); err == nil {
+				return t.Format("2006-01-02"), "", nil
+			}
+		}
+		return nil, "must be a date (YYYY-MM-DD)", nil
+
+	case models.CustomFieldEnum:
+		if s, ok := value.(string); ok {
+			for _, option := range field.Options {
+				if s == option {
+					return s, "", nil
+				}
+			}
+		}
+		return nil, "must be one of " + strings.Join(field.Options, ", "), nil
+
+	case models.CustomFieldUser:
+		var id int
+		switch v := value.(type) {
+		case float64:
+			if v != math.Trunc(v) || v < 1 || v > math.MaxInt32 {
+				return nil, "must be a user ID", nil
+			}
+			id = int(v)
+		case int:
+			id = v
+		default:
+			return nil, "must be a user ID", nil
+		}
+		if _, err := app.db.Users().GetByID(ctx, id); err != nil {
+			if err.Error() == "user not found" {
+				return nil, "user not found", nil
+			}
+			return nil, "", err
+		}
+		return id, "", nil
+	}
+
+	return nil, "has an unknown type", nil
+}
+
+// coerceCustomFieldValue converts a value written for another field type
+// to the field's type where the conversion is unambiguous, e.g. "16" to 16
+// for a number. Other values are returned unchanged.
+func coerceCustomFieldValue(field *models.CustomFieldDefinition, value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		s := strings.TrimSpace(v)
+		switch field.Type {
+		case models.CustomFieldNumber, models.CustomFieldUser:
+			if f, err := strconv.ParseFloat(s, 64); err == nil {
+				return f
+			}
+		case models.CustomFieldBool:
+			if b, err := strconv.ParseBool(s); err == nil {
+				return b
+			}
+		}
+	case float64:
+		switch field.Type {
+		case models.CustomFieldString, models.CustomFieldEnum:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		case models.CustomFieldBool:
+			if v == 0 || v == 1 {
+				return v == 1
+			}
+		}
+	case bool:
+		switch field.Type {
+		case models.CustomFieldString, models.CustomFieldEnum:
+			return strconv.FormatBool(v)
+		}
+	}
+	return value
+}
+
+// validateCustomFields checks the custom fields of a task against the
+// project's definitions. Missing values are set to the field's default.
+// Errors name the field as prefix.<name>. Keys without a definition are
+// left as they are.
+func (app *Application) validateCustomFields(ctx context.Context, fields []*models.CustomFieldDefinition, values models.CustomFields, prefix string) (models.CustomFields, []models.ValidationError, error) {
+	if len(fields) == 0 {
+		return values, nil, nil
+	}
+	if values == nil {
+		values = models.CustomFields{}
+	}
+
+	var errs []models.ValidationError
+	for _, field := range fields {
+		name := prefix + "." + field.Name
+		value := values[field.Name]
+		if value == nil {
+			if field.DefaultValue != nil {
+				values[field.Name] = field.DefaultValue
+			} else if field.Required {
+				errs = append(errs, models.ValidationError{Field: name, Message: "is required"})
+			}
+			continue
+		}
+
+		stored, msg, err := app.checkCustomFieldValue(ctx, field, value)
+		if err != nil {
+			return nil, nil, err
+		}
+		if msg != "" {
+			errs = append(errs, models.ValidationError{Field: name, Message: msg, Value: valueText(value)})
+			continue
+		}
+		values[field.Name] = stored
+	}
+
+	return values, errs, nil
+}
+
+// projectCustomFields loads the custom field definitions of a project,
+// writing the error response and returning false on failure
+func (app *Application) projectCustomFields(c *gin.Context, projectID int, action string) ([]*models.CustomFieldDefinition, bool) {
+	fields, err := app.db.CustomFields().List(c.Request.Context(), projectID)
+	if err != nil {
+		app.logger.Printf("Error getting custom fields: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to "+action, nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return nil, false
+	}
+	return fields, true
+}
+
+// checkTaskCustomFields validates task custom fields against the project's
+// definitions, writing the error response and returning false on failure
+func (app *Application) checkTaskCustomFields(c *gin.Context, fields []*models.CustomFieldDefinition, values models.CustomFields, prefix, action string) (models.CustomFields, bool) {
+	values, errs, err := app.validateCustomFields(c.Request.Context(), fields, values, prefix)
+	if err != nil {
+		app.logger.Printf("Error validating custom fields: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to "+action, nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return nil, false
+	}
+	if len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, models.NewValidationErrorResponse(errs))
+		return nil, false
+	}
+	return values, true
+}
+
+// checkCustomFieldDefinition validates a field definition, returning an
+// error message or "" when it is valid. The default value is converted to
+// its stored form.
+func (app *Application) checkCustomFieldDefinition(ctx context.Context, field *models.CustomFieldDefinition) (string, error) {
+	if field.Name == "" {
+		return "Name is required", nil
+	}
+	if utf8.RuneCountInString(field.Name) > 100 || strings.Contains(field.Name, ".") {
+		return "Name must be at most 100 characters and cannot contain dots", nil
+	}
+	if _, ok := models.ParseCustomFieldPath(field.Name); !ok {
+		return "Name may only contain letters, digits, underscores and hyphens", nil
+	}
+	if reservedCustomFields[field.Name] {
+		return "Name is reserved for a task field: " + field.Name, nil
+	}
+	if !validCustomFieldTypes[field.Type] {
+		return "Type must be one of string, number, bool, date, enum, user", nil
+	}
+
+	if field.Type == models.CustomFieldEnum {
+		if len(field.Options) == 0 {
+			return "Enum fields need at least one option", nil
+		}
+		seen := make(map[string]bool)
+		for _, option := range field.Options {
+			if option == "" || seen[option] {
+				return "Enum options must be unique and non-empty", nil
+			}
+			seen[option] = true
+		}
+	} else if len(field.Options) > 0 {
+		return "Only enum fields have options", nil
+	}
+	if field.Options == nil {
+		field.Options = []string{}
+	}
+
+	if field.DefaultValue != nil {
+		stored, msg, err := app.checkCustomFieldValue(ctx, field, field.DefaultValue)
+		if err != nil {
+			return "", err
+		}
+		if msg != "" {
+			return "Default value " + msg, nil
+		}
+		field.DefaultValue = stored
+	}
+
+	return "", nil
+}
+
+// migrateCustomField rewrites the task values stored under oldName to fit
+// field, following the migration settings. It returns the number of changed
+// tasks, or validation errors when values cannot be migrated and the
+// migration rejects them. Changes are recorded in the task history.
+func (app *Application) migrateCustomField(ctx context.Context, tx database.Tx, oldName string, field *models.CustomFieldDefinition, migration *models.CustomFieldMigration, userID int) (int, []models.ValidationError, error) {
+	if migration == nil {
+		migration = &models.CustomFieldMigration{}
+	}
+
+	tasks, err := tx.Tasks().GetByCustomField(ctx, field.ProjectID, oldName)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var errs []models.ValidationError
+	var changed []*models.Task
+	var befores []*models.Task
+	for _, task := range tasks {
+		before := cloneTask(task)
+		value := task.CustomFields[oldName]
+		delete(task.CustomFields, oldName)
+
+		if mapped, ok := migration.ValueMap[valueText(value)]; ok {
+			value = mapped
+		}
+		if value != nil {
+			value = coerceCustomFieldValue(field, value)
+			stored, msg, err := app.checkCustomFieldValue(ctx, field, value)
+			if err != nil {
+				return 0, nil, err
+			}
+			if msg != "" {
+				switch migration.OnInvalid {
+				case models.CustomFieldOnInvalidClear:
+					stored = nil
+				case models.CustomFieldOnInvalidDefault:
+					stored = field.DefaultValue
+				default:
+					errs = append(errs, models.ValidationError{
+						Field:   fmt.Sprintf("tasks[%d].custom_fields.%s", task.ID, oldName),
+						Message: msg,
+						Value:   valueText(value),
+					})
+					continue
+				}
+			}
+			value = stored
+		}
+		if value != nil {
+			task.CustomFields[field.Name] = value
+		}
+
+		if !sameValue(before.CustomFields, task.CustomFields) {
+			changed = append(changed, task)
+			befores = append(befores, before)
+		}
+	}
+	if len(errs) > 0 {
+		return 0, errs, nil
+	}
+
+	note := "Custom field " + field.Name + " changed"
+	for i, task := range changed {
+		if _, err := tx.Tasks().Update(ctx, task); err != nil {
+			return 0, nil, err
+		}
+		if err := recordTaskChanges(ctx, tx.TaskUpdates(), befores[i], task, &userID, note); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	return len(changed), nil, nil
+}
+
+// loadCustomFieldParam loads the custom field named by the route after
+// checking the caller's project role. It writes the error response and
+// returns false on failure.
+func (app *Application) loadCustomFieldParam(c *gin.Context, required string) (*models.CustomFieldDefinition, bool) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid project ID", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return nil, false
+	}
+
+	fieldID, err := strconv.Atoi(c.Param("fieldId"))
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid custom field ID", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return nil, false
+	}
+
+	if _, ok := app.authorizeProject(c, projectID, required); !ok {
+		return nil, false
+	}
+
+	field, err := app.db.CustomFields().GetByID(c.Request.Context(), fieldID)
+	if err == nil && field.ProjectID != projectID {
+		err = fmt.Errorf("custom field not found")
+	}
+	if err != nil {
+		if err.Error() == "custom field not found" {
+			response := models.NewErrorResponse(models.ErrCodeNotFound, "Custom field not found", nil)
+			c.JSON(http.StatusNotFound, response)
+			return nil, false
+		}
+		app.logger.Printf("Error getting custom field: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to retrieve custom field", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return nil, false
+	}
+
+	return field, true
+}
+
+// saveCustomField stores a new or changed field and migrates the task values
+// stored under oldName in one transaction, writing the response
+func (app *Application) saveCustomField(c *gin.Context, oldName string, field *models.CustomFieldDefinition, migration *models.CustomFieldMigration) {
+	ctx := c.Request.Context()
+	creating := field.ID == 0
+	action := "update"
+	if creating {
+		action = "create"
+	}
+
+	tx, err := app.db.BeginTx(ctx)
+	if err != nil {
+		app.logger.Printf("Error starting transaction: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to "+action+" custom field", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	defer tx.Rollback()
+
+	var saved *models.CustomFieldDefinition
+	if creating {
+		saved, err = tx.CustomFields().Create(ctx, field)
+	} else {
+		saved, err = tx.CustomFields().Update(ctx, field)
+	}
+	if err != nil {
+		if err.Error() == "custom field already exists" {
+			response := models.NewErrorResponse(models.ErrCodeConflict, "A custom field with this name already exists", nil)
+			c.JSON(http.StatusConflict, response)
+			return
+		}
+		app.logger.Printf("Error saving custom field: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to "+action+" custom field", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	claims := currentUser(c)
+	migrated, errs, err := app.migrateCustomField(ctx, tx, oldName, saved, migration, claims.UserID)
+	if err != nil {
+		app.logger.Printf("Error migrating custom field values: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to "+action+" custom field", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	if len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, models.NewValidationErrorResponse(errs))
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		app.logger.Printf("Error committing transaction: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to "+action+" custom field", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	auditAction := "UPDATE"
+	status := http.StatusOK
+	message := "Custom field updated successfully"
+	if creating {
+		auditAction = "CREATE"
+		status = http.StatusCreated
+		message = "Custom field created successfully"
+	}
+	entityData := map[string]interface{}{"project_id": saved.ProjectID, "name": saved.Name, "migrated_tasks": migrated}
+	if err := app.db.System().LogAction(ctx, &claims.UserID, auditAction, "custom_field", saved.ID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
+	response := models.NewSuccessResponse(models.CustomFieldUpdateResponse{Field: saved, MigratedTasks: migrated}, message)
+	c.JSON(status, response)
+}
+
+func (app *Application) getCustomFieldsHandler(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid project ID", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if _, ok := app.authorizeProject(c, projectID, policy.ProjectRoleViewer); !ok {
+		return
+	}
+
+	fields, err := app.db.CustomFields().List(c.Request.Context(), projectID)
+	if err != nil {
+		app.logger.Printf("Error getting custom fields: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to retrieve custom fields", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := models.NewSuccessResponse(fields, "Custom fields retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) createCustomFieldHandler(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid project ID", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	var req models.CustomFieldDefinitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid request body", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	field := &models.CustomFieldDefinition{
+		ProjectID: projectID,
+		Name:      strings.TrimSpace(req.Name),
+		Type:      req.Type,
+		Options:   req.Options,
+	}
+	if req.Required != nil {
+		field.Required = *req.Required
+	}
+	if len(req.DefaultValue) > 0 {
+		if err := json.Unmarshal(req.DefaultValue, &field.DefaultValue); err != nil {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid default value", nil)
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+	}
+	if !app.checkCustomFieldRequest(c, field, req.Migration) {
+		return
+	}
+
+	if _, ok := app.authorizeProject(c, projectID, policy.ProjectRoleMaintainer); !ok {
+		return
+	}
+
+	// Values already stored under the name are converted to the new type
+	app.saveCustomField(c, field.Name, field, req.Migration)
+}
+
+func (app *Application) updateCustomFieldHandler(c *gin.Context) {
+	var req models.CustomFieldDefinitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid request body", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	field, ok := app.loadCustomFieldParam(c, policy.ProjectRoleMaintainer)
+	if !ok {
+		return
+	}
+	oldName := field.Name
+
+	if req.Name != "" {
+		field.Name = strings.TrimSpace(req.Name)
+	}
+	if req.Type != "" {
+		if req.Type != field.Type && req.Options == nil {
+			field.Options = nil
+		}
+		field.Type = req.Type
+	}
+	if req.Options != nil {
+		field.Options = req.Options
+	}
+	if req.Required != nil {
+		field.Required = *req.Required
+	}
+	if len(req.DefaultValue) > 0 {
+		field.DefaultValue = nil
+		if err := json.Unmarshal(req.DefaultValue, &field.DefaultValue); err != nil {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid default value", nil)
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+	} else if field.DefaultValue != nil {
+		// Keep the default when it still fits the changed field
+		field.DefaultValue = coerceCustomFieldValue(field, field.DefaultValue)
+	}
+	if !app.checkCustomFieldRequest(c, field, req.Migration) {
+		return
+	}
+
+	app.saveCustomField(c, oldName, field, req.Migration)
+}
+
+// checkCustomFieldRequest validates a field definition and its migration
+// settings, writing the error response and returning false on failure
+func (app *Application) checkCustomFieldRequest(c *gin.Context, field *models.CustomFieldDefinition, migration *models.CustomFieldMigration) bool {
+	msg, err := app.checkCustomFieldDefinition(c.Request.Context(), field)
+	if err != nil {
+		app.logger.Printf("Error checking custom field: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to save custom field", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return false
+	}
+	if msg != "" {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, msg, nil)
+		c.JSON(http.StatusBadRequest, response)
+		return false
+	}
+
+	if migration != nil {
+		switch migration.OnInvalid {
+		case "", models.CustomFieldOnInvalidReject, models.CustomFieldOnInvalidClear, models.CustomFieldOnInvalidDefault:
+		default:
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, "on_invalid must be one of reject, clear, default", nil)
+			c.JSON(http.StatusBadRequest, response)
+			return false
+		}
+	}
+
+	return true
+}
+
+func (app *Application) deleteCustomFieldHandler(c *gin.Context) {
+	field, ok := app.loadCustomFieldParam(c, policy.ProjectRoleMaintainer)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+
+	tx, err := app.db.BeginTx(ctx)
+	if err != nil {
+		app.logger.Printf("Error starting transaction: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to delete custom field", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	defer tx.Rollback()
+
+	if err := tx.CustomFields().Delete(ctx, field.ID); err != nil {
+		app.logger.Printf("Error deleting custom field: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to delete custom field", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	// With purge=true the field's values are removed from every task
+	claims := currentUser(c)
+	purged := 0
+	if c.Query("purge") == "true" {
+		tasks, err := tx.Tasks().GetByCustomField(ctx, field.ProjectID, field.Name)
+		if err == nil {
+			note := "Custom field " + field.Name + " deleted"
+			for _, task := range tasks {
+				before := cloneTask(task)
+				delete(task.CustomFields, field.Name)
+				if _, err = tx.Tasks().Update(ctx, task); err != nil {
+					break
+				}
+				if err = recordTaskChanges(ctx, tx.TaskUpdates(), before, task, &claims.UserID, note); err != nil {
+					break
+				}
+				purged++
+			}
+		}
+		if err != nil {
+			app.logger.Printf("Error purging custom field values: %v", err)
+			response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to delete custom field", nil)
+			c.JSON(http.StatusInternalServerError, response)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		app.logger.Printf("Error committing transaction: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to delete custom field", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	entityData := map[string]interface{}{"project_id": field.ProjectID, "name": field.Name, "purged_tasks": purged}
+	if err := app.db.System().LogAction(ctx, &claims.UserID, "DELETE", "custom_field", field.ID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
+	response := models.NewSuccessResponse(nil, "Custom field deleted successfully")
+	c.JSON(http.StatusOK, response)
+}
\ No newline at end of file
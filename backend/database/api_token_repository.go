@@ -0,0 +1,158 @@
+package database
+
+import (
+	"ai-project-backend/models"
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// PostgresAPITokenRepository implements APITokenRepository using PostgreSQL
+type PostgresAPITokenRepository struct {
+	db interface{}
+}
+
+// getExecer returns the appropriate execer (DB or Tx)
+func (r *PostgresAPITokenRepository) getExecer() execer {
+	if tx, ok := r.db.(*sql.Tx); ok {
+		return tx
+	}
+	return r.db.(*sql.DB)
+}
+
+// Create stores a new personal API token
+func (r *PostgresAPITokenRepository) Create(ctx context.Context, token *models.APIToken) (*models.APIToken, error) {
+	query := `
+		INSERT INTO api_tokens (user_id, name, token_prefix, token_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`
+
+	exec := r.getExecer()
+	row := exec.QueryRowContext(ctx, query,
+		token.UserID, token.Name, token.TokenPrefix, token.TokenHash,
+		pq.Array(token.Scopes), token.ExpiresAt)
+
+	err := row.Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API token: %w", err)
+	}
+
+	return token, nil
+}
+
+// GetByHash gets a live (not revoked, not expired) token by its hash
+func (r *PostgresAPITokenRepository) GetByHash(ctx context.Context, tokenHash string) (*models.APIToken, error) {
+	query := `
+		SELECT id, user_id, name, token_prefix, token_hash, scopes, expires_at,
+		       last_used_at, revoked_at, created_at
+		FROM api_tokens
+		WHERE token_hash = $1 AND revoked_at IS NULL AND expires_at > NOW()`
+
+	exec := r.getExecer()
+	row := exec.QueryRowContext(ctx, query, tokenHash)
+
+	token := &models.APIToken{}
+	err := row.Scan(
+		&token.ID, &token.UserID, &token.Name, &token.TokenPrefix, &token.TokenHash,
+		pq.Array(&token.Scopes), &token.ExpiresAt, &token.LastUsedAt,
+		&token.RevokedAt, &token.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("API token not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API token: %w", err)
+	}
+
+	return token, nil
+}
+
+// ListByUser lists a user's tokens, including revoked and expired ones
+func (r *PostgresAPITokenRepository) ListByUser(ctx context.Context, userID int) ([]*models.APIToken, error) {
+	query := `
+		SELECT id, user_id, name, token_prefix, token_hash, scopes, expires_at,
+		       last_used_at, revoked_at, created_at
+		FROM api_tokens
+		WHERE user_id = $1
+		ORDER BY created_at DESC`
+
+	exec := r.getExecer()
+	rows, err := exec.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []*models.APIToken
+	for rows.Next() {
+		token := &models.APIToken{}
+
+		err := rows.Scan(
+			&token.ID, &token.UserID, &token.Name, &token.TokenPrefix, &token.TokenHash,
+			pq.Array(&token.Scopes), &token.ExpiresAt, &token.LastUsedAt,
+			&token.RevokedAt, &token.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API token: %w", err)
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// Revoke revokes one of a user's tokens
+func (r *PostgresAPITokenRepository) Revoke(ctx context.Context, userID, id int) error {
+	query := `UPDATE api_tokens SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+
+	exec := r.getExecer()
+	result, err := exec.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API token: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("API token not found")
+	}
+
+	return nil
+}
+
+// RevokeAllForUser revokes every live token of a user
+func (r *PostgresAPITokenRepository) RevokeAllForUser(ctx context.Context, userID int) error {
+	query := `UPDATE api_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`
+
+	exec := r.getExecer()
+	_, err := exec.ExecContext(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API tokens: %w", err)
+	}
+
+	return nil
+}
+
+// TouchLastUsed records that a token was just used
+func (r *PostgresAPITokenRepository) TouchLastUsed(ctx context.Context, id int) error {
+	query := `UPDATE api_tokens SET last_used_at = NOW() WHERE id = $1`
+
+	exec := r.getExecer()
+	_, err := exec.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to update API token: %w", err)
+	}
+
+	return nil
+}
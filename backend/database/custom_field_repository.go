@@ -0,0 +1,191 @@
+package database
+
+import (
+	"ai-project-backend/models"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// PostgresCustomFieldRepository implements CustomFieldRepository using PostgreSQL
+type PostgresCustomFieldRepository struct {
+	db interface{}
+}
+
+// getExecer returns the appropriate execer (DB or Tx)
+func (r *PostgresCustomFieldRepository) getExecer() execer {
+	if tx, ok := r.db.(*sql.Tx); ok {
+		return tx
+	}
+	return r.db.(*sql.DB)
+}
+
+// customFieldColumns lists the custom_field_definitions columns read by scanCustomField, in order
+const customFieldColumns = `id, project_id, name, field_type, required, default_value, options, created_at, updated_at`
+
+// scanCustomField scans a row selected with customFieldColumns
+func scanCustomField(scanner rowScanner) (*models.CustomFieldDefinition, error) {
+	field := &models.CustomFieldDefinition{}
+	var defaultJSON []byte
+
+	err := scanner.Scan(
+		&field.ID, &field.ProjectID, &field.Name, &field.Type, &field.Required,
+		&defaultJSON, pq.Array(&field.Options), &field.CreatedAt, &field.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(defaultJSON) > 0 {
+		if err := json.Unmarshal(defaultJSON, &field.DefaultValue); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal default value: %w", err)
+		}
+	}
+
+	return field, nil
+}
+
+// defaultValueJSON encodes a default value for the default_value column
+func defaultValueJSON(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal default value: %w", err)
+	}
+	return valueJSON, nil
+}
+
+// List gets the custom fields of a project, ordered by name
+func (r *PostgresCustomFieldRepository) List(ctx context.Context, projectID int) ([]*models.CustomFieldDefinition, error) {
+	query := `
+		SELECT ` + customFieldColumns + `
+		FROM custom_field_definitions
+		WHERE project_id = $1
+		ORDER BY name`
+
+	exec := r.getExecer()
+	rows, err := exec.QueryContext(ctx, query, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom fields: %w", err)
+	}
+	defer rows.Close()
+
+	fields := []*models.CustomFieldDefinition{}
+	for rows.Next() {
+		field, err := scanCustomField(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan custom field: %w", err)
+		}
+		fields = append(fields, field)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return fields, nil
+}
+
+// GetByID gets a custom field by ID
+func (r *PostgresCustomFieldRepository) GetByID(ctx context.Context, id int) (*models.CustomFieldDefinition, error) {
+	query := `SELECT ` + customFieldColumns + ` FROM custom_field_definitions WHERE id = $1`
+
+	exec := r.getExecer()
+	field, err := scanCustomField(exec.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("custom field not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get custom field: %w", err)
+	}
+
+	return field, nil
+}
+
+// Create creates a custom field
+func (r *PostgresCustomFieldRepository) Create(ctx context.Context, field *models.CustomFieldDefinition) (*models.CustomFieldDefinition, error) {
+	defaultJSON, err := defaultValueJSON(field.DefaultValue)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO custom_field_definitions (project_id, name, field_type, required, default_value, options)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at`
+
+	exec := r.getExecer()
+	row := exec.QueryRowContext(ctx, query,
+		field.ProjectID, field.Name, field.Type, field.Required, defaultJSON, pq.Array(field.Options))
+
+	err = row.Scan(&field.ID, &field.CreatedAt, &field.UpdatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return nil, fmt.Errorf("custom field already exists")
+		}
+		return nil, fmt.Errorf("failed to create custom field: %w", err)
+	}
+
+	return field, nil
+}
+
+// Update updates a custom field
+func (r *PostgresCustomFieldRepository) Update(ctx context.Context, field *models.CustomFieldDefinition) (*models.CustomFieldDefinition, error) {
+	defaultJSON, err := defaultValueJSON(field.DefaultValue)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE custom_field_definitions
+		SET name = $2, field_type = $3, required = $4, default_value = $5, options = $6
+		WHERE id = $1
+		RETURNING updated_at`
+
+	exec := r.getExecer()
+	row := exec.QueryRowContext(ctx, query,
+		field.ID, field.Name, field.Type, field.Required, defaultJSON, pq.Array(field.Options))
+
+	err = row.Scan(&field.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("custom field not found")
+	}
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return nil, fmt.Errorf("custom field already exists")
+		}
+		return nil, fmt.Errorf("failed to update custom field: %w", err)
+	}
+
+	return field, nil
+}
+
+// Delete deletes a custom field. Task values are left in place.
+func (r *PostgresCustomFieldRepository) Delete(ctx context.Context, id int) error {
+	query := `DELETE FROM custom_field_definitions WHERE id = $1`
+
+	exec := r.getExecer()
+	result, err := exec.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete custom field: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("custom field not found")
+	}
+
+	return nil
+}
\ No newline at end of file
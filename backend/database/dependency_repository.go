@@ -0,0 +1,190 @@
+package database
+
+import (
+	"ai-project-backend/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// PostgresDependencyRepository implements DependencyRepository using PostgreSQL
+type PostgresDependencyRepository struct {
+	db interface{}
+}
+
+// getExecer returns the appropriate execer (DB or Tx)
+func (r *PostgresDependencyRepository) getExecer() execer {
+	if tx, ok := r.db.(*sql.Tx); ok {
+		return tx
+	}
+	return r.db.(*sql.DB)
+}
+
+// Add creates a dependency of one task on another
+func (r *PostgresDependencyRepository) Add(ctx context.Context, dependency *models.TaskDependency) (*models.TaskDependency, error) {
+	query := `
+		INSERT INTO task_dependencies (task_id, depends_on_id, dependency_type, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`
+
+	exec := r.getExecer()
+	row := exec.QueryRowContext(ctx, query,
+		dependency.TaskID, dependency.DependsOnID, dependency.Type, dependency.CreatedBy)
+
+	err := row.Scan(&dependency.ID, &dependency.CreatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return nil, fmt.Errorf("dependency already exists")
+		}
+		return nil, fmt.Errorf("failed to create dependency: %w", err)
+	}
+
+	return dependency, nil
+}
+
+// Remove deletes the dependency of a task on another and returns its ID
+func (r *PostgresDependencyRepository) Remove(ctx context.Context, taskID, dependsOnID int) (int, error) {
+	query := `DELETE FROM task_dependencies WHERE task_id = $1 AND depends_on_id = $2 RETURNING id`
+
+	exec := r.getExecer()
+	var id int
+	err := exec.QueryRowContext(ctx, query, taskID, dependsOnID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("dependency not found")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete dependency: %w", err)
+	}
+
+	return id, nil
+}
+
+// ListByTask gets the non-deleted tasks a task waits for and the ones
+// waiting for it, ordered by task ID
+func (r *PostgresDependencyRepository) ListByTask(ctx context.Context, taskID int) (*models.TaskDependencies, error) {
+	blockedByQuery := `
+		SELECT d.id, d.dependency_type, t.id, t.title, t.status
+		FROM task_dependencies d
+		JOIN tasks t ON t.id = d.depends_on_id AND t.deleted_at IS NULL
+		WHERE d.task_id = $1
+		ORDER BY t.id`
+
+	blocksQuery := `
+		SELECT d.id, d.dependency_type, t.id, t.title, t.status
+		FROM task_dependencies d
+		JOIN tasks t ON t.id = d.task_id AND t.deleted_at IS NULL
+		WHERE d.depends_on_id = $1
+		ORDER BY t.id`
+
+	blockedBy, err := r.queryLinks(ctx, blockedByQuery, taskID)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := r.queryLinks(ctx, blocksQuery, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TaskDependencies{BlockedBy: blockedBy, Blocks: blocks}, nil
+}
+
+// ListByProject gets the dependencies between non-deleted tasks of a
+// project, ordered by ID
+func (r *PostgresDependencyRepository) ListByProject(ctx context.Context, projectID int) ([]*models.TaskDependency, error) {
+	query := `
+		SELECT d.id, d.task_id, d.depends_on_id, d.dependency_type, d.created_by, d.created_at
+		FROM task_dependencies d
+		JOIN tasks t ON t.id = d.task_id AND t.deleted_at IS NULL
+		JOIN tasks b ON b.id = d.depends_on_id AND b.deleted_at IS NULL
+		WHERE t.project_id = $1
+		ORDER BY d.id`
+
+	exec := r.getExecer()
+	rows, err := exec.QueryContext(ctx, query, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dependencies: %w", err)
+	}
+	defer rows.Close()
+
+	dependencies := []*models.TaskDependency{}
+	for rows.Next() {
+		dependency := &models.TaskDependency{}
+		err := rows.Scan(&dependency.ID, &dependency.TaskID, &dependency.DependsOnID,
+			&dependency.Type, &dependency.CreatedBy, &dependency.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan dependency: %w", err)
+		}
+		dependencies = append(dependencies, dependency)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return dependencies, nil
+}
+
+// queryLinks runs a query selecting dependency links
+func (r *PostgresDependencyRepository) queryLinks(ctx context.Context, query string, args ...interface{}) ([]*models.DependencyLink, error) {
+	exec := r.getExecer()
+	rows, err := exec.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dependencies: %w", err)
+	}
+	defer rows.Close()
+
+	links := []*models.DependencyLink{}
+	for rows.Next() {
+		link := &models.DependencyLink{}
+		if err := rows.Scan(&link.ID, &link.Type, &link.TaskID, &link.Title, &link.Status); err != nil {
+			return nil, fmt.Errorf("failed to scan dependency: %w", err)
+		}
+		links = append(links, link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return links, nil
+}
+
+// DependsOn reports whether a task waits for another, directly or through
+// a chain of dependencies. Adding the reverse dependency would then close
+// a cycle.
+func (r *PostgresDependencyRepository) DependsOn(ctx context.Context, taskID, dependsOnID int) (bool, error) {
+	query := `
+		WITH RECURSIVE chain AS (
+			SELECT depends_on_id FROM task_dependencies WHERE task_id = $1
+			UNION
+			SELECT d.depends_on_id FROM task_dependencies d
+			JOIN chain c ON d.task_id = c.depends_on_id
+		)
+		SELECT EXISTS (SELECT 1 FROM chain WHERE depends_on_id = $2)`
+
+	exec := r.getExecer()
+	var exists bool
+	if err := exec.QueryRowContext(ctx, query, taskID, dependsOnID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check dependency chain: %w", err)
+	}
+
+	return exists, nil
+}
+
+// LockProject serializes dependency changes in a project until the
+// transaction ends, so that concurrent cycle checks see each other's links.
+// It must be called inside a transaction.
+func (r *PostgresDependencyRepository) LockProject(ctx context.Context, projectID int) error {
+	query := `SELECT pg_advisory_xact_lock(hashtext('task_dependencies'), $1)`
+
+	exec := r.getExecer()
+	if _, err := exec.ExecContext(ctx, query, projectID); err != nil {
+		return fmt.Errorf("failed to lock project dependencies: %w", err)
+	}
+
+	return nil
+}
\ No newline at end of file
@@ -3,6 +3,7 @@ package database
 import (
 	"ai-project-backend/models"
 	"context"
+	"time"
 )
 
 // UserRepository defines the interface for user database operations
@@ -11,9 +12,22 @@ type UserRepository interface {
 	GetByID(ctx context.Context, id int) (*models.User, error)
 	GetByUsername(ctx context.Context, username string) (*models.User, error)
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	GetByExternalID(ctx context.Context, provider, externalID string) (*models.User, error)
 	Update(ctx context.Context, user *models.User) (*models.User, error)
 	Delete(ctx context.Context, id int) error
 	List(ctx context.Context, limit, offset int) ([]*models.User, int, error)
+	SetActive(ctx context.Context, id int, active bool) error
+	SetEmail(ctx context.Context, id int, email *string) error
+	MarkEmailVerified(ctx context.Context, id int, email string) error
+
+	// Two-factor authentication
+	SetTOTPSecret(ctx context.Context, userID int, secret string) error
+	EnableTwoFactor(ctx context.Context, userID int, recoveryCodeHashes []string, step int64) error
+	DisableTwoFactor(ctx context.Context, userID int) error
+	SetRecoveryCodes(ctx context.Context, userID int, recoveryCodeHashes []string) error
+	CountRecoveryCodes(ctx context.Context, userID int) (int, error)
+	ConsumeRecoveryCode(ctx context.Context, userID int, recoveryCodeHash string) (bool, error)
+	RecordTOTPStep(ctx context.Context, userID int, step int64) (bool, error)
 }
 
 // ProjectRepository defines the interface for project database operations
@@ -36,11 +50,87 @@ type TaskRepository interface {
 	Create(ctx context.Context, task *models.Task) (*models.Task, error)
 	GetByID(ctx context.Context, id int) (*models.Task, error)
 	GetByProjectID(ctx context.Context, projectID int, limit, offset int) ([]*models.Task, int, error)
+	GetAllByProjectID(ctx context.Context, projectID int) ([]*models.Task, error)
+	List(ctx context.Context, projectID int, filter models.TaskFilter, limit, offset int) ([]*models.Task, int, error)
+	ListAssigned(ctx context.Context, userID int, memberOnly bool, filter models.TaskFilter, limit, offset int) ([]*models.Task, int, error)
+	GetByCustomField(ctx context.Context, projectID int, name string) ([]*models.Task, error)
 	Update(ctx context.Context, task *models.Task) (*models.Task, error)
 	Delete(ctx context.Context, id int) error
+
+	// Task hierarchy
+	GetChildren(ctx context.Context, parentID int) ([]*models.Task, error)
+	GetSubtree(ctx context.Context, id int) ([]*models.Task, error)
+	Move(ctx context.Context, id int, parentID *int, level int) error
+
 	BulkCreate(ctx context.Context, tasks []*models.Task) ([]*models.Task, error)
 	UpdateStatus(ctx context.Context, id int, status string) error
 	GetByStatus(ctx context.Context, status string, limit, offset int) ([]*models.Task, int, error)
+
+	// Workflow statuses
+	CountByStatus(ctx context.Context, projectID int) (map[string]int, error)
+	GetStatuses(ctx context.Context, projectID int) ([]string, error)
+	RemapStatus(ctx context.Context, projectID int, from, to string) ([]int, error)
+}
+
+// TaskUpdateRepository defines the interface for task update history operations
+type TaskUpdateRepository interface {
+	Record(ctx context.Context, updates []*models.TaskUpdate) error
+	ListByTask(ctx context.Context, taskID int, limit, offset int) ([]*models.TaskUpdate, int, error)
+}
+
+// TimelineRepository defines the interface for activity timeline queries
+type TimelineRepository interface {
+	List(ctx context.Context, filter models.TimelineFilter) ([]*models.TimelineEvent, error)
+}
+
+// CustomFieldRepository defines the interface for custom field definition operations
+type CustomFieldRepository interface {
+	List(ctx context.Context, projectID int) ([]*models.CustomFieldDefinition, error)
+	GetByID(ctx context.Context, id int) (*models.CustomFieldDefinition, error)
+	Create(ctx context.Context, field *models.CustomFieldDefinition) (*models.CustomFieldDefinition, error)
+	Update(ctx context.Context, field *models.CustomFieldDefinition) (*models.CustomFieldDefinition, error)
+	Delete(ctx context.Context, id int) error
+}
+
+// DependencyRepository defines the interface for task dependency operations
+type DependencyRepository interface {
+	Add(ctx context.Context, dependency *models.TaskDependency) (*models.TaskDependency, error)
+	Remove(ctx context.Context, taskID, dependsOnID int) (int, error)
+	ListByTask(ctx context.Context, taskID int) (*models.TaskDependencies, error)
+	ListByProject(ctx context.Context, projectID int) ([]*models.TaskDependency, error)
+	DependsOn(ctx context.Context, taskID, dependsOnID int) (bool, error)
+	LockProject(ctx context.Context, projectID int) error
+}
+
+// MilestoneRepository defines the interface for project milestone operations
+type MilestoneRepository interface {
+	List(ctx context.Context, projectID int) ([]*models.Milestone, error)
+	GetByID(ctx context.Context, id int) (*models.Milestone, error)
+	Create(ctx context.Context, milestone *models.Milestone) (*models.Milestone, error)
+	Update(ctx context.Context, milestone *models.Milestone) (*models.Milestone, error)
+	Delete(ctx context.Context, id int) error
+}
+
+// SprintRepository defines the interface for project sprint operations
+type SprintRepository interface {
+	List(ctx context.Context, projectID int) ([]*models.Sprint, error)
+	ListClosed(ctx context.Context, projectID int, limit int) ([]*models.Sprint, error)
+	GetByID(ctx context.Context, id int) (*models.Sprint, error)
+	LockState(ctx context.Context, id int) (string, error)
+	Create(ctx context.Context, sprint *models.Sprint) (*models.Sprint, error)
+	Update(ctx context.Context, sprint *models.Sprint) (*models.Sprint, error)
+	Delete(ctx context.Context, id int) error
+}
+
+// WorkflowRepository defines the interface for project workflow operations
+type WorkflowRepository interface {
+	Get(ctx context.Context, projectID int) (*models.Workflow, error)
+	Replace(ctx context.Context, workflow *models.Workflow) error
+}
+
+// SearchRepository defines the interface for project and task search
+type SearchRepository interface {
+	Search(ctx context.Context, filter models.SearchFilter) (*models.SearchResult, error)
 }
 
 // SystemRepository defines the interface for system management operations
@@ -57,7 +147,66 @@ type SystemRepository interface {
 	// Audit log operations
 	GetAuditLogs(ctx context.Context, limit, offset int) ([]*models.AuditLog, int, error)
 	LogAction(ctx context.Context, userID *int, action, entityType string, entityID int, entityData interface{}, ipAddress, userAgent string) error
-	
+
+	// System settings, stored as JSON documents
+	GetSetting(ctx context.Context, key string, value interface{}) error
+	SetSetting(ctx context.Context, key string, value interface{}, updatedBy *int) error
+
+}
+
+// MemberRepository defines the interface for project membership operations
+type MemberRepository interface {
+	Add(ctx context.Context, member *models.ProjectMember) (*models.ProjectMember, error)
+	Get(ctx context.Context, projectID, userID int) (*models.ProjectMember, error)
+	GetRole(ctx context.Context, projectID, userID int) (string, error)
+	List(ctx context.Context, projectID int) ([]*models.ProjectMember, error)
+	UpdateRole(ctx context.Context, projectID, userID int, role string) error
+	Remove(ctx context.Context, projectID, userID int) error
+}
+
+// TokenRepository defines the interface for server-side token revocation
+type TokenRepository interface {
+	RevokeToken(ctx context.Context, tokenID string, userID int, expiresAt time.Time) error
+	IsTokenRevoked(ctx context.Context, tokenID string) (bool, error)
+	CleanupExpired(ctx context.Context) (int, error)
+}
+
+// SessionRepository defines the interface for refresh-token session operations
+type SessionRepository interface {
+	Create(ctx context.Context, session *models.AuthSession) (*models.AuthSession, error)
+	GetByTokenHash(ctx context.Context, tokenHash string) (*models.AuthSession, error)
+	MarkReplaced(ctx context.Context, id, replacedBy int) error
+	RevokeFamily(ctx context.Context, familyID string) error
+	RevokeUserFamily(ctx context.Context, userID int, familyID string) error
+	RevokeAllForUser(ctx context.Context, userID int) error
+	RevokeOtherFamilies(ctx context.Context, userID int, keepFamilyID string) error
+	IsFamilyActive(ctx context.Context, familyID string) (bool, error)
+	ListActiveByUser(ctx context.Context, userID int) ([]*models.SessionInfo, error)
+}
+
+// APITokenRepository defines the interface for personal API token operations
+type APITokenRepository interface {
+	Create(ctx context.Context, token *models.APIToken) (*models.APIToken, error)
+	GetByHash(ctx context.Context, tokenHash string) (*models.APIToken, error)
+	ListByUser(ctx context.Context, userID int) ([]*models.APIToken, error)
+	Revoke(ctx context.Context, userID, id int) error
+	RevokeAllForUser(ctx context.Context, userID int) error
+	TouchLastUsed(ctx context.Context, id int) error
+}
+
+// LoginFailureRepository defines the interface for login brute-force tracking
+type LoginFailureRepository interface {
+	Get(ctx context.Context, scope, subject string) (*models.LoginFailure, error)
+	RecordFailure(ctx context.Context, scope, subject string, window time.Duration) (*models.LoginFailure, error)
+	Lock(ctx context.Context, scope, subject string, until time.Time) error
+	Reset(ctx context.Context, scope, subject string) (bool, error)
+}
+
+// UserTokenRepository defines the interface for emailed single-use user tokens
+type UserTokenRepository interface {
+	Create(ctx context.Context, token *models.UserToken) (*models.UserToken, error)
+	Consume(ctx context.Context, purpose, tokenHash string) (*models.UserToken, error)
+	InvalidateForUser(ctx context.Context, userID int, purpose string) error
 }
 
 // DB defines the database interface that combines all repositories
@@ -65,7 +214,21 @@ type DB interface {
 	Users() UserRepository
 	Projects() ProjectRepository
 	Tasks() TaskRepository
+	TaskUpdates() TaskUpdateRepository
+	CustomFields() CustomFieldRepository
+	Workflows() WorkflowRepository
+	Dependencies() DependencyRepository
+	Milestones() MilestoneRepository
+	Sprints() SprintRepository
+	Timeline() TimelineRepository
+	Search() SearchRepository
 	System() SystemRepository
+	Tokens() TokenRepository
+	Members() MemberRepository
+	Sessions() SessionRepository
+	APITokens() APITokenRepository
+	LoginFailures() LoginFailureRepository
+	UserTokens() UserTokenRepository
 	GetDB() interface{} // Access to underlying database connection
 	Close() error
 	Ping() error
@@ -77,6 +240,14 @@ type Tx interface {
 	Users() UserRepository
 	Projects() ProjectRepository
 	Tasks() TaskRepository
+	TaskUpdates() TaskUpdateRepository
+	CustomFields() CustomFieldRepository
+	Workflows() WorkflowRepository
+	Dependencies() DependencyRepository
+	Milestones() MilestoneRepository
+	Sprints() SprintRepository
+	Members() MemberRepository
+	Sessions() SessionRepository
 	Commit() error
 	Rollback() error
 }
\ No newline at end of file
@@ -0,0 +1,129 @@
+package database
+
+import (
+	"ai-project-backend/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// PostgresLoginFailureRepository implements LoginFailureRepository using PostgreSQL
+type PostgresLoginFailureRepository struct {
+	db interface{}
+}
+
+// getExecer returns the appropriate execer (DB or Tx)
+func (r *PostgresLoginFailureRepository) getExecer() execer {
+	if tx, ok := r.db.(*sql.Tx); ok {
+		return tx
+	}
+	return r.db.(*sql.DB)
+}
+
+// scanLoginFailure scans a login_failures row
+func scanLoginFailure(scanner rowScanner) (*models.LoginFailure, error) {
+	failure := &models.LoginFailure{}
+	var lockedUntil sql.NullTime
+
+	err := scanner.Scan(
+		&failure.Scope, &failure.Subject, &failure.FailureCount,
+		&failure.LastFailedAt, &lockedUntil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if lockedUntil.Valid {
+		failure.LockedUntil = &lockedUntil.Time
+	}
+
+	return failure, nil
+}
+
+// Get returns the failure counter for a username or IP
+func (r *PostgresLoginFailureRepository) Get(ctx context.Context, scope, subject string) (*models.LoginFailure, error) {
+	query := `
+		SELECT scope, subject, failure_count, last_failed_at, locked_until
+		FROM login_failures WHERE scope = $1 AND subject = $2`
+
+	exec := r.getExecer()
+	failure, err := scanLoginFailure(exec.QueryRowContext(ctx, query, scope, subject))
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("login failure not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get login failure: %w", err)
+	}
+
+	return failure, nil
+}
+
+// RecordFailure increments the failure counter and returns it. A counter whose
+// last failure is older than window, or whose lockout has expired, starts over.
+func (r *PostgresLoginFailureRepository) RecordFailure(ctx context.Context, scope, subject string, window time.Duration) (*models.LoginFailure, error) {
+	query := `
+		INSERT INTO login_failures (scope, subject, failure_count, last_failed_at)
+		VALUES ($1, $2, 1, NOW())
+		ON CONFLICT (scope, subject) DO UPDATE SET
+			failure_count = CASE
+				WHEN login_failures.last_failed_at < NOW() - make_interval(secs => $3)
+				  OR login_failures.locked_until <= NOW() THEN 1
+				ELSE login_failures.failure_count + 1
+			END,
+			locked_until = CASE
+				WHEN login_failures.locked_until <= NOW() THEN NULL
+				ELSE login_failures.locked_until
+			END,
+			last_failed_at = NOW()
+		RETURNING scope, subject, failure_count, last_failed_at, locked_until`
+
+	exec := r.getExecer()
+	failure, err := scanLoginFailure(exec.QueryRowContext(ctx, query, scope, subject, window.Seconds()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to record login failure: %w", err)
+	}
+
+	return failure, nil
+}
+
+// Lock blocks logins for a username or IP until the given time
+func (r *PostgresLoginFailureRepository) Lock(ctx context.Context, scope, subject string, until time.Time) error {
+	query := `UPDATE login_failures SET locked_until = $3 WHERE scope = $1 AND subject = $2`
+
+	exec := r.getExecer()
+	result, err := exec.ExecContext(ctx, query, scope, subject, until)
+	if err != nil {
+		return fmt.Errorf("failed to lock login: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("login failure not found")
+	}
+
+	return nil
+}
+
+// Reset clears the failure counter and any lockout, reporting whether one existed
+func (r *PostgresLoginFailureRepository) Reset(ctx context.Context, scope, subject string) (bool, error) {
+	query := `DELETE FROM login_failures WHERE scope = $1 AND subject = $2`
+
+	exec := r.getExecer()
+	result, err := exec.ExecContext(ctx, query, scope, subject)
+	if err != nil {
+		return false, fmt.Errorf("failed to reset login failures: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
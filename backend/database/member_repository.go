@@ -0,0 +1,180 @@
+package database
+
+import (
+	"ai-project-backend/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// PostgresMemberRepository implements MemberRepository using PostgreSQL
+type PostgresMemberRepository struct {
+	db interface{}
+}
+
+// getExecer returns the appropriate execer (DB or Tx)
+func (r *PostgresMemberRepository) getExecer() execer {
+	if tx, ok := r.db.(*sql.Tx); ok {
+		return tx
+	}
+	return r.db.(*sql.DB)
+}
+
+// Add adds a user to a project with the given role
+func (r *PostgresMemberRepository) Add(ctx context.Context, member *models.ProjectMember) (*models.ProjectMember, error) {
+	query := `
+		INSERT INTO project_members (project_id, user_id, role)
+		VALUES ($1, $2, $3)
+		RETURNING created_at, updated_at`
+
+	exec := r.getExecer()
+	row := exec.QueryRowContext(ctx, query, member.ProjectID, member.UserID, member.Role)
+
+	err := row.Scan(&member.CreatedAt, &member.UpdatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return nil, fmt.Errorf("member already exists")
+		}
+		return nil, fmt.Errorf("failed to add project member: %w", err)
+	}
+
+	return member, nil
+}
+
+// Get gets a single membership of a project
+func (r *PostgresMemberRepository) Get(ctx context.Context, projectID, userID int) (*models.ProjectMember, error) {
+	query := `
+		SELECT pm.project_id, pm.user_id, u.username, pm.role, pm.created_at, pm.updated_at
+		FROM project_members pm
+		JOIN users u ON pm.user_id = u.id
+		WHERE pm.project_id = $1 AND pm.user_id = $2`
+
+	exec := r.getExecer()
+	row := exec.QueryRowContext(ctx, query, projectID, userID)
+
+	member := &models.ProjectMember{}
+	err := row.Scan(
+		&member.ProjectID, &member.UserID, &member.Username,
+		&member.Role, &member.CreatedAt, &member.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("member not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project member: %w", err)
+	}
+
+	return member, nil
+}
+
+// GetRole returns the caller's effective role in a non-deleted project.
+// The project owner is always treated as an owner; an empty role means
+// the user is not a member.
+func (r *PostgresMemberRepository) GetRole(ctx context.Context, projectID, userID int) (string, error) {
+	query := `
+		SELECT CASE WHEN p.owner_id = $2 THEN 'owner' ELSE pm.role END
+		FROM projects p
+		LEFT JOIN project_members pm ON pm.project_id = p.id AND pm.user_id = $2
+		WHERE p.id = $1 AND p.deleted_at IS NULL`
+
+	exec := r.getExecer()
+	row := exec.QueryRowContext(ctx, query, projectID, userID)
+
+	var role sql.NullString
+	err := row.Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("project not found")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get project role: %w", err)
+	}
+
+	return role.String, nil
+}
+
+// List gets all members of a project
+func (r *PostgresMemberRepository) List(ctx context.Context, projectID int) ([]*models.ProjectMember, error) {
+	query := `
+		SELECT pm.project_id, pm.user_id, u.username, pm.role, pm.created_at, pm.updated_at
+		FROM project_members pm
+		JOIN users u ON pm.user_id = u.id
+		WHERE pm.project_id = $1
+		ORDER BY pm.created_at ASC`
+
+	exec := r.getExecer()
+	rows, err := exec.QueryContext(ctx, query, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list project members: %w", err)
+	}
+	defer rows.Close()
+
+	var members []*models.ProjectMember
+	for rows.Next() {
+		member := &models.ProjectMember{}
+
+		err := rows.Scan(
+			&member.ProjectID, &member.UserID, &member.Username,
+			&member.Role, &member.CreatedAt, &member.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan project member: %w", err)
+		}
+
+		members = append(members, member)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return members, nil
+}
+
+// UpdateRole changes a member's role
+func (r *PostgresMemberRepository) UpdateRole(ctx context.Context, projectID, userID int, role string) error {
+	query := `UPDATE project_members SET role = $3 WHERE project_id = $1 AND user_id = $2`
+
+	exec := r.getExecer()
+	result, err := exec.ExecContext(ctx, query, projectID, userID, role)
+	if err != nil {
+		return fmt.Errorf("failed to update project member: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("member not found")
+	}
+
+	return nil
+}
+
+// Remove removes a user from a project
+func (r *PostgresMemberRepository) Remove(ctx context.Context, projectID, userID int) error {
+	query := `DELETE FROM project_members WHERE project_id = $1 AND user_id = $2`
+
+	exec := r.getExecer()
+	result, err := exec.ExecContext(ctx, query, projectID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove project member: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("member not found")
+	}
+
+	return nil
+}
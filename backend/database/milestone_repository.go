@@ -0,0 +1,185 @@
+package database
+
+import (
+	"ai-project-backend/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+)
+
+// PostgresMilestoneRepository implements MilestoneRepository using PostgreSQL
+type PostgresMilestoneRepository struct {
+	db interface{}
+}
+
+// getExecer returns the appropriate execer (DB or Tx)
+func (r *PostgresMilestoneRepository) getExecer() execer {
+	if tx, ok := r.db.(*sql.Tx); ok {
+		return tx
+	}
+	return r.db.(*sql.DB)
+}
+
+// milestoneSelect selects milestones with the progress of their non-deleted
+// tasks; callers add conditions on m and must end with milestoneGroupBy
+const milestoneSelect = `
+	SELECT m.id, m.project_id, m.name, m.description, m.target_date, m.status,
+	       m.created_by, m.created_at, m.updated_at,
+	       COUNT(t.id),
+	       COUNT(t.id) FILTER (WHERE task_status_category(t.project_id, t.status) = 'done'),
+	       COALESCE(SUM(t.estimated_hours), 0),
+	       COALESCE(SUM(t.estimated_hours) FILTER (WHERE task_status_category(t.project_id, t.status) = 'done'), 0),
+	       m.status = 'open' AND m.target_date < CURRENT_DATE
+	FROM milestones m
+	LEFT JOIN tasks t ON t.milestone_id = m.id AND t.deleted_at IS NULL`
+
+// milestoneGroupBy ends a milestoneSelect query
+const milestoneGroupBy = `
+	GROUP BY m.id`
+
+// scanMilestone scans a row selected with milestoneSelect
+func scanMilestone(scanner rowScanner) (*models.Milestone, error) {
+	milestone := &models.Milestone{Progress: &models.MilestoneProgress{}}
+	var description sql.NullString
+	var createdBy sql.NullInt64
+	progress := milestone.Progress
+
+	err := scanner.Scan(
+		&milestone.ID, &milestone.ProjectID, &milestone.Name, &description,
+		&milestone.TargetDate, &milestone.Status, &createdBy,
+		&milestone.CreatedAt, &milestone.UpdatedAt,
+		&progress.TotalTasks, &progress.DoneTasks, &progress.TotalHours, &progress.DoneHours,
+		&progress.Overdue,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	milestone.Description = description.String
+	if createdBy.Valid {
+		intVal := int(createdBy.Int64)
+		milestone.CreatedBy = &intVal
+	}
+
+	switch {
+	case progress.TotalHours > 0:
+		progress.Percent = progress.DoneHours * 100 / progress.TotalHours
+	case progress.TotalTasks > 0:
+		progress.Percent = float64(progress.DoneTasks) * 100 / float64(progress.TotalTasks)
+	}
+	progress.Percent = math.Round(progress.Percent*100) / 100
+
+	return milestone, nil
+}
+
+// List gets the milestones of a project with their progress, ordered by target date
+func (r *PostgresMilestoneRepository) List(ctx context.Context, projectID int) ([]*models.Milestone, error) {
+	query := milestoneSelect + `
+	WHERE m.project_id = $1` + milestoneGroupBy + `
+	ORDER BY m.target_date, m.id`
+
+	exec := r.getExecer()
+	rows, err := exec.QueryContext(ctx, query, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list milestones: %w", err)
+	}
+	defer rows.Close()
+
+	milestones := []*models.Milestone{}
+	for rows.Next() {
+		milestone, err := scanMilestone(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan milestone: %w", err)
+		}
+		milestones = append(milestones, milestone)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return milestones, nil
+}
+
+// GetByID gets a milestone with its progress by ID
+func (r *PostgresMilestoneRepository) GetByID(ctx context.Context, id int) (*models.Milestone, error) {
+	query := milestoneSelect + `
+	WHERE m.id = $1` + milestoneGroupBy
+
+	exec := r.getExecer()
+	milestone, err := scanMilestone(exec.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("milestone not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get milestone: %w", err)
+	}
+
+	return milestone, nil
+}
+
+// Create creates a milestone
+func (r *PostgresMilestoneRepository) Create(ctx context.Context, milestone *models.Milestone) (*models.Milestone, error) {
+	query := `
+		INSERT INTO milestones (project_id, name, description, target_date, status, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at`
+
+	exec := r.getExecer()
+	row := exec.QueryRowContext(ctx, query,
+		milestone.ProjectID, milestone.Name, milestone.Description, milestone.TargetDate,
+		milestone.Status, milestone.CreatedBy)
+
+	err := row.Scan(&milestone.ID, &milestone.CreatedAt, &milestone.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create milestone: %w", err)
+	}
+
+	return milestone, nil
+}
+
+// Update updates the name, description, target date and status of a milestone
+func (r *PostgresMilestoneRepository) Update(ctx context.Context, milestone *models.Milestone) (*models.Milestone, error) {
+	query := `
+		UPDATE milestones
+		SET name = $2, description = $3, target_date = $4, status = $5
+		WHERE id = $1
+		RETURNING updated_at`
+
+	exec := r.getExecer()
+	row := exec.QueryRowContext(ctx, query,
+		milestone.ID, milestone.Name, milestone.Description, milestone.TargetDate, milestone.Status)
+
+	err := row.Scan(&milestone.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("milestone not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update milestone: %w", err)
+	}
+
+	return milestone, nil
+}
+
+// Delete deletes a milestone; its tasks are detached by the foreign key
+func (r *PostgresMilestoneRepository) Delete(ctx context.Context, id int) error {
+	query := `DELETE FROM milestones WHERE id = $1`
+
+	exec := r.getExecer()
+	result, err := exec.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete milestone: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("milestone not found")
+	}
+
+	return nil
+}
\ No newline at end of file
@@ -62,11 +62,81 @@ func (pdb *PostgresDB) Tasks() TaskRepository {
 	return &PostgresTaskRepository{db: pdb.db}
 }
 
+// TaskUpdates returns the task update history repository
+func (pdb *PostgresDB) TaskUpdates() TaskUpdateRepository {
+	return &PostgresTaskUpdateRepository{db: pdb.db}
+}
+
+// CustomFields returns the custom field definition repository
+func (pdb *PostgresDB) CustomFields() CustomFieldRepository {
+	return &PostgresCustomFieldRepository{db: pdb.db}
+}
+
+// Workflows returns the project workflow repository
+func (pdb *PostgresDB) Workflows() WorkflowRepository {
+	return &PostgresWorkflowRepository{db: pdb.db}
+}
+
+// Dependencies returns the task dependency repository
+func (pdb *PostgresDB) Dependencies() DependencyRepository {
+	return &PostgresDependencyRepository{db: pdb.db}
+}
+
+// Milestones returns the project milestone repository
+func (pdb *PostgresDB) Milestones() MilestoneRepository {
+	return &PostgresMilestoneRepository{db: pdb.db}
+}
+
+// Sprints returns the project sprint repository
+func (pdb *PostgresDB) Sprints() SprintRepository {
+	return &PostgresSprintRepository{db: pdb.db}
+}
+
+// Timeline returns the activity timeline repository
+func (pdb *PostgresDB) Timeline() TimelineRepository {
+	return &PostgresTimelineRepository{db: pdb.db}
+}
+
+// Search returns the search repository
+func (pdb *PostgresDB) Search() SearchRepository {
+	return &PostgresSearchRepository{db: pdb.db}
+}
+
 // System returns the system repository
 func (pdb *PostgresDB) System() SystemRepository {
 	return &PostgresSystemRepository{db: pdb.db}
 }
 
+// Tokens returns the token revocation repository
+func (pdb *PostgresDB) Tokens() TokenRepository {
+	return &PostgresTokenRepository{db: pdb.db}
+}
+
+// Members returns the project member repository
+func (pdb *PostgresDB) Members() MemberRepository {
+	return &PostgresMemberRepository{db: pdb.db}
+}
+
+// Sessions returns the refresh-token session repository
+func (pdb *PostgresDB) Sessions() SessionRepository {
+	return &PostgresSessionRepository{db: pdb.db}
+}
+
+// APITokens returns the personal API token repository
+func (pdb *PostgresDB) APITokens() APITokenRepository {
+	return &PostgresAPITokenRepository{db: pdb.db}
+}
+
+// LoginFailures returns the login brute-force tracking repository
+func (pdb *PostgresDB) LoginFailures() LoginFailureRepository {
+	return &PostgresLoginFailureRepository{db: pdb.db}
+}
+
+// UserTokens returns the emailed user token repository
+func (pdb *PostgresDB) UserTokens() UserTokenRepository {
+	return &PostgresUserTokenRepository{db: pdb.db}
+}
+
 // GetDB returns the underlying database connection
 func (pdb *PostgresDB) GetDB() interface{} {
 	return pdb.db
@@ -111,6 +181,46 @@ func (ptx *PostgresTx) Tasks() TaskRepository {
 	return &PostgresTaskRepository{db: ptx.tx}
 }
 
+// TaskUpdates returns the task update history repository for transaction
+func (ptx *PostgresTx) TaskUpdates() TaskUpdateRepository {
+	return &PostgresTaskUpdateRepository{db: ptx.tx}
+}
+
+// CustomFields returns the custom field definition repository for transaction
+func (ptx *PostgresTx) CustomFields() CustomFieldRepository {
+	return &PostgresCustomFieldRepository{db: ptx.tx}
+}
+
+// Workflows returns the project workflow repository for transaction
+func (ptx *PostgresTx) Workflows() WorkflowRepository {
+	return &PostgresWorkflowRepository{db: ptx.tx}
+}
+
+// Dependencies returns the task dependency repository for transaction
+func (ptx *PostgresTx) Dependencies() DependencyRepository {
+	return &PostgresDependencyRepository{db: ptx.tx}
+}
+
+// Milestones returns the project milestone repository for transaction
+func (ptx *PostgresTx) Milestones() MilestoneRepository {
+	return &PostgresMilestoneRepository{db: ptx.tx}
+}
+
+// Sprints returns the project sprint repository for transaction
+func (ptx *PostgresTx) Sprints() SprintRepository {
+	return &PostgresSprintRepository{db: ptx.tx}
+}
+
+// Members returns the project member repository for transaction
+func (ptx *PostgresTx) Members() MemberRepository {
+	return &PostgresMemberRepository{db: ptx.tx}
+}
+
+// Sessions returns the refresh-token session repository for transaction
+func (ptx *PostgresTx) Sessions() SessionRepository {
+	return &PostgresSessionRepository{db: ptx.tx}
+}
+
 // Commit commits the transaction
 func (ptx *PostgresTx) Commit() error {
 	return ptx.tx.Commit()
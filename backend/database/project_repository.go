@@ -23,13 +23,13 @@ func (r *PostgresProjectRepository) getExecer() execer {
 // Create creates a new project
 func (r *PostgresProjectRepository) Create(ctx context.Context, project *models.Project) (*models.Project, error) {
 	query := `
-		INSERT INTO projects (name, description, owner_id)
-		VALUES ($1, $2, $3)
+		INSERT INTO projects (name, description, owner_id, strict_dependencies)
+		VALUES ($1, $2, $3, $4)
 		RETURNING id, created_at, updated_at`
 
 	exec := r.getExecer()
 	row := exec.QueryRowContext(ctx, query,
-		project.Name, project.Description, project.OwnerID)
+		project.Name, project.Description, project.OwnerID, project.StrictDependencies)
 
 	err := row.Scan(&project.ID, &project.CreatedAt, &project.UpdatedAt)
 	if err != nil {
@@ -42,7 +42,7 @@ func (r *PostgresProjectRepository) Create(ctx context.Context, project *models.
 // GetByID gets a project by ID (only non-deleted)
 func (r *PostgresProjectRepository) GetByID(ctx context.Context, id int) (*models.Project, error) {
 	query := `
-		SELECT id, name, description, owner_id, created_at, updated_at, deleted_at
+		SELECT id, name, description, owner_id, strict_dependencies, created_at, updated_at, deleted_at
 		FROM projects WHERE id = $1 AND deleted_at IS NULL`
 
 	exec := r.getExecer()
@@ -51,7 +51,7 @@ func (r *PostgresProjectRepository) GetByID(ctx context.Context, id int) (*model
 	project := &models.Project{}
 
 	err := row.Scan(
-		&project.ID, &project.Name, &project.Description, &project.OwnerID,
+		&project.ID, &project.Name, &project.Description, &project.OwnerID, &project.StrictDependencies,
 		&project.CreatedAt, &project.UpdatedAt, &project.DeletedAt,
 	)
 
@@ -65,10 +65,13 @@ func (r *PostgresProjectRepository) GetByID(ctx context.Context, id int) (*model
 	return project, nil
 }
 
-// GetByUserID gets projects by user ID with pagination (only non-deleted)
+// GetByUserID gets projects the user owns or is a member of, with pagination (only non-deleted)
 func (r *PostgresProjectRepository) GetByUserID(ctx context.Context, userID int, limit, offset int) ([]*models.Project, int, error) {
 	// Get total count
-	countQuery := `SELECT COUNT(*) FROM projects WHERE owner_id = $1 AND deleted_at IS NULL`
+	countQuery := `
+		SELECT COUNT(*) FROM projects
+		WHERE deleted_at IS NULL
+		  AND (owner_id = $1 OR id IN (SELECT project_id FROM project_members WHERE user_id = $1))`
 	exec := r.getExecer()
 	row := exec.QueryRowContext(ctx, countQuery, userID)
 
@@ -79,9 +82,10 @@ func (r *PostgresProjectRepository) GetByUserID(ctx context.Context, userID int,
 
 	// Get projects with pagination
 	query := `
-		SELECT id, name, description, owner_id, created_at, updated_at, deleted_at
+		SELECT id, name, description, owner_id, strict_dependencies, created_at, updated_at, deleted_at
 		FROM projects 
-		WHERE owner_id = $1 AND deleted_at IS NULL
+		WHERE deleted_at IS NULL
+		  AND (owner_id = $1 OR id IN (SELECT project_id FROM project_members WHERE user_id = $1))
 		ORDER BY created_at DESC
 		LIMIT $2 OFFSET $3`
 
@@ -96,7 +100,7 @@ func (r *PostgresProjectRepository) GetByUserID(ctx context.Context, userID int,
 		project := &models.Project{}
 
 		err := rows.Scan(
-			&project.ID, &project.Name, &project.Description, &project.OwnerID,
+			&project.ID, &project.Name, &project.Description, &project.OwnerID, &project.StrictDependencies,
 			&project.CreatedAt, &project.UpdatedAt, &project.DeletedAt,
 		)
 		if err != nil {
@@ -117,13 +121,13 @@ func (r *PostgresProjectRepository) GetByUserID(ctx context.Context, userID int,
 func (r *PostgresProjectRepository) Update(ctx context.Context, project *models.Project) (*models.Project, error) {
 	query := `
 		UPDATE projects 
-		SET name = $2, description = $3, updated_at = CURRENT_TIMESTAMP
+		SET name = $2, description = $3, strict_dependencies = $4, updated_at = CURRENT_TIMESTAMP
 		WHERE id = $1
 		RETURNING updated_at`
 
 	exec := r.getExecer()
 	row := exec.QueryRowContext(ctx, query,
-		project.ID, project.Name, project.Description)
+		project.ID, project.Name, project.Description, project.StrictDependencies)
 
 	err := row.Scan(&project.UpdatedAt)
 	if err != nil {
@@ -169,7 +173,7 @@ func (r *PostgresProjectRepository) List(ctx context.Context, limit, offset int)
 
 	// Get projects with pagination
 	query := `
-		SELECT id, name, description, owner_id, created_at, updated_at, deleted_at
+		SELECT id, name, description, owner_id, strict_dependencies, created_at, updated_at, deleted_at
 		FROM projects 
 		WHERE deleted_at IS NULL
 		ORDER BY created_at DESC
@@ -186,7 +190,7 @@ func (r *PostgresProjectRepository) List(ctx context.Context, limit, offset int)
 		project := &models.Project{}
 
 		err := rows.Scan(
-			&project.ID, &project.Name, &project.Description, &project.OwnerID,
+			&project.ID, &project.Name, &project.Description, &project.OwnerID, &project.StrictDependencies,
 			&project.CreatedAt, &project.UpdatedAt, &project.DeletedAt,
 		)
 		if err != nil {
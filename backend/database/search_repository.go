@@ -0,0 +1,237 @@
+package database
+
+import (
+	"ai-project-backend/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// PostgresSearchRepository implements SearchRepository using PostgreSQL
+type PostgresSearchRepository struct {
+	db interface{}
+}
+
+// getExecer returns the appropriate execer (DB or Tx)
+func (r *PostgresSearchRepository) getExecer() execer {
+	if tx, ok := r.db.(*sql.Tx); ok {
+		return tx
+	}
+	return r.db.(*sql.DB)
+}
+
+// searchBranch is one source of search hits
+type searchBranch struct {
+	hitType     string
+	columns     string
+	from        string
+	title       string
+	description string
+	vector      string
+	projectID   string
+	deleted     string
+}
+
+// searchBranches lists the searchable tables
+var searchBranches = []searchBranch{
+	{
+		hitType:     models.SearchTypeProject,
+		columns:     "p.id, p.id, p.name, p.name, coalesce(p.description, ''), NULL::varchar",
+		from:        "projects p",
+		title:       "p.name",
+		description: "p.description",
+		vector:      "p.search_vector",
+		projectID:   "p.id",
+		deleted:     "p.deleted_at IS NULL",
+	},
+	{
+		hitType:     models.SearchTypeTask,
+		columns:     "t.id, t.project_id, p.name, t.title, coalesce(t.description, ''), t.status",
+		from:        "tasks t JOIN projects p ON p.id = t.project_id",
+		title:       "t.title",
+		description: "t.description",
+		vector:      "t.search_vector",
+		projectID:   "t.project_id",
+		deleted:     "t.deleted_at IS NULL AND p.deleted_at IS NULL",
+	},
+}
+
+// searchHits builds a CTE named hits with every project and task matching
+// the search terms that the filter's user may see. Hits are ranked by
+// full-text rank, title similarity and a bonus for titles containing the
+// whole query.
+func searchHits(filter models.SearchFilter, args *queryArgs) string {
+	query := args.add(filter.Query)
+	phrase := args.add(likePattern(filter.Query))
+	var terms []string
+	for _, term := range filter.Terms {
+		terms = append(terms, args.add(likePattern(term)))
+	}
+	var member string
+	if filter.MemberID != nil {
+		member = args.add(*filter.MemberID)
+	}
+
+	var selects []string
+	for _, branch := range searchBranches {
+		if len(filter.Types) > 0 && !containsString(filter.Types, branch.hitType) {
+			continue
+		}
+
+		conditions := []string{branch.deleted}
+		for _, term := range terms {
+			conditions = append(conditions, fmt.Sprintf("(%s ILIKE %s OR %s ILIKE %s)", branch.title, term, branch.description, term))
+		}
+		if member != "" {
+			conditions = append(conditions, fmt.Sprintf(
+				"%s IN (SELECT id FROM projects WHERE owner_id = %s UNION SELECT project_id FROM project_members WHERE user_id = %s)",
+				branch.projectID, member, member))
+		}
+
+		rank := fmt.Sprintf("ts_rank(%s, plainto_tsquery('simple', %s)) + similarity(%s, %s) + CASE WHEN %s ILIKE %s THEN 1 ELSE 0 END",
+			branch.vector, query, branch.title, query, branch.title, phrase)
+
+		selects = append(selects, fmt.Sprintf("SELECT '%s' AS type, %s, %s AS rank FROM %s WHERE %s",
+			branch.hitType, branch.columns, rank, branch.from, strings.Join(conditions, " AND ")))
+	}
+
+	return `WITH hits (type, id, project_id, project_name, title, description, status, rank) AS (
+		` + strings.Join(selects, "\n\t\tUNION ALL\n\t\t") + `
+	)`
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Search finds the projects and tasks matching the filter, best matches
+// first, and counts the hits per project and per status
+func (r *PostgresSearchRepository) Search(ctx context.Context, filter models.SearchFilter) (*models.SearchResult, error) {
+	// Each facet applies every filter except its own
+	projectCondition := func(args *queryArgs) string {
+		if len(filter.ProjectIDs) == 0 {
+			return "TRUE"
+		}
+		return "project_id = ANY(" + args.add(pq.Array(filter.ProjectIDs)) + ")"
+	}
+	statusCondition := func(args *queryArgs) string {
+		if len(filter.Statuses) == 0 {
+			return "TRUE"
+		}
+		return "status = ANY(" + args.add(pq.Array(filter.Statuses)) + ")"
+	}
+
+	exec := r.getExecer()
+	result := &models.SearchResult{}
+
+	// Get total count
+	var args queryArgs
+	query := searchHits(filter, &args) + `
+	SELECT COUNT(*) FROM hits WHERE ` + projectCondition(&args) + ` AND ` + statusCondition(&args)
+	if err := exec.QueryRowContext(ctx, query, args...).Scan(&result.Total); err != nil {
+		return nil, fmt.Errorf("failed to count search results: %w", err)
+	}
+
+	// Get hits with pagination
+	args = nil
+	query = searchHits(filter, &args) + `
+	SELECT type, id, project_id, project_name, title, description, status, rank
+	FROM hits
+	WHERE ` + projectCondition(&args) + ` AND ` + statusCondition(&args) + `
+	ORDER BY rank DESC, type, id DESC
+	LIMIT ` + args.add(filter.Limit) + ` OFFSET ` + args.add(filter.Offset)
+
+	rows, err := exec.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		hit := &models.SearchHit{}
+		var status sql.NullString
+		err := rows.Scan(&hit.Type, &hit.ID, &hit.ProjectID, &hit.ProjectName,
+			&hit.Title, &hit.Description, &status, &hit.Rank)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		if status.Valid {
+			hit.Status = &status.String
+		}
+		result.Hits = append(result.Hits, hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	// Count hits per project
+	args = nil
+	query = searchHits(filter, &args) + `
+	SELECT project_id, project_name, COUNT(*)
+	FROM hits
+	WHERE ` + statusCondition(&args) + `
+	GROUP BY project_id, project_name
+	ORDER BY COUNT(*) DESC, project_name`
+	result.Facets.Projects, err = r.queryFacets(ctx, query, args, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// Count task hits per status
+	args = nil
+	query = searchHits(filter, &args) + `
+	SELECT status, status, COUNT(*)
+	FROM hits
+	WHERE status IS NOT NULL AND ` + projectCondition(&args) + `
+	GROUP BY status
+	ORDER BY COUNT(*) DESC, status`
+	result.Facets.Statuses, err = r.queryFacets(ctx, query, args, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// queryFacets runs a facet query selecting value, label and count
+func (r *PostgresSearchRepository) queryFacets(ctx context.Context, query string, args queryArgs, intValue bool) ([]*models.SearchFacet, error) {
+	exec := r.getExecer()
+	rows, err := exec.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count search facets: %w", err)
+	}
+	defer rows.Close()
+
+	facets := []*models.SearchFacet{}
+	for rows.Next() {
+		facet := &models.SearchFacet{}
+		var id int
+		var value string
+		var err error
+		if intValue {
+			err = rows.Scan(&id, &facet.Label, &facet.Count)
+			facet.Value = id
+		} else {
+			err = rows.Scan(&value, &facet.Label, &facet.Count)
+			facet.Value = value
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan search facet: %w", err)
+		}
+		facets = append(facets, facet)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return facets, nil
+}
\ No newline at end of file
@@ -0,0 +1,240 @@
+package database
+
+import (
+	"ai-project-backend/models"
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// PostgresSessionRepository implements SessionRepository using PostgreSQL
+type PostgresSessionRepository struct {
+	db interface{}
+}
+
+// getExecer returns the appropriate execer (DB or Tx)
+func (r *PostgresSessionRepository) getExecer() execer {
+	if tx, ok := r.db.(*sql.Tx); ok {
+		return tx
+	}
+	return r.db.(*sql.DB)
+}
+
+// Create stores a new refresh token
+func (r *PostgresSessionRepository) Create(ctx context.Context, session *models.AuthSession) (*models.AuthSession, error) {
+	query := `
+		INSERT INTO auth_sessions (user_id, family_id, token_hash, ip_address, user_agent, expires_at, mfa_verified)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at`
+
+	exec := r.getExecer()
+	row := exec.QueryRowContext(ctx, query,
+		session.UserID, session.FamilyID, session.TokenHash,
+		session.IPAddress, session.UserAgent, session.ExpiresAt, session.MFA)
+
+	err := row.Scan(&session.ID, &session.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return session, nil
+}
+
+// GetByTokenHash gets a refresh token by its hash, including revoked ones
+func (r *PostgresSessionRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.AuthSession, error) {
+	query := `
+		SELECT id, user_id, family_id, token_hash, replaced_by, ip_address, user_agent,
+		       mfa_verified, expires_at, revoked_at, created_at
+		FROM auth_sessions WHERE token_hash = $1`
+
+	exec := r.getExecer()
+	row := exec.QueryRowContext(ctx, query, tokenHash)
+
+	session := &models.AuthSession{}
+	var replacedBy sql.NullInt64
+	var ipAddress, userAgent sql.NullString
+
+	err := row.Scan(
+		&session.ID, &session.UserID, &session.FamilyID, &session.TokenHash,
+		&replacedBy, &ipAddress, &userAgent,
+		&session.MFA, &session.ExpiresAt, &session.RevokedAt, &session.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("session not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	if replacedBy.Valid {
+		intVal := int(replacedBy.Int64)
+		session.ReplacedBy = &intVal
+	}
+	if ipAddress.Valid {
+		session.IPAddress = &ipAddress.String
+	}
+	if userAgent.Valid {
+		session.UserAgent = &userAgent.String
+	}
+
+	return session, nil
+}
+
+// MarkReplaced revokes a refresh token as part of rotation and links it to its successor.
+// It fails if the token was already revoked, so concurrent rotations cannot both succeed.
+func (r *PostgresSessionRepository) MarkReplaced(ctx context.Context, id, replacedBy int) error {
+	query := `
+		UPDATE auth_sessions
+		SET revoked_at = NOW(), replaced_by = $2
+		WHERE id = $1 AND revoked_at IS NULL`
+
+	exec := r.getExecer()
+	result, err := exec.ExecContext(ctx, query, id, replacedBy)
+	if err != nil {
+		return fmt.Errorf("failed to rotate session: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("session already revoked")
+	}
+
+	return nil
+}
+
+// RevokeFamily revokes every live refresh token in a session family
+func (r *PostgresSessionRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	query := `UPDATE auth_sessions SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL`
+
+	exec := r.getExecer()
+	_, err := exec.ExecContext(ctx, query, familyID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeUserFamily revokes a session family owned by the given user
+func (r *PostgresSessionRepository) RevokeUserFamily(ctx context.Context, userID int, familyID string) error {
+	query := `
+		UPDATE auth_sessions SET revoked_at = NOW()
+		WHERE user_id = $1 AND family_id = $2 AND revoked_at IS NULL AND expires_at > NOW()`
+
+	exec := r.getExecer()
+	result, err := exec.ExecContext(ctx, query, userID, familyID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("session not found")
+	}
+
+	return nil
+}
+
+// RevokeAllForUser revokes every live session of a user
+func (r *PostgresSessionRepository) RevokeAllForUser(ctx context.Context, userID int) error {
+	query := `UPDATE auth_sessions SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`
+
+	exec := r.getExecer()
+	_, err := exec.ExecContext(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeOtherFamilies revokes every live session of a user except one family,
+// typically the caller's own session
+func (r *PostgresSessionRepository) RevokeOtherFamilies(ctx context.Context, userID int, keepFamilyID string) error {
+	query := `
+		UPDATE auth_sessions SET revoked_at = NOW()
+		WHERE user_id = $1 AND family_id <> $2 AND revoked_at IS NULL`
+
+	exec := r.getExecer()
+	_, err := exec.ExecContext(ctx, query, userID, keepFamilyID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return nil
+}
+
+// IsFamilyActive reports whether a session family still has a live refresh token
+func (r *PostgresSessionRepository) IsFamilyActive(ctx context.Context, familyID string) (bool, error) {
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM auth_sessions
+			WHERE family_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		)`
+
+	exec := r.getExecer()
+	row := exec.QueryRowContext(ctx, query, familyID)
+
+	var active bool
+	if err := row.Scan(&active); err != nil {
+		return false, fmt.Errorf("failed to check session: %w", err)
+	}
+
+	return active, nil
+}
+
+// ListActiveByUser lists a user's live sessions, one entry per family
+func (r *PostgresSessionRepository) ListActiveByUser(ctx context.Context, userID int) ([]*models.SessionInfo, error) {
+	query := `
+		SELECT s.family_id, s.ip_address, s.user_agent,
+		       (SELECT MIN(f.created_at) FROM auth_sessions f WHERE f.family_id = s.family_id),
+		       s.created_at, s.expires_at
+		FROM auth_sessions s
+		WHERE s.user_id = $1 AND s.revoked_at IS NULL AND s.expires_at > NOW()
+		ORDER BY s.created_at DESC`
+
+	exec := r.getExecer()
+	rows, err := exec.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*models.SessionInfo
+	for rows.Next() {
+		session := &models.SessionInfo{}
+		var ipAddress, userAgent sql.NullString
+
+		err := rows.Scan(
+			&session.ID, &ipAddress, &userAgent,
+			&session.CreatedAt, &session.LastUsedAt, &session.ExpiresAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+
+		if ipAddress.Valid {
+			session.IPAddress = &ipAddress.String
+		}
+		if userAgent.Valid {
+			session.UserAgent = &userAgent.String
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return sessions, nil
+}
@@ -0,0 +1,242 @@
+package database
+
+import (
+	"ai-project-backend/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// PostgresSprintRepository implements SprintRepository using PostgreSQL
+type PostgresSprintRepository struct {
+	db interface{}
+}
+
+// getExecer returns the appropriate execer (DB or Tx)
+func (r *PostgresSprintRepository) getExecer() execer {
+	if tx, ok := r.db.(*sql.Tx); ok {
+		return tx
+	}
+	return r.db.(*sql.DB)
+}
+
+// sprintSelect selects sprints with the progress of their non-deleted tasks;
+// callers add conditions on s and must end with sprintGroupBy
+const sprintSelect = `
+	SELECT s.id, s.project_id, s.name, s.goal, s.start_date, s.end_date, s.state,
+	       s.committed_hours, s.completed_hours, s.completed_tasks, s.carried_over_tasks,
+	       s.started_at, s.closed_at, s.created_by, s.created_at, s.updated_at,
+	       COUNT(t.id),
+	       COUNT(t.id) FILTER (WHERE task_status_category(t.project_id, t.status) = 'done'),
+	       COALESCE(SUM(t.estimated_hours), 0),
+	       COALESCE(SUM(t.estimated_hours) FILTER (WHERE task_status_category(t.project_id, t.status) = 'done'), 0)
+	FROM sprints s
+	LEFT JOIN tasks t ON t.sprint_id = s.id AND t.deleted_at IS NULL`
+
+// sprintGroupBy ends a sprintSelect query
+const sprintGroupBy = `
+	GROUP BY s.id`
+
+// scanSprint scans a row selected with sprintSelect
+func scanSprint(scanner rowScanner) (*models.Sprint, error) {
+	sprint := &models.Sprint{Progress: &models.SprintProgress{}}
+	var goal sql.NullString
+	var committedHours, completedHours sql.NullFloat64
+	var completedTasks, carriedOverTasks, createdBy sql.NullInt64
+	var startedAt, closedAt sql.NullTime
+	progress := sprint.Progress
+
+	err := scanner.Scan(
+		&sprint.ID, &sprint.ProjectID, &sprint.Name, &goal, &sprint.StartDate, &sprint.EndDate,
+		&sprint.State, &committedHours, &completedHours, &completedTasks, &carriedOverTasks,
+		&startedAt, &closedAt, &createdBy, &sprint.CreatedAt, &sprint.UpdatedAt,
+		&progress.TotalTasks, &progress.DoneTasks, &progress.TotalHours, &progress.DoneHours,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	sprint.Goal = goal.String
+	if committedHours.Valid {
+		sprint.CommittedHours = &committedHours.Float64
+	}
+	if completedHours.Valid {
+		sprint.CompletedHours = &completedHours.Float64
+	}
+	if completedTasks.Valid {
+		intVal := int(completedTasks.Int64)
+		sprint.CompletedTasks = &intVal
+	}
+	if carriedOverTasks.Valid {
+		intVal := int(carriedOverTasks.Int64)
+		sprint.CarriedOverTasks = &intVal
+	}
+	if startedAt.Valid {
+		sprint.StartedAt = &startedAt.Time
+	}
+	if closedAt.Valid {
+		sprint.ClosedAt = &closedAt.Time
+	}
+	if createdBy.Valid {
+		intVal := int(createdBy.Int64)
+		sprint.CreatedBy = &intVal
+	}
+
+	return sprint, nil
+}
+
+// querySprints runs a sprintSelect query and scans every row
+func (r *PostgresSprintRepository) querySprints(ctx context.Context, query string, args ...interface{}) ([]*models.Sprint, error) {
+	exec := r.getExecer()
+	rows, err := exec.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sprints: %w", err)
+	}
+	defer rows.Close()
+
+	sprints := []*models.Sprint{}
+	for rows.Next() {
+		sprint, err := scanSprint(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan sprint: %w", err)
+		}
+		sprints = append(sprints, sprint)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return sprints, nil
+}
+
+// List gets the sprints of a project with their progress, ordered by start date
+func (r *PostgresSprintRepository) List(ctx context.Context, projectID int) ([]*models.Sprint, error) {
+	query := sprintSelect + `
+	WHERE s.project_id = $1` + sprintGroupBy + `
+	ORDER BY s.start_date, s.id`
+
+	return r.querySprints(ctx, query, projectID)
+}
+
+// ListClosed gets the most recently ended closed sprints of a project, newest first
+func (r *PostgresSprintRepository) ListClosed(ctx context.Context, projectID int, limit int) ([]*models.Sprint, error) {
+	query := sprintSelect + `
+	WHERE s.project_id = $1 AND s.state = 'closed'` + sprintGroupBy + `
+	ORDER BY s.end_date DESC, s.id DESC
+	LIMIT $2`
+
+	return r.querySprints(ctx, query, projectID, limit)
+}
+
+// GetByID gets a sprint with its progress by ID
+func (r *PostgresSprintRepository) GetByID(ctx context.Context, id int) (*models.Sprint, error) {
+	query := sprintSelect + `
+	WHERE s.id = $1` + sprintGroupBy
+
+	exec := r.getExecer()
+	sprint, err := scanSprint(exec.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("sprint not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sprint: %w", err)
+	}
+
+	return sprint, nil
+}
+
+// LockState locks a sprint row until the transaction ends and returns its
+// current state. It must be called inside a transaction.
+func (r *PostgresSprintRepository) LockState(ctx context.Context, id int) (string, error) {
+	query := `SELECT state FROM sprints WHERE id = $1 FOR UPDATE`
+
+	var state string
+	exec := r.getExecer()
+	err := exec.QueryRowContext(ctx, query, id).Scan(&state)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("sprint not found")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to lock sprint: %w", err)
+	}
+
+	return state, nil
+}
+
+// Create creates a sprint
+func (r *PostgresSprintRepository) Create(ctx context.Context, sprint *models.Sprint) (*models.Sprint, error) {
+	query := `
+		INSERT INTO sprints (project_id, name, goal, start_date, end_date, state, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at`
+
+	exec := r.getExecer()
+	row := exec.QueryRowContext(ctx, query,
+		sprint.ProjectID, sprint.Name, sprint.Goal, sprint.StartDate, sprint.EndDate,
+		sprint.State, sprint.CreatedBy)
+
+	err := row.Scan(&sprint.ID, &sprint.CreatedAt, &sprint.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sprint: %w", err)
+	}
+
+	return sprint, nil
+}
+
+// Update updates a sprint, including its state and recorded figures. Only
+// one sprint of a project can be active.
+func (r *PostgresSprintRepository) Update(ctx context.Context, sprint *models.Sprint) (*models.Sprint, error) {
+	query := `
+		UPDATE sprints
+		SET name = $2, goal = $3, start_date = $4, end_date = $5, state = $6,
+		    committed_hours = $7, completed_hours = $8, completed_tasks = $9,
+		    carried_over_tasks = $10, started_at = $11, closed_at = $12
+		WHERE id = $1
+		RETURNING updated_at`
+
+	exec := r.getExecer()
+	row := exec.QueryRowContext(ctx, query,
+		sprint.ID, sprint.Name, sprint.Goal, sprint.StartDate, sprint.EndDate, sprint.State,
+		sprint.CommittedHours, sprint.CompletedHours, sprint.CompletedTasks,
+		sprint.CarriedOverTasks, sprint.StartedAt, sprint.ClosedAt)
+
+	err := row.Scan(&sprint.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("sprint not found")
+	}
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return nil, fmt.Errorf("active sprint already exists")
+		}
+		return nil, fmt.Errorf("failed to update sprint: %w", err)
+	}
+
+	return sprint, nil
+}
+
+// Delete deletes a sprint; its tasks return to the backlog through the foreign key
+func (r *PostgresSprintRepository) Delete(ctx context.Context, id int) error {
+	query := `DELETE FROM sprints WHERE id = $1`
+
+	exec := r.getExecer()
+	result, err := exec.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete sprint: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("sprint not found")
+	}
+
+	return nil
+}
\ No newline at end of file
@@ -41,7 +41,7 @@ func (r *PostgresSystemRepository) GetRecycledTasks(ctx context.Context, limit,
 	// Get recycled tasks with pagination
 	query := `
 		SELECT id, project_id, title, description, status, assignee_id, due_date, 
-		       custom_fields, created_at, deleted_at, project_name, assignee_username
+		       custom_fields, created_at, deleted_at, project_name, assignee_username, parent_id
 		FROM recycled_tasks
 		ORDER BY deleted_at DESC
 		LIMIT $1 OFFSET $2`
@@ -59,11 +59,13 @@ func (r *PostgresSystemRepository) GetRecycledTasks(ctx context.Context, limit,
 		var assigneeID sql.NullInt64
 		var dueDate sql.NullTime
 		var assigneeUsername sql.NullString
+		var parentID sql.NullInt64
 
 		err := rows.Scan(
 			&task.ID, &task.ProjectID, &task.Title, &task.Description,
 			&task.Status, &assigneeID, &dueDate, &customFieldsJSON,
 			&task.CreatedAt, &task.DeletedAt, &task.ProjectName, &assigneeUsername,
+			&parentID,
 		)
 		if err != nil {
 			return nil, 0, fmt.Errorf("failed to scan recycled task: %w", err)
@@ -79,6 +81,10 @@ func (r *PostgresSystemRepository) GetRecycledTasks(ctx context.Context, limit,
 		if assigneeUsername.Valid {
 			task.AssigneeUsername = &assigneeUsername.String
 		}
+		if parentID.Valid {
+			intVal := int(parentID.Int64)
+			task.ParentID = &intVal
+		}
 
 		if len(customFieldsJSON) > 0 {
 			if err := json.Unmarshal(customFieldsJSON, &task.CustomFields); err != nil {
@@ -96,11 +102,39 @@ func (r *PostgresSystemRepository) GetRecycledTasks(ctx context.Context, limit,
 	return tasks, total, nil
 }
 
-// RestoreTask restores a deleted task
+// RestoreTask restores a deleted task together with the descendants that were
+// deleted with it. A task cannot be restored while its parent is deleted.
 func (r *PostgresSystemRepository) RestoreTask(ctx context.Context, id int) error {
-	query := `UPDATE tasks SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL`
-
 	exec := r.getExecer()
+
+	var parentDeleted bool
+	checkQuery := `
+		SELECT p.id IS NOT NULL
+		FROM tasks t
+		LEFT JOIN tasks p ON p.id = t.parent_id AND p.deleted_at IS NOT NULL
+		WHERE t.id = $1 AND t.deleted_at IS NOT NULL`
+	err := exec.QueryRowContext(ctx, checkQuery, id).Scan(&parentDeleted)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("task not found in recycle bin")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to restore task: %w", err)
+	}
+	if parentDeleted {
+		return fmt.Errorf("parent task is deleted")
+	}
+
+	query := `
+		WITH RECURSIVE subtree AS (
+			SELECT id, deleted_at FROM tasks WHERE id = $1 AND deleted_at IS NOT NULL
+			UNION ALL
+			SELECT t.id, s.deleted_at FROM tasks t
+			JOIN subtree s ON t.parent_id = s.id
+			WHERE t.deleted_at = s.deleted_at
+		)
+		UPDATE tasks SET deleted_at = NULL
+		WHERE id IN (SELECT id FROM subtree)`
+
 	result, err := exec.ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to restore task: %w", err)
@@ -261,6 +295,49 @@ func (r *PostgresSystemRepository) createAuditLog(ctx context.Context, log *mode
 	return nil
 }
 
+// GetSetting loads the JSON setting stored under key into value
+func (r *PostgresSystemRepository) GetSetting(ctx context.Context, key string, value interface{}) error {
+	query := `SELECT value FROM system_settings WHERE key = $1`
+
+	exec := r.getExecer()
+	row := exec.QueryRowContext(ctx, query, key)
+
+	var data []byte
+	err := row.Scan(&data)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("setting not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get setting: %w", err)
+	}
+
+	if err := json.Unmarshal(data, value); err != nil {
+		return fmt.Errorf("failed to unmarshal setting: %w", err)
+	}
+
+	return nil
+}
+
+// SetSetting stores value as JSON under key, creating or replacing it
+func (r *PostgresSystemRepository) SetSetting(ctx context.Context, key string, value interface{}, updatedBy *int) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal setting: %w", err)
+	}
+
+	query := `
+		INSERT INTO system_settings (key, value, updated_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, updated_by = EXCLUDED.updated_by`
+
+	exec := r.getExecer()
+	_, err = exec.ExecContext(ctx, query, key, data, updatedBy)
+	if err != nil {
+		return fmt.Errorf("failed to set setting: %w", err)
+	}
+
+	return nil
+}
 
 // GetRecycledProjects gets all deleted projects with pagination
 func (r *PostgresSystemRepository) GetRecycledProjects(ctx context.Context, limit, offset int) ([]*models.RecycledProject, int, error) {
@@ -6,6 +6,9 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
 )
 
 // PostgresTaskRepository implements TaskRepository using PostgreSQL
@@ -21,25 +24,122 @@ func (r *PostgresTaskRepository) getExecer() execer {
 	return r.db.(*sql.DB)
 }
 
+// taskColumns lists the tasks columns read by scanTask, in order
+const taskColumns = `id, project_id, title, description, status, assignee_id, due_date,
+	custom_fields, parent_id, level, milestone_id, sprint_id, priority, estimated_hours, actual_hours, progress, tags,
+	metadata, created_at, updated_at, deleted_at`
+
+// scanTask scans a row selected with taskColumns
+func scanTask(scanner rowScanner) (*models.Task, error) {
+	task := &models.Task{}
+	var description sql.NullString
+	var customFieldsJSON, metadataJSON []byte
+	var assigneeID, parentID, milestoneID, sprintID sql.NullInt64
+	var dueDate sql.NullTime
+	var estimatedHours, actualHours sql.NullFloat64
+
+	err := scanner.Scan(
+		&task.ID, &task.ProjectID, &task.Title, &description,
+		&task.Status, &assigneeID, &dueDate, &customFieldsJSON,
+		&parentID, &task.Level, &milestoneID, &sprintID, &task.Priority, &estimatedHours, &actualHours,
+		&task.Progress, pq.Array(&task.Tags), &metadataJSON,
+		&task.CreatedAt, &task.UpdatedAt, &task.DeletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	task.Description = description.String
+	if assigneeID.Valid {
+		intVal := int(assigneeID.Int64)
+		task.AssigneeID = &intVal
+	}
+	if dueDate.Valid {
+		task.DueDate = &dueDate.Time
+	}
+	if parentID.Valid {
+		intVal := int(parentID.Int64)
+		task.ParentID = &intVal
+	}
+	if milestoneID.Valid {
+		intVal := int(milestoneID.Int64)
+		task.MilestoneID = &intVal
+	}
+	if sprintID.Valid {
+		intVal := int(sprintID.Int64)
+		task.SprintID = &intVal
+	}
+	if estimatedHours.Valid {
+		task.EstimatedHours = &estimatedHours.Float64
+	}
+	if actualHours.Valid {
+		task.ActualHours = &actualHours.Float64
+	}
+
+	if len(customFieldsJSON) > 0 {
+		if err := json.Unmarshal(customFieldsJSON, &task.CustomFields); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal custom fields: %w", err)
+		}
+	}
+	if len(metadataJSON) > 0 {
+		if err := json.Unmarshal(metadataJSON, &task.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+		}
+	}
+
+	return task, nil
+}
+
+// queryTasks runs a query selecting taskColumns and scans every row
+func (r *PostgresTaskRepository) queryTasks(ctx context.Context, query string, args ...interface{}) ([]*models.Task, error) {
+	exec := r.getExecer()
+	rows, err := exec.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []*models.Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan task: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return tasks, nil
+}
+
 // Create creates a new task
 func (r *PostgresTaskRepository) Create(ctx context.Context, task *models.Task) (*models.Task, error) {
 	customFieldsJSON, err := json.Marshal(task.CustomFields)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal custom fields: %w", err)
 	}
+	metadataJSON, err := json.Marshal(task.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
 
 	query := `
-		INSERT INTO tasks (project_id, title, description, status, assignee_id, due_date, custom_fields)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, created_at`
+		INSERT INTO tasks (project_id, title, description, status, assignee_id, due_date, custom_fields, parent_id, level,
+		                   milestone_id, sprint_id, priority, estimated_hours, actual_hours, progress, tags, metadata)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		RETURNING id, created_at, updated_at`
 
 	exec := r.getExecer()
 	row := exec.QueryRowContext(ctx, query,
 		task.ProjectID, task.Title, task.Description, task.Status,
-		task.AssigneeID, task.DueDate, customFieldsJSON)
+		task.AssigneeID, task.DueDate, customFieldsJSON, task.ParentID, task.Level,
+		task.MilestoneID, task.SprintID, task.Priority, task.EstimatedHours, task.ActualHours, task.Progress,
+		pq.Array(task.Tags), metadataJSON)
 
-	err = row.Scan(&task.ID, &task.CreatedAt)
-	task.UpdatedAt = task.CreatedAt
+	err = row.Scan(&task.ID, &task.CreatedAt, &task.UpdatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create task: %w", err)
 	}
@@ -49,26 +149,10 @@ func (r *PostgresTaskRepository) Create(ctx context.Context, task *models.Task)
 
 // GetByID gets a task by ID (only non-deleted)
 func (r *PostgresTaskRepository) GetByID(ctx context.Context, id int) (*models.Task, error) {
-	query := `
-		SELECT id, project_id, title, description, status, assignee_id, due_date, 
-		       custom_fields, created_at, deleted_at
-		FROM tasks WHERE id = $1 AND deleted_at IS NULL`
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE id = $1 AND deleted_at IS NULL`
 
 	exec := r.getExecer()
-	row := exec.QueryRowContext(ctx, query, id)
-
-	task := &models.Task{}
-	var customFieldsJSON []byte
-	var assigneeID sql.NullInt64
-	var dueDate sql.NullTime
-
-	err := row.Scan(
-		&task.ID, &task.ProjectID, &task.Title, &task.Description,
-		&task.Status, &assigneeID, &dueDate, &customFieldsJSON,
-		&task.CreatedAt, &task.DeletedAt,
-	)
-	task.UpdatedAt = task.CreatedAt
-
+	task, err := scanTask(exec.QueryRowContext(ctx, query, id))
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("task not found")
 	}
@@ -76,20 +160,6 @@ func (r *PostgresTaskRepository) GetByID(ctx context.Context, id int) (*models.T
 		return nil, fmt.Errorf("failed to get task: %w", err)
 	}
 
-	if assigneeID.Valid {
-		intVal := int(assigneeID.Int64)
-		task.AssigneeID = &intVal
-	}
-	if dueDate.Valid {
-		task.DueDate = &dueDate.Time
-	}
-
-	if len(customFieldsJSON) > 0 {
-		if err := json.Unmarshal(customFieldsJSON, &task.CustomFields); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal custom fields: %w", err)
-		}
-	}
-
 	return task, nil
 }
 
@@ -105,81 +175,361 @@ func (r *PostgresTaskRepository) GetByProjectID(ctx context.Context, projectID i
 		return nil, 0, fmt.Errorf("failed to get task count: %w", err)
 	}
 
-	// Get tasks with pagination (matching actual table structure)
+	// Get tasks with pagination
 	query := `
-		SELECT id, project_id, title, description, status, assignee_id, due_date, 
-		       custom_fields, created_at, created_at as updated_at, deleted_at
-		FROM tasks 
+		SELECT ` + taskColumns + `
+		FROM tasks
 		WHERE project_id = $1 AND deleted_at IS NULL
 		ORDER BY created_at DESC
 		LIMIT $2 OFFSET $3`
 
-	rows, err := exec.QueryContext(ctx, query, projectID, limit, offset)
+	tasks, err := r.queryTasks(ctx, query, projectID, limit, offset)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to list tasks: %w", err)
+		return nil, 0, err
 	}
-	defer rows.Close()
 
-	var tasks []*models.Task
-	for rows.Next() {
-		task := &models.Task{}
-		var customFieldsJSON []byte
-		var assigneeID sql.NullInt64
-		var dueDate sql.NullTime
-
-		err := rows.Scan(
-			&task.ID, &task.ProjectID, &task.Title, &task.Description,
-			&task.Status, &assigneeID, &dueDate, &customFieldsJSON,
-			&task.CreatedAt, &task.UpdatedAt, &task.DeletedAt,
-		)
-		if err != nil {
-			return nil, 0, fmt.Errorf("failed to scan task: %w", err)
-		}
+	return tasks, total, nil
+}
 
-		if assigneeID.Valid {
-			intVal := int(assigneeID.Int64)
-			task.AssigneeID = &intVal
+// GetAllByProjectID gets every non-deleted task of a project, ordered by ID
+func (r *PostgresTaskRepository) GetAllByProjectID(ctx context.Context, projectID int) ([]*models.Task, error) {
+	query := `
+		SELECT ` + taskColumns + `
+		FROM tasks
+		WHERE project_id = $1 AND deleted_at IS NULL
+		ORDER BY id`
+
+	return r.queryTasks(ctx, query, projectID)
+}
+
+// taskSortColumns maps the sort keys accepted by List to SQL expressions.
+// Statuses sort in workflow order, using the default workflow for projects
+// without their own.
+var taskSortColumns = map[string]string{
+	"id":              "id",
+	"created_at":      "created_at",
+	"updated_at":      "updated_at",
+	"title":           "title",
+	"status":          "COALESCE((SELECT ws.position FROM workflow_statuses ws WHERE ws.project_id = t.project_id AND ws.key = t.status), CASE t.status WHEN 'todo' THEN 0 WHEN 'in_progress' THEN 1 WHEN 'completed' THEN 2 WHEN 'cancelled' THEN 3 END)",
+	"assignee_id":     "assignee_id",
+	"milestone_id":    "milestone_id",
+	"sprint_id":       "sprint_id",
+	"project_id":      "project_id",
+	"due_date":        "due_date",
+	"priority":        "CASE priority WHEN 'high' THEN 3 WHEN 'medium' THEN 2 ELSE 1 END",
+	"progress":        "progress",
+	"estimated_hours": "estimated_hours",
+	"actual_hours":    "actual_hours",
+}
+
+// taskOrderBy builds an ORDER BY clause from a comma separated list of sort
+// keys, each optionally prefixed with "-" for descending order. A key
+// cf.<path> sorts by a custom field, comparing JSON values. Rows with NULL
+// values sort last either way.
+func taskOrderBy(sort string) (string, error) {
+	var terms []string
+	for _, key := range strings.Split(sort, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		direction := "ASC"
+		if strings.HasPrefix(key, "-") {
+			direction = "DESC"
+			key = key[1:]
 		}
-		if dueDate.Valid {
-			task.DueDate = &dueDate.Time
+		column, ok := taskSortColumns[key]
+		if strings.HasPrefix(key, "cf.") {
+			// The path only holds letters, digits, "_" and "-", so it can be
+			// written as an array literal
+			var path []string
+			path, ok = models.ParseCustomFieldPath(key[3:])
+			column = "custom_fields #> '{" + strings.Join(path, ",") + "}'"
+		}
+		if !ok {
+			return "", fmt.Errorf("invalid sort key")
+		}
+		terms = append(terms, column+" "+direction+" NULLS LAST")
+	}
+
+	if len(terms) == 0 {
+		return "created_at DESC, id DESC", nil
+	}
+	return strings.Join(terms, ", ") + ", id DESC", nil
+}
+
+// queryArgs collects the arguments of a dynamically built query
+type queryArgs []interface{}
+
+// add appends an argument and returns its placeholder
+func (a *queryArgs) add(value interface{}) string {
+	*a = append(*a, value)
+	return fmt.Sprintf("$%d", len(*a))
+}
+
+// likePattern escapes a search term for a substring ILIKE match
+func likePattern(term string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	return "%" + replacer.Replace(term) + "%"
+}
+
+// taskFilterConditions translates a task filter into SQL conditions on tasks t
+func taskFilterConditions(filter models.TaskFilter, args *queryArgs) []string {
+	conditions := []string{"t.deleted_at IS NULL"}
+
+	if len(filter.Status) > 0 {
+		conditions = append(conditions, "t.status = ANY("+args.add(pq.Array(filter.Status))+")")
+	}
+	switch {
+	case len(filter.AssigneeIDs) > 0 && filter.Unassigned:
+		conditions = append(conditions, "(t.assignee_id = ANY("+args.add(pq.Array(filter.AssigneeIDs))+") OR t.assignee_id IS NULL)")
+	case len(filter.AssigneeIDs) > 0:
+		conditions = append(conditions, "t.assignee_id = ANY("+args.add(pq.Array(filter.AssigneeIDs))+")")
+	case filter.Unassigned:
+		conditions = append(conditions, "t.assignee_id IS NULL")
+	}
+	switch {
+	case len(filter.MilestoneIDs) > 0 && filter.NoMilestone:
+		conditions = append(conditions, "(t.milestone_id = ANY("+args.add(pq.Array(filter.MilestoneIDs))+") OR t.milestone_id IS NULL)")
+	case len(filter.MilestoneIDs) > 0:
+		conditions = append(conditions, "t.milestone_id = ANY("+args.add(pq.Array(filter.MilestoneIDs))+")")
+	case filter.NoMilestone:
+		conditions = append(conditions, "t.milestone_id IS NULL")
+	}
+	switch {
+	case len(filter.SprintIDs) > 0 && filter.Backlog:
+		conditions = append(conditions, "(t.sprint_id = ANY("+args.add(pq.Array(filter.SprintIDs))+") OR t.sprint_id IS NULL)")
+	case len(filter.SprintIDs) > 0:
+		conditions = append(conditions, "t.sprint_id = ANY("+args.add(pq.Array(filter.SprintIDs))+")")
+	case filter.Backlog:
+		conditions = append(conditions, "t.sprint_id IS NULL")
+	}
+	if filter.DueFrom != nil {
+		conditions = append(conditions, "t.due_date >= "+args.add(*filter.DueFrom))
+	}
+	if filter.DueTo != nil {
+		conditions = append(conditions, "t.due_date <= "+args.add(*filter.DueTo))
+	}
+	if filter.Search != "" {
+		pattern := args.add(likePattern(filter.Search))
+		conditions = append(conditions, "(t.title ILIKE "+pattern+" OR t.description ILIKE "+pattern+")")
+	}
+	if len(filter.Priority) > 0 {
+		conditions = append(conditions, "t.priority = ANY("+args.add(pq.Array(filter.Priority))+")")
+	}
+	if len(filter.Tag) > 0 {
+		conditions = append(conditions, "t.tags && "+args.add(pq.Array(filter.Tag))+"::text[]")
+	}
+	if filter.MinProgress != nil {
+		conditions = append(conditions, "t.progress >= "+args.add(*filter.MinProgress))
+	}
+	if filter.MaxProgress != nil {
+		conditions = append(conditions, "t.progress <= "+args.add(*filter.MaxProgress))
+	}
+	if filter.MinEstimatedHours != nil {
+		conditions = append(conditions, "t.estimated_hours >= "+args.add(*filter.MinEstimatedHours))
+	}
+	if filter.MaxEstimatedHours != nil {
+		conditions = append(conditions, "t.estimated_hours <= "+args.add(*filter.MaxEstimatedHours))
+	}
+	if filter.MinActualHours != nil {
+		conditions = append(conditions, "t.actual_hours >= "+args.add(*filter.MinActualHours))
+	}
+	if filter.MaxActualHours != nil {
+		conditions = append(conditions, "t.actual_hours <= "+args.add(*filter.MaxActualHours))
+	}
+	if len(filter.ProjectID) > 0 {
+		conditions = append(conditions, "t.project_id = ANY("+args.add(pq.Array(filter.ProjectID))+")")
+	}
+	for _, condition := range filter.CustomFields {
+		conditions = append(conditions, customFieldCondition(condition, args))
+	}
+
+	return conditions
+}
+
+// customFieldComparisons maps the ordering operators of custom field conditions to SQL
+var customFieldComparisons = map[string]string{
+	">=": ">=",
+	"<=": "<=",
+	">":  ">",
+	"<":  "<",
+}
+
+// customFieldCondition translates a custom field condition into SQL on tasks t.
+// Equality uses JSONB containment, which the GIN index on custom_fields
+// serves; ordering compares numbers numerically and strings as text, and
+// never matches values of another type.
+func customFieldCondition(condition models.CustomFieldCondition, args *queryArgs) string {
+	if op, ok := customFieldComparisons[condition.Operator]; ok {
+		path := args.add(pq.Array(condition.Path))
+		raw := condition.Values[0]
+		if value, ok := models.CustomFieldValue(raw).(float64); ok {
+			return fmt.Sprintf("CASE WHEN jsonb_typeof(t.custom_fields #> %s) = 'number' THEN (t.custom_fields #>> %s)::numeric %s %s END",
+				path, path, op, args.add(value))
 		}
+		return fmt.Sprintf("CASE WHEN jsonb_typeof(t.custom_fields #> %s) = 'string' THEN (t.custom_fields #>> %s) %s %s END",
+			path, path, op, args.add(raw))
+	}
 
-		if len(customFieldsJSON) > 0 {
-			if err := json.Unmarshal(customFieldsJSON, &task.CustomFields); err != nil {
-				return nil, 0, fmt.Errorf("failed to unmarshal custom fields: %w", err)
+	// A value that looks like a number or boolean also matches its string form
+	var matches []string
+	for _, raw := range condition.Values {
+		candidates := []interface{}{models.CustomFieldValue(raw)}
+		if _, ok := candidates[0].(string); !ok {
+			candidates = append(candidates, raw)
+		}
+		for _, value := range candidates {
+			var document interface{} = value
+			for i := len(condition.Path) - 1; i >= 0; i-- {
+				document = map[string]interface{}{condition.Path[i]: document}
 			}
+			documentJSON, _ := json.Marshal(document)
+			matches = append(matches, "t.custom_fields @> "+args.add(string(documentJSON))+"::jsonb")
 		}
+	}
+	match := "(" + strings.Join(matches, " OR ") + ")"
 
-		tasks = append(tasks, task)
+	if condition.Operator == "!=" {
+		return "NOT COALESCE(" + match + ", FALSE)"
 	}
+	return match
+}
 
-	if err := rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("rows error: %w", err)
+// listTasks gets a page of the tasks matching the conditions, sorted as
+// requested, along with the number of matching tasks
+func (r *PostgresTaskRepository) listTasks(ctx context.Context, conditions []string, args queryArgs, sort string, limit, offset int) ([]*models.Task, int, error) {
+	orderBy, err := taskOrderBy(sort)
+	if err != nil {
+		return nil, 0, err
+	}
+	where := strings.Join(conditions, " AND ")
+
+	// Get total count
+	exec := r.getExecer()
+	var total int
+	if err := exec.QueryRowContext(ctx, `SELECT COUNT(*) FROM tasks t WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to get task count: %w", err)
+	}
+
+	query := `SELECT ` + taskColumns + ` FROM tasks t WHERE ` + where +
+		` ORDER BY ` + orderBy + ` LIMIT ` + args.add(limit) + ` OFFSET ` + args.add(offset)
+
+	tasks, err := r.queryTasks(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
 	}
 
 	return tasks, total, nil
 }
 
-// Update updates a task
+// List gets the non-deleted tasks of a project matching the filter, with
+// pagination. The total counts every matching task.
+func (r *PostgresTaskRepository) List(ctx context.Context, projectID int, filter models.TaskFilter, limit, offset int) ([]*models.Task, int, error) {
+	var args queryArgs
+	conditions := append(taskFilterConditions(filter, &args), "t.project_id = "+args.add(projectID))
+
+	return r.listTasks(ctx, conditions, args, filter.Sort, limit, offset)
+}
+
+// ListAssigned gets the non-deleted tasks assigned to a user across all
+// non-deleted projects matching the filter, with pagination. With
+// memberOnly set, only projects the user owns or belongs to are included.
+func (r *PostgresTaskRepository) ListAssigned(ctx context.Context, userID int, memberOnly bool, filter models.TaskFilter, limit, offset int) ([]*models.Task, int, error) {
+	var args queryArgs
+	conditions := taskFilterConditions(filter, &args)
+
+	user := args.add(userID)
+	conditions = append(conditions, "t.assignee_id = "+user)
+	projects := "SELECT id FROM projects WHERE deleted_at IS NULL"
+	if memberOnly {
+		projects += " AND (owner_id = " + user + " OR id IN (SELECT project_id FROM project_members WHERE user_id = " + user + "))"
+	}
+	conditions = append(conditions, "t.project_id IN ("+projects+")")
+
+	return r.listTasks(ctx, conditions, args, filter.Sort, limit, offset)
+}
+
+// GetByCustomField gets the tasks of a project, including deleted ones,
+// that have a value for a custom field
+func (r *PostgresTaskRepository) GetByCustomField(ctx context.Context, projectID int, name string) ([]*models.Task, error) {
+	query := `
+		SELECT ` + taskColumns + `
+		FROM tasks
+		WHERE project_id = $1 AND custom_fields ? $2
+		ORDER BY id`
+
+	return r.queryTasks(ctx, query, projectID, name)
+}
+
+// GetChildren gets the direct subtasks of a task (only non-deleted)
+func (r *PostgresTaskRepository) GetChildren(ctx context.Context, parentID int) ([]*models.Task, error) {
+	query := `
+		SELECT ` + taskColumns + `
+		FROM tasks
+		WHERE parent_id = $1 AND deleted_at IS NULL
+		ORDER BY created_at, id`
+
+	return r.queryTasks(ctx, query, parentID)
+}
+
+// GetSubtree gets a task followed by all of its non-deleted descendants,
+// ordered by level. Descendants below a deleted task are not included.
+func (r *PostgresTaskRepository) GetSubtree(ctx context.Context, id int) ([]*models.Task, error) {
+	query := `
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM tasks WHERE id = $1 AND deleted_at IS NULL
+			UNION ALL
+			SELECT t.id FROM tasks t
+			JOIN subtree s ON t.parent_id = s.id
+			WHERE t.deleted_at IS NULL
+		)
+		SELECT ` + taskColumns + `
+		FROM tasks
+		WHERE id IN (SELECT id FROM subtree)
+		ORDER BY level, created_at, id`
+
+	tasks, err := r.queryTasks(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("task not found")
+	}
+
+	return tasks, nil
+}
+
+// Update updates a task. The parent and level are changed with Move.
 func (r *PostgresTaskRepository) Update(ctx context.Context, task *models.Task) (*models.Task, error) {
 	customFieldsJSON, err := json.Marshal(task.CustomFields)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal custom fields: %w", err)
 	}
+	metadataJSON, err := json.Marshal(task.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
 
 	query := `
-		UPDATE tasks 
+		UPDATE tasks
 		SET title = $2, description = $3, assignee_id = $4, status = $5,
-		    due_date = $6, custom_fields = $7
+		    due_date = $6, custom_fields = $7, priority = $8, estimated_hours = $9,
+		    actual_hours = $10, progress = $11, tags = $12, metadata = $13, milestone_id = $14,
+		    sprint_id = $15
 		WHERE id = $1
-		RETURNING created_at`
+		RETURNING updated_at`
 
 	exec := r.getExecer()
 	row := exec.QueryRowContext(ctx, query,
 		task.ID, task.Title, task.Description, task.AssigneeID,
-		task.Status, task.DueDate, customFieldsJSON)
+		task.Status, task.DueDate, customFieldsJSON, task.Priority, task.EstimatedHours,
+		task.ActualHours, task.Progress, pq.Array(task.Tags), metadataJSON, task.MilestoneID, task.SprintID)
 
 	err = row.Scan(&task.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("task not found")
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to update task: %w", err)
 	}
@@ -187,9 +537,53 @@ func (r *PostgresTaskRepository) Update(ctx context.Context, task *models.Task)
 	return task, nil
 }
 
-// Delete soft deletes a task (sets deleted_at timestamp)
+// Move sets the parent of a task and shifts the level of its whole subtree,
+// including deleted descendants, so the task ends up at the given level
+func (r *PostgresTaskRepository) Move(ctx context.Context, id int, parentID *int, level int) error {
+	query := `
+		WITH RECURSIVE subtree AS (
+			SELECT id, 0 AS depth FROM tasks WHERE id = $1
+			UNION ALL
+			SELECT t.id, s.depth + 1 FROM tasks t
+			JOIN subtree s ON t.parent_id = s.id
+		)
+		UPDATE tasks
+		SET parent_id = CASE WHEN tasks.id = $1 THEN $2 ELSE tasks.parent_id END,
+		    level = $3 + subtree.depth
+		FROM subtree
+		WHERE tasks.id = subtree.id`
+
+	exec := r.getExecer()
+	result, err := exec.ExecContext(ctx, query, id, parentID, level)
+	if err != nil {
+		return fmt.Errorf("failed to move task: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("task not found")
+	}
+
+	return nil
+}
+
+// Delete soft deletes a task and its non-deleted descendants. They share the
+// same deleted_at timestamp so RestoreTask can bring the subtree back together.
 func (r *PostgresTaskRepository) Delete(ctx context.Context, id int) error {
-	query := `UPDATE tasks SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
+	query := `
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM tasks WHERE id = $1 AND deleted_at IS NULL
+			UNION ALL
+			SELECT t.id FROM tasks t
+			JOIN subtree s ON t.parent_id = s.id
+			WHERE t.deleted_at IS NULL
+		)
+		UPDATE tasks SET deleted_at = NOW()
+		WHERE id IN (SELECT id FROM subtree)`
 
 	exec := r.getExecer()
 	result, err := exec.ExecContext(ctx, query, id)
@@ -216,9 +610,10 @@ func (r *PostgresTaskRepository) BulkCreate(ctx context.Context, tasks []*models
 	}
 
 	query := `
-		INSERT INTO tasks (project_id, title, description, status, assignee_id, due_date, custom_fields)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, created_at`
+		INSERT INTO tasks (project_id, title, description, status, assignee_id, due_date, custom_fields, parent_id, level,
+		                   milestone_id, sprint_id, priority, estimated_hours, actual_hours, progress, tags, metadata)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		RETURNING id, created_at, updated_at`
 
 	exec := r.getExecer()
 
@@ -227,16 +622,21 @@ func (r *PostgresTaskRepository) BulkCreate(ctx context.Context, tasks []*models
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal custom fields for task %d: %w", i, err)
 		}
+		metadataJSON, err := json.Marshal(task.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal metadata for task %d: %w", i, err)
+		}
 
 		row := exec.QueryRowContext(ctx, query,
 			task.ProjectID, task.Title, task.Description, task.Status,
-			task.AssigneeID, task.DueDate, customFieldsJSON)
+			task.AssigneeID, task.DueDate, customFieldsJSON, task.ParentID, task.Level,
+			task.MilestoneID, task.SprintID, task.Priority, task.EstimatedHours, task.ActualHours, task.Progress,
+			pq.Array(task.Tags), metadataJSON)
 
-		err = row.Scan(&task.ID, &task.CreatedAt)
+		err = row.Scan(&task.ID, &task.CreatedAt, &task.UpdatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create task %d: %w", i, err)
 		}
-		task.UpdatedAt = task.CreatedAt
 	}
 
 	return tasks, nil
@@ -245,7 +645,7 @@ func (r *PostgresTaskRepository) BulkCreate(ctx context.Context, tasks []*models
 // UpdateStatus updates task status only
 func (r *PostgresTaskRepository) UpdateStatus(ctx context.Context, id int, status string) error {
 	query := `
-		UPDATE tasks 
+		UPDATE tasks
 		SET status = $2
 		WHERE id = $1`
 
@@ -267,6 +667,98 @@ func (r *PostgresTaskRepository) UpdateStatus(ctx context.Context, id int, statu
 	return nil
 }
 
+// CountByStatus counts the non-deleted tasks of a project per status
+func (r *PostgresTaskRepository) CountByStatus(ctx context.Context, projectID int) (map[string]int, error) {
+	query := `
+		SELECT status, COUNT(*)
+		FROM tasks
+		WHERE project_id = $1 AND deleted_at IS NULL
+		GROUP BY status`
+
+	exec := r.getExecer()
+	rows, err := exec.QueryContext(ctx, query, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tasks by status: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan status count: %w", err)
+		}
+		counts[status] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return counts, nil
+}
+
+// GetStatuses gets the distinct statuses used by the tasks of a project,
+// including deleted tasks so they can still be restored
+func (r *PostgresTaskRepository) GetStatuses(ctx context.Context, projectID int) ([]string, error) {
+	query := `SELECT DISTINCT status FROM tasks WHERE project_id = $1 ORDER BY status`
+
+	exec := r.getExecer()
+	rows, err := exec.QueryContext(ctx, query, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task statuses: %w", err)
+	}
+	defer rows.Close()
+
+	statuses := []string{}
+	for rows.Next() {
+		var status string
+		if err := rows.Scan(&status); err != nil {
+			return nil, fmt.Errorf("failed to scan task status: %w", err)
+		}
+		statuses = append(statuses, status)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return statuses, nil
+}
+
+// RemapStatus moves every task of a project, deleted or not, from one status
+// to another and returns the IDs of the moved tasks
+func (r *PostgresTaskRepository) RemapStatus(ctx context.Context, projectID int, from, to string) ([]int, error) {
+	query := `
+		UPDATE tasks
+		SET status = $3
+		WHERE project_id = $1 AND status = $2
+		RETURNING id`
+
+	exec := r.getExecer()
+	rows, err := exec.QueryContext(ctx, query, projectID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remap task status: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan task ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return ids, nil
+}
+
 // GetByStatus gets tasks by status with pagination
 func (r *PostgresTaskRepository) GetByStatus(ctx context.Context, status string, limit, offset int) ([]*models.Task, int, error) {
 	// Get total count
@@ -279,56 +771,17 @@ func (r *PostgresTaskRepository) GetByStatus(ctx context.Context, status string,
 		return nil, 0, fmt.Errorf("failed to get task count: %w", err)
 	}
 
-	// Get tasks with pagination (matching actual table structure)
+	// Get tasks with pagination
 	query := `
-		SELECT id, project_id, title, description, status, assignee_id, due_date, 
-		       custom_fields, created_at, created_at as updated_at
-		FROM tasks 
+		SELECT ` + taskColumns + `
+		FROM tasks
 		WHERE status = $1
 		ORDER BY created_at DESC
 		LIMIT $2 OFFSET $3`
 
-	rows, err := exec.QueryContext(ctx, query, status, limit, offset)
+	tasks, err := r.queryTasks(ctx, query, status, limit, offset)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to list tasks: %w", err)
-	}
-	defer rows.Close()
-
-	var tasks []*models.Task
-	for rows.Next() {
-		task := &models.Task{}
-		var customFieldsJSON []byte
-		var assigneeID sql.NullInt64
-		var dueDate sql.NullTime
-
-		err := rows.Scan(
-			&task.ID, &task.ProjectID, &task.Title, &task.Description,
-			&task.Status, &assigneeID, &dueDate, &customFieldsJSON,
-			&task.CreatedAt, &task.UpdatedAt, &task.DeletedAt,
-		)
-		if err != nil {
-			return nil, 0, fmt.Errorf("failed to scan task: %w", err)
-		}
-
-		if assigneeID.Valid {
-			intVal := int(assigneeID.Int64)
-			task.AssigneeID = &intVal
-		}
-		if dueDate.Valid {
-			task.DueDate = &dueDate.Time
-		}
-
-		if len(customFieldsJSON) > 0 {
-			if err := json.Unmarshal(customFieldsJSON, &task.CustomFields); err != nil {
-				return nil, 0, fmt.Errorf("failed to unmarshal custom fields: %w", err)
-			}
-		}
-
-		tasks = append(tasks, task)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("rows error: %w", err)
+		return nil, 0, err
 	}
 
 	return tasks, total, nil
@@ -0,0 +1,132 @@
+package database
+
+import (
+	"ai-project-backend/models"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// PostgresTaskUpdateRepository implements TaskUpdateRepository using PostgreSQL
+type PostgresTaskUpdateRepository struct {
+	db interface{}
+}
+
+// getExecer returns the appropriate execer (DB or Tx)
+func (r *PostgresTaskUpdateRepository) getExecer() execer {
+	if tx, ok := r.db.(*sql.Tx); ok {
+		return tx
+	}
+	return r.db.(*sql.DB)
+}
+
+// marshalValue encodes a recorded value as JSON, keeping nil as SQL NULL
+func marshalValue(value interface{}) ([]byte, error) {
+	if value == nil {
+		return nil, nil
+	}
+	return json.Marshal(value)
+}
+
+// Record stores task updates in order
+func (r *PostgresTaskUpdateRepository) Record(ctx context.Context, updates []*models.TaskUpdate) error {
+	query := `
+		INSERT INTO task_updates (task_id, update_type, old_value, new_value, notes, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`
+
+	exec := r.getExecer()
+
+	for _, update := range updates {
+		oldValue, err := marshalValue(update.OldValue)
+		if err != nil {
+			return fmt.Errorf("failed to marshal old value: %w", err)
+		}
+		newValue, err := marshalValue(update.NewValue)
+		if err != nil {
+			return fmt.Errorf("failed to marshal new value: %w", err)
+		}
+
+		row := exec.QueryRowContext(ctx, query,
+			update.TaskID, update.UpdateType, oldValue, newValue, update.Notes, update.UpdatedBy)
+		if err := row.Scan(&update.ID, &update.CreatedAt); err != nil {
+			return fmt.Errorf("failed to record task update: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ListByTask gets the update history of a task with pagination, newest first
+func (r *PostgresTaskUpdateRepository) ListByTask(ctx context.Context, taskID int, limit, offset int) ([]*models.TaskUpdate, int, error) {
+	// Get total count
+	countQuery := `SELECT COUNT(*) FROM task_updates WHERE task_id = $1`
+	exec := r.getExecer()
+	row := exec.QueryRowContext(ctx, countQuery, taskID)
+
+	var total int
+	if err := row.Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to get task update count: %w", err)
+	}
+
+	query := `
+		SELECT tu.id, tu.task_id, tu.update_type, tu.old_value, tu.new_value, tu.notes,
+		       tu.updated_by, u.username, tu.created_at
+		FROM task_updates tu
+		LEFT JOIN users u ON tu.updated_by = u.id
+		WHERE tu.task_id = $1
+		ORDER BY tu.created_at DESC, tu.id DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := exec.QueryContext(ctx, query, taskID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list task updates: %w", err)
+	}
+	defer rows.Close()
+
+	var updates []*models.TaskUpdate
+	for rows.Next() {
+		update := &models.TaskUpdate{}
+		var oldValue, newValue []byte
+		var notes, updatedByName sql.NullString
+		var updatedBy sql.NullInt64
+
+		err := rows.Scan(
+			&update.ID, &update.TaskID, &update.UpdateType, &oldValue, &newValue, &notes,
+			&updatedBy, &updatedByName, &update.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan task update: %w", err)
+		}
+
+		if len(oldValue) > 0 {
+			if err := json.Unmarshal(oldValue, &update.OldValue); err != nil {
+				return nil, 0, fmt.Errorf("failed to unmarshal old value: %w", err)
+			}
+		}
+		if len(newValue) > 0 {
+			if err := json.Unmarshal(newValue, &update.NewValue); err != nil {
+				return nil, 0, fmt.Errorf("failed to unmarshal new value: %w", err)
+			}
+		}
+		if notes.Valid {
+			update.Notes = &notes.String
+		}
+		if updatedBy.Valid {
+			intVal := int(updatedBy.Int64)
+			update.UpdatedBy = &intVal
+		}
+		if updatedByName.Valid {
+			update.UpdatedByName = &updatedByName.String
+		}
+
+		updates = append(updates, update)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows error: %w", err)
+	}
+
+	return updates, total, nil
+}
\ No newline at end of file
@@ -0,0 +1,181 @@
+package database
+
+import (
+	"ai-project-backend/models"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// PostgresTimelineRepository implements TimelineRepository using PostgreSQL
+type PostgresTimelineRepository struct {
+	db interface{}
+}
+
+// getExecer returns the appropriate execer (DB or Tx)
+func (r *PostgresTimelineRepository) getExecer() execer {
+	if tx, ok := r.db.(*sql.Tx); ok {
+		return tx
+	}
+	return r.db.(*sql.DB)
+}
+
+// timelineEvents merges task updates and task/project/milestone audit log
+// entries into one event stream. Task creation is only recorded in
+// task_updates, so the two sources do not overlap. A task completes when it
+// moves from a status outside the done category of its project's workflow
+// into one inside it; a milestone completes when its status changes to
+// completed.
+const timelineEvents = `
+	WITH events AS (
+		SELECT 'u' AS source, tu.id, tu.created_at AS occurred_at,
+		       CASE
+		           WHEN tu.update_type = 'created' THEN 'created'
+		           WHEN tu.update_type = 'status'
+		                AND task_status_category(t.project_id, tu.new_value #>> '{}') = 'done'
+		                AND task_status_category(t.project_id, tu.old_value #>> '{}') <> 'done' THEN 'completed'
+		           ELSE 'updated'
+		       END AS event_type,
+		       'task' AS entity_type, t.id AS entity_id, t.project_id, t.title,
+		       tu.update_type, tu.old_value, tu.new_value, tu.notes, tu.updated_by AS user_id
+		FROM task_updates tu
+		JOIN tasks t ON t.id = tu.task_id
+		UNION ALL
+		SELECT 'a', l.id, l.created_at,
+		       CASE
+		           WHEN l.action = 'CREATE' THEN 'created'
+		           WHEN l.action = 'UPDATE' AND l.entity_type = 'milestone'
+		                AND l.entity_data->>'status' = 'completed'
+		                AND l.entity_data->>'old_status' <> 'completed' THEN 'completed'
+		           WHEN l.action = 'UPDATE' THEN 'updated'
+		           WHEN l.action = 'DELETE' THEN 'deleted'
+		           ELSE 'restored'
+		       END,
+		       l.entity_type, l.entity_id,
+		       CASE
+		           WHEN l.entity_type = 'project' THEN l.entity_id
+		           ELSE COALESCE(t.project_id, (l.entity_data->>'project_id')::int)
+		       END,
+		       COALESCE(t.title, p.name, l.entity_data->>'title', l.entity_data->>'name', ''),
+		       NULL, NULL, NULL, NULL, l.user_id
+		FROM system_audit_log l
+		LEFT JOIN tasks t ON l.entity_type = 'task' AND t.id = l.entity_id
+		LEFT JOIN projects p ON l.entity_type = 'project' AND p.id = l.entity_id
+		WHERE l.entity_type IN ('task', 'project', 'milestone')
+		  AND l.action IN ('CREATE', 'UPDATE', 'DELETE', 'RESTORE')
+	)
+	SELECT e.source, e.id, e.occurred_at, e.event_type, e.entity_type, e.entity_id,
+	       e.project_id, e.title, e.update_type, e.old_value, e.new_value, e.notes,
+	       e.user_id, u.username
+	FROM events e
+	LEFT JOIN users u ON u.id = e.user_id`
+
+// List gets timeline events matching the filter, newest first. Events after
+// filter.After are returned, so a page's last event is the next page's cursor.
+func (r *PostgresTimelineRepository) List(ctx context.Context, filter models.TimelineFilter) ([]*models.TimelineEvent, error) {
+	var conditions []string
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.TaskID != nil {
+		conditions = append(conditions, "e.entity_type = 'task' AND e.entity_id = "+arg(*filter.TaskID))
+	}
+	if filter.ProjectID != nil {
+		conditions = append(conditions, "e.project_id = "+arg(*filter.ProjectID))
+	}
+	if filter.UserID != nil {
+		conditions = append(conditions, "e.user_id = "+arg(*filter.UserID))
+	}
+	if filter.MemberID != nil {
+		member := arg(*filter.MemberID)
+		conditions = append(conditions, fmt.Sprintf(
+			"e.project_id IN (SELECT id FROM projects WHERE owner_id = %s UNION SELECT project_id FROM project_members WHERE user_id = %s)",
+			member, member))
+	}
+	if len(filter.EventTypes) > 0 {
+		conditions = append(conditions, "e.event_type = ANY("+arg(pq.Array(filter.EventTypes))+")")
+	}
+	if filter.From != nil {
+		conditions = append(conditions, "e.occurred_at >= "+arg(*filter.From))
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "e.occurred_at < "+arg(*filter.To))
+	}
+	if filter.After != nil {
+		conditions = append(conditions, fmt.Sprintf("(e.occurred_at, e.source, e.id) < (%s, %s, %s)",
+			arg(filter.After.OccurredAt), arg(filter.After.Source), arg(filter.After.ID)))
+	}
+
+	query := timelineEvents
+	if len(conditions) > 0 {
+		query += "\n\tWHERE " + strings.Join(conditions, "\n\t  AND ")
+	}
+	query += "\n\tORDER BY e.occurred_at DESC, e.source DESC, e.id DESC\n\tLIMIT " + arg(filter.Limit)
+
+	exec := r.getExecer()
+	rows, err := exec.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list timeline events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*models.TimelineEvent
+	for rows.Next() {
+		event := &models.TimelineEvent{}
+		var projectID, userID sql.NullInt64
+		var updateType, notes, username sql.NullString
+		var oldValue, newValue []byte
+
+		err := rows.Scan(
+			&event.Source, &event.ID, &event.OccurredAt, &event.EventType,
+			&event.EntityType, &event.EntityID, &projectID, &event.Title,
+			&updateType, &oldValue, &newValue, &notes, &userID, &username,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan timeline event: %w", err)
+		}
+
+		if projectID.Valid {
+			intVal := int(projectID.Int64)
+			event.ProjectID = &intVal
+		}
+		if userID.Valid {
+			intVal := int(userID.Int64)
+			event.UserID = &intVal
+		}
+		if updateType.Valid {
+			event.UpdateType = &updateType.String
+		}
+		if notes.Valid {
+			event.Notes = &notes.String
+		}
+		if username.Valid {
+			event.Username = &username.String
+		}
+		if len(oldValue) > 0 {
+			if err := json.Unmarshal(oldValue, &event.OldValue); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal old value: %w", err)
+			}
+		}
+		if len(newValue) > 0 {
+			if err := json.Unmarshal(newValue, &event.NewValue); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal new value: %w", err)
+			}
+		}
+
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return events, nil
+}
\ No newline at end of file
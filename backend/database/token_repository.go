@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// PostgresTokenRepository implements TokenRepository using PostgreSQL
+type PostgresTokenRepository struct {
+	db interface{}
+}
+
+// getExecer returns the appropriate execer (DB or Tx)
+func (r *PostgresTokenRepository) getExecer() execer {
+	if tx, ok := r.db.(*sql.Tx); ok {
+		return tx
+	}
+	return r.db.(*sql.DB)
+}
+
+// RevokeToken adds a token ID to the revocation list until it expires
+func (r *PostgresTokenRepository) RevokeToken(ctx context.Context, tokenID string, userID int, expiresAt time.Time) error {
+	query := `
+		INSERT INTO revoked_tokens (jti, user_id, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (jti) DO NOTHING`
+
+	exec := r.getExecer()
+	_, err := exec.ExecContext(ctx, query, tokenID, userID, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+
+	return nil
+}
+
+// IsTokenRevoked checks whether a token ID is on the revocation list
+func (r *PostgresTokenRepository) IsTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE jti = $1)`
+
+	exec := r.getExecer()
+	row := exec.QueryRowContext(ctx, query, tokenID)
+
+	var revoked bool
+	if err := row.Scan(&revoked); err != nil {
+		return false, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+
+	return revoked, nil
+}
+
+// CleanupExpired removes revocation entries for tokens that have already expired
+func (r *PostgresTokenRepository) CleanupExpired(ctx context.Context) (int, error) {
+	query := `DELETE FROM revoked_tokens WHERE expires_at < NOW()`
+
+	exec := r.getExecer()
+	result, err := exec.ExecContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to cleanup revoked tokens: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
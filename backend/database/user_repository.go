@@ -4,7 +4,11 @@ import (
 	"ai-project-backend/models"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
 )
 
 // PostgresUserRepository implements UserRepository using PostgreSQL
@@ -19,6 +23,63 @@ type execer interface {
 	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
 }
 
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// userColumns lists the users columns read by scanUser, in order
+const userColumns = `id, username, email, email_verified_at, password_hash, role, auth_provider, external_id,
+	totp_secret, totp_enabled, totp_last_step, is_active, deactivated_at, created_at, updated_at`
+
+// scanUser scans a row selected with userColumns
+func scanUser(scanner rowScanner) (*models.User, error) {
+	user := &models.User{}
+	var email, externalID, totpSecret sql.NullString
+	var emailVerifiedAt, deactivatedAt sql.NullTime
+
+	err := scanner.Scan(
+		&user.ID, &user.Username, &email, &emailVerifiedAt, &user.PasswordHash, &user.Role,
+		&user.AuthProvider, &externalID,
+		&totpSecret, &user.TOTPEnabled, &user.TOTPLastStep,
+		&user.IsActive, &deactivatedAt,
+		&user.CreatedAt, &user.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if email.Valid {
+		user.Email = &email.String
+	}
+	if emailVerifiedAt.Valid {
+		user.EmailVerifiedAt = &emailVerifiedAt.Time
+	}
+	if externalID.Valid {
+		user.ExternalID = &externalID.String
+	}
+	if totpSecret.Valid {
+		user.TOTPSecret = &totpSecret.String
+	}
+	if deactivatedAt.Valid {
+		user.DeactivatedAt = &deactivatedAt.Time
+	}
+
+	return user, nil
+}
+
+// uniqueViolation maps a unique constraint error on users to a plain error
+func uniqueViolation(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		if strings.Contains(pqErr.Constraint, "email") {
+			return fmt.Errorf("email already exists")
+		}
+		return fmt.Errorf("username already exists")
+	}
+	return nil
+}
+
 // getExecer returns the appropriate execer (DB or Tx)
 func (r *PostgresUserRepository) getExecer() execer {
 	if tx, ok := r.db.(*sql.Tx); ok {
@@ -29,17 +90,24 @@ func (r *PostgresUserRepository) getExecer() execer {
 
 // Create creates a new user
 func (r *PostgresUserRepository) Create(ctx context.Context, user *models.User) (*models.User, error) {
+	if user.AuthProvider == "" {
+		user.AuthProvider = "local"
+	}
+
 	query := `
-		INSERT INTO users (username, password_hash, role)
-		VALUES ($1, $2, $3)
-		RETURNING id, created_at, updated_at`
+		INSERT INTO users (username, email, password_hash, role, auth_provider, external_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, is_active, created_at, updated_at`
 
 	exec := r.getExecer()
 	row := exec.QueryRowContext(ctx, query,
-		user.Username, user.PasswordHash, user.Role)
+		user.Username, user.Email, user.PasswordHash, user.Role, user.AuthProvider, user.ExternalID)
 
-	err := row.Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+	err := row.Scan(&user.ID, &user.IsActive, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
+		if uniqueErr := uniqueViolation(err); uniqueErr != nil {
+			return nil, uniqueErr
+		}
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
@@ -48,19 +116,10 @@ func (r *PostgresUserRepository) Create(ctx context.Context, user *models.User)
 
 // GetByID gets a user by ID
 func (r *PostgresUserRepository) GetByID(ctx context.Context, id int) (*models.User, error) {
-	query := `
-		SELECT id, username, password_hash, role, created_at, updated_at
-		FROM users WHERE id = $1`
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
 
 	exec := r.getExecer()
-	row := exec.QueryRowContext(ctx, query, id)
-
-	user := &models.User{}
-
-	err := row.Scan(
-		&user.ID, &user.Username, &user.PasswordHash,
-		&user.Role, &user.CreatedAt, &user.UpdatedAt,
-	)
+	user, err := scanUser(exec.QueryRowContext(ctx, query, id))
 
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("user not found")
@@ -74,19 +133,27 @@ func (r *PostgresUserRepository) GetByID(ctx context.Context, id int) (*models.U
 
 // GetByUsername gets a user by username
 func (r *PostgresUserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
-	query := `
-		SELECT id, username, password_hash, role, created_at, updated_at
-		FROM users WHERE username = $1`
+	query := `SELECT ` + userColumns + ` FROM users WHERE username = $1`
 
 	exec := r.getExecer()
-	row := exec.QueryRowContext(ctx, query, username)
+	user, err := scanUser(exec.QueryRowContext(ctx, query, username))
 
-	user := &models.User{}
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
 
-	err := row.Scan(
-		&user.ID, &user.Username, &user.PasswordHash,
-		&user.Role, &user.CreatedAt, &user.UpdatedAt,
-	)
+	return user, nil
+}
+
+// GetByEmail gets a user by email, ignoring case
+func (r *PostgresUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE LOWER(email) = LOWER($1)`
+
+	exec := r.getExecer()
+	user, err := scanUser(exec.QueryRowContext(ctx, query, email))
 
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("user not found")
@@ -98,10 +165,45 @@ func (r *PostgresUserRepository) GetByUsername(ctx context.Context, username str
 	return user, nil
 }
 
-// GetByEmail gets a user by email (placeholder - no email field in current schema)
-func (r *PostgresUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
-	// Since the current schema doesn't have email field, this is a placeholder
-	return nil, fmt.Errorf("email field not supported in current schema")
+// SetEmail changes a user's email address and marks it unverified.
+// A nil email removes the address.
+func (r *PostgresUserRepository) SetEmail(ctx context.Context, id int, email *string) error {
+	query := `
+		UPDATE users SET email = $2, email_verified_at = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1`
+
+	err := r.execUserUpdate(ctx, query, id, email)
+	if uniqueErr := uniqueViolation(err); uniqueErr != nil {
+		return uniqueErr
+	}
+	return err
+}
+
+// MarkEmailVerified marks the user's email verified, provided it is still the
+// address the verification was sent to
+func (r *PostgresUserRepository) MarkEmailVerified(ctx context.Context, id int, email string) error {
+	query := `
+		UPDATE users SET email_verified_at = NOW(), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND LOWER(email) = LOWER($2)`
+
+	return r.execUserUpdate(ctx, query, id, email)
+}
+
+// GetByExternalID gets a user by identity provider and the provider's subject
+func (r *PostgresUserRepository) GetByExternalID(ctx context.Context, provider, externalID string) (*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE auth_provider = $1 AND external_id = $2`
+
+	exec := r.getExecer()
+	user, err := scanUser(exec.QueryRowContext(ctx, query, provider, externalID))
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
 }
 
 // Update updates a user
@@ -117,14 +219,21 @@ func (r *PostgresUserRepository) Update(ctx context.Context, user *models.User)
 		user.ID, user.Username, user.PasswordHash, user.Role)
 
 	err := row.Scan(&user.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user not found")
+	}
 	if err != nil {
+		if uniqueErr := uniqueViolation(err); uniqueErr != nil {
+			return nil, uniqueErr
+		}
 		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
 
 	return user, nil
 }
 
-// Delete deletes a user
+// Delete permanently deletes a user. The API deactivates users with SetActive
+// instead so their tasks keep their assignee.
 func (r *PostgresUserRepository) Delete(ctx context.Context, id int) error {
 	query := `DELETE FROM users WHERE id = $1`
 
@@ -160,7 +269,7 @@ func (r *PostgresUserRepository) List(ctx context.Context, limit, offset int) ([
 
 	// Get users with pagination
 	query := `
-		SELECT id, username, password_hash, role, created_at, updated_at
+		SELECT ` + userColumns + `
 		FROM users 
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2`
@@ -173,12 +282,7 @@ func (r *PostgresUserRepository) List(ctx context.Context, limit, offset int) ([
 
 	var users []*models.User
 	for rows.Next() {
-		user := &models.User{}
-
-		err := rows.Scan(
-			&user.ID, &user.Username, &user.PasswordHash,
-			&user.Role, &user.CreatedAt, &user.UpdatedAt,
-		)
+		user, err := scanUser(rows)
 		if err != nil {
 			return nil, 0, fmt.Errorf("failed to scan user: %w", err)
 		}
@@ -191,4 +295,134 @@ func (r *PostgresUserRepository) List(ctx context.Context, limit, offset int) ([
 	}
 
 	return users, total, nil
-}
\ No newline at end of file
+}
+
+// execUserUpdate runs a single-user UPDATE and maps zero affected rows to "user not found"
+func (r *PostgresUserRepository) execUserUpdate(ctx context.Context, query string, args ...interface{}) error {
+	exec := r.getExecer()
+	result, err := exec.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
+
+// SetActive deactivates or reactivates a user
+func (r *PostgresUserRepository) SetActive(ctx context.Context, id int, active bool) error {
+	query := `
+		UPDATE users
+		SET is_active = $2,
+		    deactivated_at = CASE WHEN $2 THEN NULL ELSE COALESCE(deactivated_at, NOW()) END,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1`
+
+	return r.execUserUpdate(ctx, query, id, active)
+}
+
+// SetTOTPSecret stores a pending TOTP secret; it is not used for login until EnableTwoFactor
+func (r *PostgresUserRepository) SetTOTPSecret(ctx context.Context, userID int, secret string) error {
+	query := `
+		UPDATE users SET totp_secret = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND totp_enabled = FALSE`
+
+	return r.execUserUpdate(ctx, query, userID, secret)
+}
+
+// EnableTwoFactor turns on TOTP for a user with the given hashed recovery codes.
+// step is the time step of the code that confirmed enrollment.
+func (r *PostgresUserRepository) EnableTwoFactor(ctx context.Context, userID int, recoveryCodeHashes []string, step int64) error {
+	query := `
+		UPDATE users
+		SET totp_enabled = TRUE, totp_recovery_codes = $2, totp_last_step = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND totp_secret IS NOT NULL`
+
+	return r.execUserUpdate(ctx, query, userID, pq.Array(recoveryCodeHashes), step)
+}
+
+// DisableTwoFactor turns off TOTP and discards the secret and recovery codes
+func (r *PostgresUserRepository) DisableTwoFactor(ctx context.Context, userID int) error {
+	query := `
+		UPDATE users
+		SET totp_enabled = FALSE, totp_secret = NULL, totp_recovery_codes = '{}', totp_last_step = 0,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1`
+
+	return r.execUserUpdate(ctx, query, userID)
+}
+
+// SetRecoveryCodes replaces a user's hashed recovery codes
+func (r *PostgresUserRepository) SetRecoveryCodes(ctx context.Context, userID int, recoveryCodeHashes []string) error {
+	query := `UPDATE users SET totp_recovery_codes = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $1`
+
+	return r.execUserUpdate(ctx, query, userID, pq.Array(recoveryCodeHashes))
+}
+
+// CountRecoveryCodes returns how many unused recovery codes a user has left
+func (r *PostgresUserRepository) CountRecoveryCodes(ctx context.Context, userID int) (int, error) {
+	query := `SELECT cardinality(totp_recovery_codes) FROM users WHERE id = $1`
+
+	exec := r.getExecer()
+	row := exec.QueryRowContext(ctx, query, userID)
+
+	var count int
+	err := row.Scan(&count)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+
+	return count, nil
+}
+
+// ConsumeRecoveryCode removes a hashed recovery code, reporting whether it was present.
+// The check and removal are one statement so a code cannot be used twice.
+func (r *PostgresUserRepository) ConsumeRecoveryCode(ctx context.Context, userID int, recoveryCodeHash string) (bool, error) {
+	query := `
+		UPDATE users
+		SET totp_recovery_codes = array_remove(totp_recovery_codes, $2), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND totp_enabled = TRUE AND $2 = ANY(totp_recovery_codes)`
+
+	exec := r.getExecer()
+	result, err := exec.ExecContext(ctx, query, userID, recoveryCodeHash)
+	if err != nil {
+		return false, fmt.Errorf("failed to consume recovery code: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// RecordTOTPStep stores the time step of an accepted code. It reports false when
+// the step is not newer than the last accepted one, i.e. the code is a replay.
+func (r *PostgresUserRepository) RecordTOTPStep(ctx context.Context, userID int, step int64) (bool, error) {
+	query := `UPDATE users SET totp_last_step = $2 WHERE id = $1 AND totp_last_step < $2`
+
+	exec := r.getExecer()
+	result, err := exec.ExecContext(ctx, query, userID, step)
+	if err != nil {
+		return false, fmt.Errorf("failed to record TOTP step: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"ai-project-backend/models"
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// PostgresUserTokenRepository implements UserTokenRepository using PostgreSQL
+type PostgresUserTokenRepository struct {
+	db interface{}
+}
+
+// getExecer returns the appropriate execer (DB or Tx)
+func (r *PostgresUserTokenRepository) getExecer() execer {
+	if tx, ok := r.db.(*sql.Tx); ok {
+		return tx
+	}
+	return r.db.(*sql.DB)
+}
+
+// Create stores a new token
+func (r *PostgresUserTokenRepository) Create(ctx context.Context, token *models.UserToken) (*models.UserToken, error) {
+	query := `
+		INSERT INTO user_tokens (user_id, purpose, token_hash, email, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`
+
+	exec := r.getExecer()
+	row := exec.QueryRowContext(ctx, query,
+		token.UserID, token.Purpose, token.TokenHash, token.Email, token.ExpiresAt)
+
+	err := row.Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user token: %w", err)
+	}
+
+	return token, nil
+}
+
+// Consume marks an unused, unexpired token as used and returns it. Checking and
+// marking happen in one statement so a token cannot be used twice.
+func (r *PostgresUserTokenRepository) Consume(ctx context.Context, purpose, tokenHash string) (*models.UserToken, error) {
+	query := `
+		UPDATE user_tokens SET used_at = NOW()
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING id, user_id, purpose, token_hash, email, expires_at, used_at, created_at`
+
+	exec := r.getExecer()
+	row := exec.QueryRowContext(ctx, query, tokenHash, purpose)
+
+	token := &models.UserToken{}
+	var email sql.NullString
+
+	err := row.Scan(
+		&token.ID, &token.UserID, &token.Purpose, &token.TokenHash, &email,
+		&token.ExpiresAt, &token.UsedAt, &token.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user token not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume user token: %w", err)
+	}
+
+	if email.Valid {
+		token.Email = &email.String
+	}
+
+	return token, nil
+}
+
+// InvalidateForUser marks a user's outstanding tokens for a purpose as used,
+// so only the most recently sent link works
+func (r *PostgresUserTokenRepository) InvalidateForUser(ctx context.Context, userID int, purpose string) error {
+	query := `UPDATE user_tokens SET used_at = NOW() WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`
+
+	exec := r.getExecer()
+	_, err := exec.ExecContext(ctx, query, userID, purpose)
+	if err != nil {
+		return fmt.Errorf("failed to invalidate user tokens: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package database
+
+import (
+	"ai-project-backend/models"
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// PostgresWorkflowRepository implements WorkflowRepository using PostgreSQL
+type PostgresWorkflowRepository struct {
+	db interface{}
+}
+
+// getExecer returns the appropriate execer (DB or Tx)
+func (r *PostgresWorkflowRepository) getExecer() execer {
+	if tx, ok := r.db.(*sql.Tx); ok {
+		return tx
+	}
+	return r.db.(*sql.DB)
+}
+
+// Get gets the workflow of a project, or the default workflow when the
+// project has no statuses of its own
+func (r *PostgresWorkflowRepository) Get(ctx context.Context, projectID int) (*models.Workflow, error) {
+	statusQuery := `
+		SELECT key, name, category, position
+		FROM workflow_statuses
+		WHERE project_id = $1
+		ORDER BY position, key`
+
+	exec := r.getExecer()
+	rows, err := exec.QueryContext(ctx, statusQuery, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow statuses: %w", err)
+	}
+	defer rows.Close()
+
+	statuses := []*models.WorkflowStatus{}
+	for rows.Next() {
+		status := &models.WorkflowStatus{}
+		if err := rows.Scan(&status.Key, &status.Name, &status.Category, &status.Position); err != nil {
+			return nil, fmt.Errorf("failed to scan workflow status: %w", err)
+		}
+		statuses = append(statuses, status)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	if len(statuses) == 0 {
+		return models.DefaultWorkflow(projectID), nil
+	}
+
+	transitionQuery := `
+		SELECT from_status, to_status
+		FROM workflow_transitions
+		WHERE project_id = $1
+		ORDER BY from_status, to_status`
+
+	transitionRows, err := exec.QueryContext(ctx, transitionQuery, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow transitions: %w", err)
+	}
+	defer transitionRows.Close()
+
+	transitions := []*models.WorkflowTransition{}
+	for transitionRows.Next() {
+		transition := &models.WorkflowTransition{}
+		if err := transitionRows.Scan(&transition.From, &transition.To); err != nil {
+			return nil, fmt.Errorf("failed to scan workflow transition: %w", err)
+		}
+		transitions = append(transitions, transition)
+	}
+
+	if err := transitionRows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return &models.Workflow{
+		ProjectID:   projectID,
+		Custom:      true,
+		Statuses:    statuses,
+		Transitions: transitions,
+	}, nil
+}
+
+// Replace replaces the workflow of a project. A workflow that is not custom
+// removes the project's statuses so it falls back to the default workflow.
+// Callers should run it in a transaction.
+func (r *PostgresWorkflowRepository) Replace(ctx context.Context, workflow *models.Workflow) error {
+	exec := r.getExecer()
+
+	_, err := exec.ExecContext(ctx, `DELETE FROM workflow_statuses WHERE project_id = $1`, workflow.ProjectID)
+	if err != nil {
+		return fmt.Errorf("failed to delete workflow statuses: %w", err)
+	}
+
+	if !workflow.Custom {
+		return nil
+	}
+
+	statusQuery := `
+		INSERT INTO workflow_statuses (project_id, key, name, category, position)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	for _, status := range workflow.Statuses {
+		_, err := exec.ExecContext(ctx, statusQuery,
+			workflow.ProjectID, status.Key, status.Name, status.Category, status.Position)
+		if err != nil {
+			return fmt.Errorf("failed to create workflow status: %w", err)
+		}
+	}
+
+	transitionQuery := `
+		INSERT INTO workflow_transitions (project_id, from_status, to_status)
+		VALUES ($1, $2, $3)`
+
+	for _, transition := range workflow.Transitions {
+		_, err := exec.ExecContext(ctx, transitionQuery, workflow.ProjectID, transition.From, transition.To)
+		if err != nil {
+			return fmt.Errorf("failed to create workflow transition: %w", err)
+		}
+	}
+
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"ai-project-backend/models"
+	"ai-project-backend/policy"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// validDependencyTypes lists the types allowed by chk_task_dependencies_type
+var validDependencyTypes = map[string]bool{
+	models.DependencyFinishToStart: true,
+	models.DependencyStartToStart:  true,
+}
+
+// openBlockers returns the dependencies that still hold a task: finish-to-
+// start blockers that are not done and start-to-start blockers that have
+// not started
+func openBlockers(workflow *models.Workflow, blockedBy []*models.DependencyLink) []*models.DependencyLink {
+	open := []*models.DependencyLink{}
+	for _, link := range blockedBy {
+		category := workflow.Category(link.Status)
+		switch link.Type {
+		case models.DependencyStartToStart:
+			if category == models.StatusCategoryDoing || category == models.StatusCategoryDone {
+				continue
+			}
+		default:
+			if category == models.StatusCategoryDone {
+				continue
+			}
+		}
+		open = append(open, link)
+	}
+	return open
+}
+
+// checkBlockers checks the dependencies of a task that is about to leave the
+// todo category for status. Open blockers are returned as warnings, unless
+// the project enforces dependencies strictly; then the conflict response is
+// written and false returned.
+func (app *Application) checkBlockers(c *gin.Context, workflow *models.Workflow, task *models.Task, status string) ([]string, bool) {
+	if workflow.Category(task.Status) != models.StatusCategoryTodo || workflow.Category(status) == models.StatusCategoryTodo {
+		return nil, true
+	}
+
+	ctx := c.Request.Context()
+	dependencies, err := app.db.Dependencies().ListByTask(ctx, task.ID)
+	if err != nil {
+		app.logger.Printf("Error getting task dependencies: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to update task", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return nil, false
+	}
+	open := openBlockers(workflow, dependencies.BlockedBy)
+	if len(open) == 0 {
+		return nil, true
+	}
+
+	project, err := app.db.Projects().GetByID(ctx, task.ProjectID)
+	if err != nil {
+		app.logger.Printf("Error getting project: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to update task", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return nil, false
+	}
+	if project.StrictDependencies {
+		response := models.NewErrorResponse(models.ErrCodeConflict, "Task is blocked by unfinished tasks", open)
+		c.JSON(http.StatusConflict, response)
+		return nil, false
+	}
+
+	warnings := make([]string, len(open))
+	for i, link := range open {
+		state := "finished"
+		if link.Type == models.DependencyStartToStart {
+			state = "started"
+		}
+		warnings[i] = fmt.Sprintf("Task is blocked by task %d (%s), which has not %s", link.TaskID, link.Title, state)
+	}
+	return warnings, true
+}
+
+func (app *Application) getTaskDependenciesHandler(c *gin.Context) {
+	task, ok := app.loadTaskParam(c, policy.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+
+	dependencies, err := app.db.Dependencies().ListByTask(c.Request.Context(), task.ID)
+	if err != nil {
+		app.logger.Printf("Error getting task dependencies: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to retrieve task dependencies", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := models.NewSuccessResponse(dependencies, "Task dependencies retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) addTaskDependencyHandler(c *gin.Context) {
+	task, ok := app.loadTaskParam(c, policy.ProjectRoleMember)
+	if !ok {
+		return
+	}
+
+	var req models.TaskDependencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid request body", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validate required fields
+	if req.DependsOnID == 0 {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Depends on ID is required", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	if req.Type == "" {
+		req.Type = models.DependencyFinishToStart
+	}
+	if !validDependencyTypes[req.Type] {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Type must be one of FS, SS", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	if req.DependsOnID == task.ID {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "A task cannot depend on itself", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	ctx := c.Request.Context()
+	blocker, err := app.db.Tasks().GetByID(ctx, req.DependsOnID)
+	if err == nil && blocker.ProjectID != task.ProjectID {
+		err = fmt.Errorf("task not found")
+	}
+	if err != nil {
+		if err.Error() == "task not found" {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, "Blocking task not found", nil)
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+		app.logger.Printf("Error getting task: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to add task dependency", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	tx, err := app.db.BeginTx(ctx)
+	if err != nil {
+		app.logger.Printf("Error starting transaction: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to add task dependency", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	defer tx.Rollback()
+
+	// The blocker must not already wait for the task. The lock keeps a
+	// concurrent request from adding the reverse link after this check.
+	if err := tx.Dependencies().LockProject(ctx, task.ProjectID); err != nil {
+		app.logger.Printf("Error locking project dependencies: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to add task dependency", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	cycle, err := tx.Dependencies().DependsOn(ctx, blocker.ID, task.ID)
+	if err != nil {
+		app.logger.Printf("Error checking dependency chain: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to add task dependency", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	if cycle {
+		response := models.NewErrorResponse(models.ErrCodeConflict, "Dependency would create a cycle", nil)
+		c.JSON(http.StatusConflict, response)
+		return
+	}
+
+	claims := currentUser(c)
+	dependency, err := tx.Dependencies().Add(ctx, &models.TaskDependency{
+		TaskID:      task.ID,
+		DependsOnID: blocker.ID,
+		Type:        req.Type,
+		CreatedBy:   &claims.UserID,
+	})
+	if err != nil {
+		if err.Error() == "dependency already exists" {
+			response := models.NewErrorResponse(models.ErrCodeConflict, "Task already depends on this task", nil)
+			c.JSON(http.StatusConflict, response)
+			return
+		}
+		app.logger.Printf("Error adding task dependency: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to add task dependency", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		app.logger.Printf("Error committing transaction: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to add task dependency", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	entityData := map[string]interface{}{"project_id": task.ProjectID, "task_id": task.ID, "depends_on_id": blocker.ID, "type": dependency.Type}
+	if err := app.db.System().LogAction(ctx, &claims.UserID, "CREATE", "task_dependency", dependency.ID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
+	response := models.NewSuccessResponse(dependency, "Task dependency added successfully")
+	c.JSON(http.StatusCreated, response)
+}
+
+func (app *Application) removeTaskDependencyHandler(c *gin.Context) {
+	task, ok := app.loadTaskParam(c, policy.ProjectRoleMember)
+	if !ok {
+		return
+	}
+
+	dependsOnID, err := strconv.Atoi(c.Param("dependsOnId"))
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid blocking task ID", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	id, err := app.db.Dependencies().Remove(c.Request.Context(), task.ID, dependsOnID)
+	if err != nil {
+		if err.Error() == "dependency not found" {
+			response := models.NewErrorResponse(models.ErrCodeNotFound, "Task dependency not found", nil)
+			c.JSON(http.StatusNotFound, response)
+			return
+		}
+		app.logger.Printf("Error removing task dependency: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to remove task dependency", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	claims := currentUser(c)
+	entityData := map[string]interface{}{"project_id": task.ProjectID, "task_id": task.ID, "depends_on_id": dependsOnID}
+	if err := app.db.System().LogAction(c.Request.Context(), &claims.UserID, "DELETE", "task_dependency", id, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
+	response := models.NewSuccessResponse(nil, "Task dependency removed successfully")
+	c.JSON(http.StatusOK, response)
+}
\ No newline at end of file
@@ -0,0 +1,378 @@
+package main
+
+import (
+	"ai-project-backend/config"
+	"ai-project-backend/mail"
+	"ai-project-backend/models"
+	"ai-project-backend/utils"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Purposes of emailed user tokens
+const (
+	tokenPurposeEmailVerification = "email_verification"
+	tokenPurposePasswordReset     = "password_reset"
+)
+
+// newMailSender creates the mail sender selected by the configuration
+func newMailSender(cfg config.MailConfig, logger *log.Logger) mail.Sender {
+	switch cfg.Driver {
+	case "smtp":
+		return mail.NewSMTPSender(mail.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.From,
+		})
+	case "file":
+		return mail.NewFileSender(cfg.FilePath, cfg.From)
+	default:
+		return mail.NewLogSender(logger)
+	}
+}
+
+// issueUserToken creates a single-use token for the user, invalidating earlier
+// tokens for the same purpose, and returns the plain token
+func (app *Application) issueUserToken(c *gin.Context, userID int, purpose string, email *string, ttl time.Duration) (string, error) {
+	if err := app.db.UserTokens().InvalidateForUser(c.Request.Context(), userID, purpose); err != nil {
+		return "", err
+	}
+
+	plainToken, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	token := &models.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: utils.HashToken(plainToken),
+		Email:     email,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if _, err := app.db.UserTokens().Create(c.Request.Context(), token); err != nil {
+		return "", err
+	}
+
+	return plainToken, nil
+}
+
+// mailLink builds a frontend link carrying a token
+func (app *Application) mailLink(path, token string) string {
+	return strings.TrimSuffix(app.config.Mail.LinkBaseURL, "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+// sendVerificationEmail emails a link that confirms the user owns the address
+func (app *Application) sendVerificationEmail(c *gin.Context, user *models.User, email string) error {
+	token, err := app.issueUserToken(c, user.ID, tokenPurposeEmailVerification, &email, app.config.Mail.VerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf(
+		"Hello %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\nThe link expires in %s. If you did not request this, you can ignore this email.\n",
+		user.Username, app.mailLink("/verify-email", token), app.config.Mail.VerificationTTL,
+	)
+
+	return app.mailer.Send(c.Request.Context(), mail.Message{
+		To:      email,
+		Subject: app.config.App.Name + ": confirm your email address",
+		Body:    body,
+	})
+}
+
+// sendPasswordResetEmail emails a single-use password reset link to the user's verified address
+func (app *Application) sendPasswordResetEmail(c *gin.Context, user *models.User) error {
+	token, err := app.issueUserToken(c, user.ID, tokenPurposePasswordReset, nil, app.config.Mail.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf(
+		"Hello %s,\n\nA password reset was requested for your account. Open the link below to choose a new password:\n\n%s\n\nThe link can be used once and expires in %s. If you did not request this, you can ignore this email.\n",
+		user.Username, app.mailLink("/reset-password", token), app.config.Mail.PasswordResetTTL,
+	)
+
+	return app.mailer.Send(c.Request.Context(), mail.Message{
+		To:      *user.Email,
+		Subject: app.config.App.Name + ": reset your password",
+		Body:    body,
+	})
+}
+
+// changeUserEmail stores a new, unverified email address and sends the verification
+// link. An empty email removes the address. It writes the error response and
+// returns false on failure.
+func (app *Application) changeUserEmail(c *gin.Context, user *models.User, email string) bool {
+	var newEmail *string
+	if email != "" {
+		newEmail = &email
+	}
+
+	if err := app.db.Users().SetEmail(c.Request.Context(), user.ID, newEmail); err != nil {
+		if err.Error() == "email already exists" {
+			response := models.NewErrorResponse(models.ErrCodeConflict, "Email address is already in use", nil)
+			c.JSON(http.StatusConflict, response)
+			return false
+		}
+		app.logger.Printf("Error updating email: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to update email", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return false
+	}
+	user.Email = newEmail
+	user.EmailVerifiedAt = nil
+
+	if newEmail != nil {
+		if err := app.sendVerificationEmail(c, user, email); err != nil {
+			app.logger.Printf("Error sending verification email: %v", err)
+		}
+	}
+
+	return true
+}
+
+func (app *Application) setEmailHandler(c *gin.Context) {
+	user, ok := app.loadCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.EmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid request body", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validate required fields
+	req.Email = strings.TrimSpace(req.Email)
+	if !mail.ValidAddress(req.Email) {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "A valid email address is required", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if user.Email != nil && strings.EqualFold(*user.Email, req.Email) && user.EmailVerifiedAt != nil {
+		response := models.NewSuccessResponse(user.ToResponse(), "Email address unchanged")
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	if !app.changeUserEmail(c, user, req.Email) {
+		return
+	}
+
+	entityData := map[string]interface{}{"email": req.Email}
+	if err := app.db.System().LogAction(c.Request.Context(), &user.ID, "UPDATE", "user", user.ID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
+	response := models.NewSuccessResponse(user.ToResponse(), "Email updated; check your inbox to verify it")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) resendVerificationHandler(c *gin.Context) {
+	user, ok := app.loadCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	if user.Email == nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "No email address is set", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	if user.EmailVerifiedAt != nil {
+		response := models.NewErrorResponse(models.ErrCodeConflict, "Email address is already verified", nil)
+		c.JSON(http.StatusConflict, response)
+		return
+	}
+
+	if err := app.sendVerificationEmail(c, user, *user.Email); err != nil {
+		app.logger.Printf("Error sending verification email: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to send verification email", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := models.NewSuccessResponse(nil, "Verification email sent")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) verifyEmailHandler(c *gin.Context) {
+	var req models.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Token == "" {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Token is required", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	token, err := app.db.UserTokens().Consume(c.Request.Context(), tokenPurposeEmailVerification, utils.HashToken(req.Token))
+	if err != nil {
+		if err.Error() == "user token not found" {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid or expired token", nil)
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+		app.logger.Printf("Error consuming user token: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to verify email", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	// The address may have changed since the link was sent
+	if token.Email == nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid or expired token", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	if err := app.db.Users().MarkEmailVerified(c.Request.Context(), token.UserID, *token.Email); err != nil {
+		if err.Error() == "user not found" {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid or expired token", nil)
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+		app.logger.Printf("Error verifying email: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to verify email", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	entityData := map[string]interface{}{"email_verified": *token.Email}
+	if err := app.db.System().LogAction(c.Request.Context(), &token.UserID, "UPDATE", "user", token.UserID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
+	response := models.NewSuccessResponse(nil, "Email verified successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) forgotPasswordHandler(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid request body", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validate required fields
+	req.Email = strings.TrimSpace(req.Email)
+	if !mail.ValidAddress(req.Email) {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "A valid email address is required", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Same response whether or not the address is known to avoid user enumeration
+	response := models.NewSuccessResponse(nil, "If the address belongs to an account, a password reset link has been sent")
+
+	user, err := app.db.Users().GetByEmail(c.Request.Context(), req.Email)
+	if err != nil {
+		if err.Error() != "user not found" {
+			app.logger.Printf("Error getting user: %v", err)
+		}
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	// Only verified addresses of active local accounts can receive reset links
+	if !user.IsActive || user.AuthProvider != "local" || user.EmailVerifiedAt == nil {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	if err := app.sendPasswordResetEmail(c, user); err != nil {
+		app.logger.Printf("Error sending password reset email: %v", err)
+	}
+
+	entityData := map[string]interface{}{"password_reset_requested": true}
+	if err := app.db.System().LogAction(c.Request.Context(), nil, "UPDATE", "user", user.ID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) resetPasswordHandler(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid request body", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validate before consuming the token so a rejected password does not burn it
+	if req.Token == "" {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Token is required", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	if msg := validatePassword(req.Password); msg != "" {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, msg, nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	token, err := app.db.UserTokens().Consume(c.Request.Context(), tokenPurposePasswordReset, utils.HashToken(req.Token))
+	if err != nil {
+		if err.Error() == "user token not found" {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid or expired token", nil)
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+		app.logger.Printf("Error consuming user token: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to reset password", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	user, err := app.db.Users().GetByID(c.Request.Context(), token.UserID)
+	if err != nil {
+		app.logger.Printf("Error getting user: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid or expired token", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	if !user.IsActive || user.AuthProvider != "local" {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid or expired token", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	passwordHash, err := utils.HashPassword(req.Password)
+	if err != nil {
+		app.logger.Printf("Error hashing password: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to reset password", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	user.PasswordHash = passwordHash
+
+	if _, err := app.db.Users().Update(c.Request.Context(), user); err != nil {
+		app.writeUserSaveError(c, err, "Failed to reset password")
+		return
+	}
+
+	// Whoever knew the old password is signed out
+	if err := app.endUserSessions(c, user.ID); err != nil {
+		app.logger.Printf("Error revoking sessions: %v", err)
+	}
+	app.resetLoginFailures(c, user.Username)
+
+	entityData := map[string]interface{}{"password_reset": "email"}
+	if err := app.db.System().LogAction(c.Request.Context(), &user.ID, "UPDATE", "user", user.ID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
+	response := models.NewSuccessResponse(nil, "Password reset successfully")
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"ai-project-backend/database"
+	"ai-project-backend/models"
+	"ai-project-backend/policy"
+	"ai-project-backend/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// The fakes below implement the repositories used by handler tests in
+// memory. Each embeds its interface, so a method a test does not expect
+// panics on the nil interface and fails the test.
+
+// fakeDB is a database.DB backed by in-memory repositories
+type fakeDB struct {
+	database.DB
+	projects *fakeProjects
+	tasks    *fakeTasks
+	search   *fakeSearch
+	system   *fakeSystem
+	members  *fakeMembers
+	users    *fakeUsers
+	sessions *fakeSessions
+	timeline *fakeTimeline
+}
+
+func newFakeDB() *fakeDB {
+	return &fakeDB{
+		projects: &fakeProjects{},
+		tasks:    &fakeTasks{},
+		search:   &fakeSearch{},
+		system:   &fakeSystem{settings: make(map[string][]byte)},
+		members:  &fakeMembers{roles: make(map[[2]int]string)},
+		users:    &fakeUsers{users: make(map[int]*models.User)},
+		sessions: &fakeSessions{},
+		timeline: &fakeTimeline{},
+	}
+}
+
+func (db *fakeDB) Projects() database.ProjectRepository  { return db.projects }
+func (db *fakeDB) Tasks() database.TaskRepository        { return db.tasks }
+func (db *fakeDB) Search() database.SearchRepository     { return db.search }
+func (db *fakeDB) System() database.SystemRepository     { return db.system }
+func (db *fakeDB) Members() database.MemberRepository    { return db.members }
+func (db *fakeDB) Users() database.UserRepository        { return db.users }
+func (db *fakeDB) Sessions() database.SessionRepository  { return db.sessions }
+func (db *fakeDB) Timeline() database.TimelineRepository { return db.timeline }
+
+// fakeProjects records whether projects were listed globally or per member
+type fakeProjects struct {
+	database.ProjectRepository
+	listedAll    bool
+	listedMember *int
+}
+
+func (r *fakeProjects) List(ctx context.Context, limit, offset int) ([]*models.Project, int, error) {
+	r.listedAll = true
+	return nil, 0, nil
+}
+
+func (r *fakeProjects) GetByUserID(ctx context.Context, userID int, limit, offset int) ([]*models.Project, int, error) {
+	r.listedMember = &userID
+	return nil, 0, nil
+}
+
+// fakeMembers holds project roles keyed by project and user ID
+type fakeMembers struct {
+	database.MemberRepository
+	roles map[[2]int]string
+}
+
+func (r *fakeMembers) GetRole(ctx context.Context, projectID, userID int) (string, error) {
+	return r.roles[[2]int{projectID, userID}], nil
+}
+
+// fakeUsers holds users keyed by ID. It returns copies so handlers cannot
+// change stored users without calling Update.
+type fakeUsers struct {
+	database.UserRepository
+	users map[int]*models.User
+}
+
+// add stores a user with the next free ID and returns the ID
+func (r *fakeUsers) add(user *models.User) int {
+	user.ID = len(r.users) + 1
+	stored := *user
+	r.users[user.ID] = &stored
+	return user.ID
+}
+
+// find returns a copy of the first user matching the predicate
+func (r *fakeUsers) find(match func(user *models.User) bool) (*models.User, error) {
+	for id := 1; id <= len(r.users); id++ {
+		if user := r.users[id]; match(user) {
+			found := *user
+			return &found, nil
+		}
+	}
+	return nil, fmt.Errorf("user not found")
+}
+
+func (r *fakeUsers) GetByID(ctx context.Context, id int) (*models.User, error) {
+	return r.find(func(user *models.User) bool { return user.ID == id })
+}
+
+func (r *fakeUsers) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	return r.find(func(user *models.User) bool { return user.Username == username })
+}
+
+func (r *fakeUsers) GetByExternalID(ctx context.Context, provider, externalID string) (*models.User, error) {
+	return r.find(func(user *models.User) bool {
+		return user.AuthProvider == provider && user.ExternalID != nil && *user.ExternalID == externalID
+	})
+}
+
+func (r *fakeUsers) Create(ctx context.Context, user *models.User) (*models.User, error) {
+	if _, err := r.GetByUsername(ctx, user.Username); err == nil {
+		return nil, fmt.Errorf("username already exists")
+	}
+	user.IsActive = true
+	r.add(user)
+	return user, nil
+}
+
+func (r *fakeUsers) Update(ctx context.Context, user *models.User) (*models.User, error) {
+	if _, ok := r.users[user.ID]; !ok {
+		return nil, fmt.Errorf("user not found")
+	}
+	stored := *user
+	r.users[user.ID] = &stored
+	return user, nil
+}
+
+// fakeSessions collects created refresh-token sessions
+type fakeSessions struct {
+	database.SessionRepository
+	created []*models.AuthSession
+}
+
+func (r *fakeSessions) Create(ctx context.Context, session *models.AuthSession) (*models.AuthSession, error) {
+	session.ID = len(r.created) + 1
+	r.created = append(r.created, session)
+	return session, nil
+}
+
+// fakeTasks records how assigned tasks were listed
+type fakeTasks struct {
+	database.TaskRepository
+	assignedMemberOnly *bool
+}
+
+func (r *fakeTasks) ListAssigned(ctx context.Context, userID int, memberOnly bool, filter models.TaskFilter, limit, offset int) ([]*models.Task, int, error) {
+	r.assignedMemberOnly = &memberOnly
+	return nil, 0, nil
+}
+
+// fakeSearch records the last search filter
+type fakeSearch struct {
+	database.SearchRepository
+	filter *models.SearchFilter
+}
+
+func (r *fakeSearch) Search(ctx context.Context, filter models.SearchFilter) (*models.SearchResult, error) {
+	r.filter = &filter
+	return &models.SearchResult{}, nil
+}
+
+// fakeTimeline records the last timeline filter
+type fakeTimeline struct {
+	database.TimelineRepository
+	filter *models.TimelineFilter
+}
+
+func (r *fakeTimeline) List(ctx context.Context, filter models.TimelineFilter) ([]*models.TimelineEvent, error) {
+	r.filter = &filter
+	return nil, nil
+}
+
+// fakeSystem stores settings as JSON and collects audit entries
+type fakeSystem struct {
+	database.SystemRepository
+	settings map[string][]byte
+	audit    []*models.AuditLog
+}
+
+func (r *fakeSystem) GetSetting(ctx context.Context, key string, value interface{}) error {
+	data, ok := r.settings[key]
+	if !ok {
+		return fmt.Errorf("setting not found")
+	}
+	return json.Unmarshal(data, value)
+}
+
+func (r *fakeSystem) SetSetting(ctx context.Context, key string, value interface{}, updatedBy *int) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	r.settings[key] = data
+	return nil
+}
+
+func (r *fakeSystem) LogAction(ctx context.Context, userID *int, action, entityType string, entityID int, entityData interface{}, ipAddress, userAgent string) error {
+	r.audit = append(r.audit, &models.AuditLog{
+		UserID:     userID,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+	})
+	return nil
+}
+
+// newTestApplication creates an Application on a fake database
+func newTestApplication(db database.DB) *Application {
+	gin.SetMode(gin.TestMode)
+	return &Application{
+		db:         db,
+		logger:     log.New(io.Discard, "", 0),
+		jwtManager: utils.NewJWTManager("test-secret", 0),
+		policy:     policy.DefaultPolicy(),
+	}
+}
+
+// serve runs a single request against handler as the given caller
+func serve(t *testing.T, claims *utils.JWTClaims, method, target string, body io.Reader, handler gin.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+	return serveRoute(t, claims, method, "/*path", target, body, handler)
+}
+
+// serveRoute is serve with handler registered on a route with parameters
+func serveRoute(t *testing.T, claims *utils.JWTClaims, method, route, target string, body io.Reader, handler gin.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+
+	router := gin.New()
+	router.Handle(method, route, func(c *gin.Context) {
+		if claims != nil {
+			c.Set(contextKeyClaims, claims)
+		}
+		c.Next()
+	}, handler)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(method, target, body))
+	return recorder
+}
+
+// requireStatus fails the test when a response has an unexpected status
+func requireStatus(t *testing.T, recorder *httptest.ResponseRecorder, want int) {
+	t.Helper()
+
+	if recorder.Code != want {
+		t.Fatalf("status = %d, want %d: %s", recorder.Code, want, recorder.Body.String())
+	}
+}
\ No newline at end of file
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"ai-project-backend/models"
+	"ai-project-backend/policy"
+	"ai-project-backend/schedule"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// treeOrder lists tasks depth first, each parent followed by its subtasks in
+// the order given. Tasks whose parent is missing are treated as roots. It
+// also returns the depth of each task in the listed tree.
+func treeOrder(tasks []*models.Task) ([]*models.Task, map[int]int) {
+	byID := make(map[int]bool, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = true
+	}
+	children := make(map[int][]*models.Task)
+	var roots []*models.Task
+	for _, task := range tasks {
+		if task.ParentID != nil && byID[*task.ParentID] {
+			children[*task.ParentID] = append(children[*task.ParentID], task)
+		} else {
+			roots = append(roots, task)
+		}
+	}
+
+	ordered := make([]*models.Task, 0, len(tasks))
+	depth := make(map[int]int, len(tasks))
+	var visit func(task *models.Task, level int)
+	visit = func(task *models.Task, level int) {
+		ordered = append(ordered, task)
+		depth[task.ID] = level
+		for _, child := range children[task.ID] {
+			visit(child, level+1)
+		}
+	}
+	for _, root := range roots {
+		visit(root, 0)
+	}
+	return ordered, depth
+}
+
+// isMilestone reports whether a task is planned as a milestone, i.e. with
+// zero estimated hours
+func isMilestone(task *models.Task) bool {
+	return task.EstimatedHours != nil && *task.EstimatedHours == 0
+}
+
+// buildGanttChart lays out a project plan as Gantt bars, links and
+// milestones. Project milestones come first, then task milestones.
+func buildGanttChart(projectID int, plan *projectPlan, milestones []*models.Milestone) *models.GanttChart {
+	scheduled := make(map[int]*schedule.TaskSchedule, len(plan.schedule.Tasks))
+	for _, result := range plan.schedule.Tasks {
+		scheduled[result.TaskID] = result
+	}
+
+	chart := &models.GanttChart{
+		ProjectID:  projectID,
+		Start:      plan.schedule.Start,
+		Finish:     plan.schedule.Finish,
+		Bars:       []*models.GanttBar{},
+		Links:      []*models.GanttLink{},
+		Milestones: []*models.GanttMilestone{},
+	}
+
+	for _, milestone := range milestones {
+		entry := &models.GanttMilestone{
+			MilestoneID: &milestone.ID,
+			Title:       milestone.Name,
+			Date:        milestone.TargetDate,
+			Done:        milestone.Status != models.MilestoneOpen,
+		}
+		if milestone.Progress != nil {
+			entry.Progress = milestone.Progress.Percent
+		}
+		chart.Milestones = append(chart.Milestones, entry)
+	}
+
+	ordered, depth := treeOrder(plan.tasks)
+	for _, task := range ordered {
+		result := scheduled[task.ID]
+		bar := &models.GanttBar{
+			ID:                task.ID,
+			ParentID:          task.ParentID,
+			Level:             depth[task.ID],
+			Title:             task.Title,
+			Type:              models.GanttBarTask,
+			Status:            task.Status,
+			Category:          plan.workflow.Category(task.Status),
+			AssigneeID:        task.AssigneeID,
+			MilestoneID:       task.MilestoneID,
+			Start:             result.EarliestStart,
+			End:               result.EarliestFinish,
+			DueDate:           task.DueDate,
+			Progress:          task.Progress,
+			Critical:          result.Critical,
+			SlackDays:         result.SlackDays,
+			DueDateInfeasible: result.DueDateInfeasible,
+		}
+		if depth[task.ID] == 0 {
+			bar.ParentID = nil
+		}
+		switch {
+		case result.Summary:
+			bar.Type = models.GanttBarSummary
+		case isMilestone(task):
+			bar.Type = models.GanttBarMilestone
+			date := result.EarliestFinish
+			if task.DueDate != nil {
+				date = *task.DueDate
+			}
+			progress := float64(task.Progress)
+			if result.Done {
+				progress = 100
+			}
+			chart.Milestones = append(chart.Milestones, &models.GanttMilestone{
+				TaskID:   &task.ID,
+				Title:    task.Title,
+				Date:     date,
+				Done:     result.Done,
+				Progress: progress,
+			})
+		}
+		chart.Bars = append(chart.Bars, bar)
+	}
+
+	for _, dependency := range plan.dependencies {
+		chart.Links = append(chart.Links, &models.GanttLink{
+			ID:     dependency.ID,
+			Source: dependency.DependsOnID,
+			Target: dependency.TaskID,
+			Type:   dependency.Type,
+		})
+	}
+
+	return chart
+}
+
+func (app *Application) getProjectGanttHandler(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid project ID", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	opts, ok := bindScheduleQuery(c)
+	if !ok {
+		return
+	}
+
+	if _, ok := app.authorizeProject(c, projectID, policy.ProjectRoleViewer); !ok {
+		return
+	}
+
+	plan, ok := app.loadProjectPlan(c, projectID, opts, "retrieve Gantt chart")
+	if !ok {
+		return
+	}
+
+	milestones, err := app.db.Milestones().List(c.Request.Context(), projectID)
+	if err != nil {
+		app.logger.Printf("Error getting milestones: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to retrieve Gantt chart", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := models.NewSuccessResponse(buildGanttChart(projectID, plan, milestones), "Gantt chart retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
\ No newline at end of file
@@ -0,0 +1,857 @@
+package main
+
+import (
+	"ai-project-backend/models"
+	"ai-project-backend/msproject"
+	"ai-project-backend/policy"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Interchange formats accepted by the export and import routes
+const (
+	formatMSProject = "msproject"
+	formatCSV       = "csv"
+)
+
+// maxImportSize is the largest accepted import file in bytes
+const maxImportSize = 10 << 20
+
+// maxImportTasks is the largest number of tasks in one import, as for bulk import
+const maxImportTasks = 1000
+
+// workdayStartHour is the hour working days start at in exported plans
+const workdayStartHour = 8
+
+// csvColumns lists the columns of exported CSV files. Imports read the
+// columns by name, so their order and any extra columns do not matter.
+var csvColumns = []string{
+	"id", "parent_id", "title", "description", "status", "priority",
+	"estimated_hours", "progress", "due_date", "assignee", "tags", "depends_on",
+}
+
+// MSPDI priorities for the task priorities; MS Project uses 0-1000 with 500 as normal
+var msProjectPriorities = map[string]int{
+	"low":    300,
+	"medium": 500,
+	"high":   700,
+}
+
+// planTask is a task as exchanged with other tools. Key identifies the task
+// within one file; tasks refer to their parent and blockers by key. An
+// empty status is derived from the progress.
+type planTask struct {
+	Key            string
+	ParentKey      string
+	Title          string
+	Description    string
+	Status         string
+	Priority       string
+	EstimatedHours *float64
+	Progress       int
+	DueDate        *time.Time
+	Assignee       string
+	Tags           []string
+	Dependencies   []planDependency
+}
+
+// planDependency makes a plan task wait for the task with Key
+type planDependency struct {
+	Key  string
+	Type string
+}
+
+// projectUsernames maps the IDs of a project's members and of any other
+// assignees of its tasks to their usernames
+func (app *Application) projectUsernames(c *gin.Context, projectID int, tasks []*models.Task, action string) (map[int]string, bool) {
+	ctx := c.Request.Context()
+	members, err := app.db.Members().List(ctx, projectID)
+	if err != nil {
+		app.logger.Printf("Error getting project members: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to "+action, nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return nil, false
+	}
+
+	usernames := make(map[int]string, len(members))
+	for _, member := range members {
+		usernames[member.UserID] = member.Username
+	}
+	for _, task := range tasks {
+		if task.AssigneeID == nil || usernames[*task.AssigneeID] != "" {
+			continue
+		}
+		user, err := app.db.Users().GetByID(ctx, *task.AssigneeID)
+		if err != nil {
+			if err.Error() == "user not found" {
+				continue
+			}
+			app.logger.Printf("Error getting user: %v", err)
+			response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to "+action, nil)
+			c.JSON(http.StatusInternalServerError, response)
+			return nil, false
+		}
+		usernames[user.ID] = user.Username
+	}
+
+	return usernames, true
+}
+
+// workTime converts a schedule day offset into a time on a working day that
+// starts at workdayStartHour. A finish that falls on a day boundary after
+// from is placed at the end of the previous working day.
+func workTime(start time.Time, from, offset, hoursPerDay float64) time.Time {
+	day := math.Floor(offset)
+	if offset > from && offset == day {
+		day--
+	}
+	hours := workdayStartHour + (offset-day)*hoursPerDay
+	return start.AddDate(0, 0, int(day)).Add(time.Duration(hours * float64(time.Hour)))
+}
+
+// buildMSProject converts a project plan into an MSPDI document. Tasks keep
+// their IDs as UIDs; assignees become resources named by username.
+func buildMSProject(project *models.Project, plan *projectPlan, usernames map[int]string) *msproject.Project {
+	hoursPerDay := plan.schedule.HoursPerDay
+	start := plan.schedule.Start
+	scheduled := make(map[int]int, len(plan.schedule.Tasks))
+	for i, result := range plan.schedule.Tasks {
+		scheduled[result.TaskID] = i
+	}
+	predecessors := make(map[int][]msproject.PredecessorLink)
+	for _, dependency := range plan.dependencies {
+		linkType := msproject.LinkFinishToStart
+		if dependency.Type == models.DependencyStartToStart {
+			linkType = msproject.LinkStartToStart
+		}
+		predecessors[dependency.TaskID] = append(predecessors[dependency.TaskID], msproject.PredecessorLink{
+			PredecessorUID: dependency.DependsOnID,
+			Type:           linkType,
+		})
+	}
+
+	doc := &msproject.Project{
+		Name:          project.Name,
+		Title:         project.Name,
+		StartDate:     msproject.FormatTime(workTime(start, 0, 0, hoursPerDay)),
+		FinishDate:    msproject.FormatTime(workTime(start, 0, plan.schedule.DurationDays, hoursPerDay)),
+		MinutesPerDay: int(hoursPerDay * 60),
+	}
+
+	resources := make(map[int]int)
+	ordered, depth := treeOrder(plan.tasks)
+	var outline []int
+	for i, task := range ordered {
+		result := plan.schedule.Tasks[scheduled[task.ID]]
+		level := depth[task.ID]
+		if len(outline) > level {
+			outline = outline[:level+1]
+		} else {
+			outline = append(outline, 0)
+		}
+		outline[level]++
+		number := make([]string, level+1)
+		for j := range number {
+			number[j] = strconv.Itoa(outline[j])
+		}
+
+		msTask := msproject.Task{
+			UID:              task.ID,
+			ID:               i + 1,
+			Name:             task.Title,
+			OutlineNumber:    strings.Join(number, "."),
+			OutlineLevel:     level + 1,
+			Priority:         msProjectPriorities[task.Priority],
+			Start:            msproject.FormatTime(workTime(start, 0, result.EarliestStartDay, hoursPerDay)),
+			Finish:           msproject.FormatTime(workTime(start, result.EarliestStartDay, result.EarliestFinishDay, hoursPerDay)),
+			Duration:         msproject.FormatDuration(result.DurationDays * hoursPerDay),
+			PercentComplete:  task.Progress,
+			Notes:            task.Description,
+			PredecessorLinks: predecessors[task.ID],
+		}
+		if result.Done {
+			msTask.PercentComplete = 100
+		}
+		if result.Summary {
+			msTask.Summary = 1
+		} else if task.EstimatedHours != nil {
+			msTask.Work = msproject.FormatDuration(*task.EstimatedHours)
+		}
+		if isMilestone(task) {
+			msTask.Milestone = 1
+		}
+		if task.DueDate != nil {
+			due := time.Date(task.DueDate.Year(), task.DueDate.Month(), task.DueDate.Day(), 0, 0, 0, 0, time.UTC)
+			msTask.Deadline = msproject.FormatTime(workTime(due, 0, 1, hoursPerDay))
+		}
+		doc.Tasks = append(doc.Tasks, msTask)
+
+		if task.AssigneeID == nil || usernames[*task.AssigneeID] == "" {
+			continue
+		}
+		resourceUID, ok := resources[*task.AssigneeID]
+		if !ok {
+			resourceUID = len(doc.Resources) + 1
+			resources[*task.AssigneeID] = resourceUID
+			doc.Resources = append(doc.Resources, msproject.Resource{
+				UID:  resourceUID,
+				ID:   resourceUID,
+				Name: usernames[*task.AssigneeID],
+				Type: 1,
+			})
+		}
+		doc.Assignments = append(doc.Assignments, msproject.Assignment{
+			UID:         len(doc.Assignments) + 1,
+			TaskUID:     task.ID,
+			ResourceUID: resourceUID,
+			Work:        msTask.Work,
+		})
+	}
+
+	return doc
+}
+
+// formatHours formats optional hours for a CSV cell
+func formatHours(hours *float64) string {
+	if hours == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*hours, 'f', -1, 64)
+}
+
+// writePlanCSV writes a project plan as CSV, one task per row in tree order
+func writePlanCSV(w io.Writer, plan *projectPlan, usernames map[int]string) error {
+	blockers := make(map[int][]string)
+	for _, dependency := range plan.dependencies {
+		blocker := strconv.Itoa(dependency.DependsOnID)
+		if dependency.Type != models.DependencyFinishToStart {
+			blocker += ":" + dependency.Type
+		}
+		blockers[dependency.TaskID] = append(blockers[dependency.TaskID], blocker)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvColumns); err != nil {
+		return err
+	}
+
+	ordered, _ := treeOrder(plan.tasks)
+	for _, task := range ordered {
+		parentID := ""
+		if task.ParentID != nil {
+			parentID = strconv.Itoa(*task.ParentID)
+		}
+		dueDate := ""
+		if task.DueDate != nil {
+			dueDate = task.DueDate.Format("2006-01-02")
+		}
+		assignee := ""
+		if task.AssigneeID != nil {
+			assignee = usernames[*task.AssigneeID]
+		}
+
+		record := []string{
+			strconv.Itoa(task.ID), parentID, task.Title, task.Description, task.Status, task.Priority,
+			formatHours(task.EstimatedHours), strconv.Itoa(task.Progress), dueDate, assignee,
+			strings.Join(task.Tags, ";"), strings.Join(blockers[task.ID], ";"),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// readPlanCSV reads plan tasks from a CSV file with a header row. Only the
+// title column is required.
+func readPlanCSV(r io.Reader) ([]*planTask, []models.ValidationError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, []models.ValidationError{{Field: "title", Message: "CSV file needs a title column"}}, nil
+	}
+
+	var tasks []*planTask
+	var errs []models.ValidationError
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		get := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		prefix := fmt.Sprintf("tasks[%d]", len(tasks))
+
+		task := &planTask{
+			Key:         get("id"),
+			ParentKey:   get("parent_id"),
+			Title:       get("title"),
+			Description: get("description"),
+			Status:      get("status"),
+			Priority:    get("priority"),
+			Assignee:    get("assignee"),
+			Tags:        []string{},
+		}
+		if value := get("estimated_hours"); value != "" {
+			hours, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				errs = append(errs, models.ValidationError{Field: prefix + ".estimated_hours", Message: "Must be a number", Value: value})
+			}
+			task.EstimatedHours = &hours
+		}
+		if value := get("progress"); value != "" {
+			progress, err := strconv.Atoi(value)
+			if err != nil {
+				errs = append(errs, models.ValidationError{Field: prefix + ".progress", Message: "Must be a whole number", Value: value})
+			}
+			task.Progress = progress
+		}
+		if value := get("due_date"); value != "" {
+			dueDate, err := time.Parse("2006-01-02", value)
+			if err != nil {
+				errs = append(errs, models.ValidationError{Field: prefix + ".due_date", Message: "Must be a date (YYYY-MM-DD)", Value: value})
+			}
+			task.DueDate = &dueDate
+		}
+		for _, tag := range strings.Split(get("tags"), ";") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				task.Tags = append(task.Tags, tag)
+			}
+		}
+		for _, blocker := range strings.Split(get("depends_on"), ";") {
+			blocker = strings.TrimSpace(blocker)
+			if blocker == "" {
+				continue
+			}
+			dependency := planDependency{Key: blocker, Type: models.DependencyFinishToStart}
+			if i := strings.LastIndex(blocker, ":"); i >= 0 {
+				dependency.Key = blocker[:i]
+				dependency.Type = strings.ToUpper(blocker[i+1:])
+			}
+			task.Dependencies = append(task.Dependencies, dependency)
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	return tasks, errs, nil
+}
+
+// readPlanMSProject converts the tasks of an MSPDI document into plan
+// tasks. The project summary task and empty rows are skipped. Parts that
+// cannot be mapped are reported as warnings.
+func readPlanMSProject(doc *msproject.Project) ([]*planTask, []string) {
+	var warnings []string
+	resources := make(map[int]string, len(doc.Resources))
+	for _, resource := range doc.Resources {
+		resources[resource.UID] = resource.Name
+	}
+	assignees := make(map[int][]string)
+	for _, assignment := range doc.Assignments {
+		if name := resources[assignment.ResourceUID]; name != "" {
+			assignees[assignment.TaskUID] = append(assignees[assignment.TaskUID], name)
+		}
+	}
+
+	var tasks []*planTask
+	var outline []string
+	for _, msTask := range doc.Tasks {
+		if msTask.UID == 0 || msTask.IsNull == 1 {
+			continue
+		}
+		key := strconv.Itoa(msTask.UID)
+		level := msTask.OutlineLevel
+		if level < 1 {
+			level = 1
+		}
+		if level > len(outline)+1 {
+			level = len(outline) + 1
+		}
+		outline = append(outline[:level-1], key)
+
+		task := &planTask{
+			Key:         key,
+			Title:       strings.TrimSpace(msTask.Name),
+			Description: msTask.Notes,
+			Progress:    msTask.PercentComplete,
+			Tags:        []string{},
+		}
+		if level > 1 {
+			task.ParentKey = outline[level-2]
+		}
+		switch {
+		case msTask.Priority == 0:
+		case msTask.Priority < msProjectPriorities["medium"]:
+			task.Priority = "low"
+		case msTask.Priority > msProjectPriorities["medium"]:
+			task.Priority = "high"
+		default:
+			task.Priority = "medium"
+		}
+
+		// Summary tasks add up their subtasks, so their work is not kept
+		if msTask.Summary != 1 {
+			work := msTask.Work
+			if hours, err := msproject.ParseDuration(work); work == "" || err != nil || hours == 0 {
+				work = msTask.Duration
+			}
+			if work != "" {
+				hours, err := msproject.ParseDuration(work)
+				if err != nil {
+					warnings = append(warnings, fmt.Sprintf("Task %s: ignored invalid work %q", key, work))
+				} else {
+					task.EstimatedHours = &hours
+				}
+			}
+			if msTask.Milestone == 1 {
+				hours := 0.0
+				task.EstimatedHours = &hours
+			}
+		}
+
+		if msTask.Deadline != "" {
+			deadline, err := msproject.ParseTime(msTask.Deadline)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("Task %s: ignored invalid deadline %q", key, msTask.Deadline))
+			} else {
+				dueDate := time.Date(deadline.Year(), deadline.Month(), deadline.Day(), 0, 0, 0, 0, time.UTC)
+				task.DueDate = &dueDate
+			}
+		}
+
+		if names := assignees[msTask.UID]; len(names) > 0 {
+			task.Assignee = names[0]
+			if len(names) > 1 {
+				warnings = append(warnings, fmt.Sprintf("Task %s: only the first of %d resources was assigned", key, len(names)))
+			}
+		}
+
+		for _, link := range msTask.PredecessorLinks {
+			dependency := planDependency{Key: strconv.Itoa(link.PredecessorUID)}
+			switch link.Type {
+			case msproject.LinkFinishToStart:
+				dependency.Type = models.DependencyFinishToStart
+			case msproject.LinkStartToStart:
+				dependency.Type = models.DependencyStartToStart
+			default:
+				warnings = append(warnings, fmt.Sprintf("Task %s: skipped link to task %s; only finish-to-start and start-to-start links are supported", key, dependency.Key))
+				continue
+			}
+			task.Dependencies = append(task.Dependencies, dependency)
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	return tasks, warnings
+}
+
+// importPlanTasks validates plan tasks and creates them in a project with
+// their hierarchy and dependencies, all in one transaction. Tasks must be
+// listed after their parent. Unknown assignees and blockers are skipped with
+// a warning; everything else that does not fit fails the import.
+func (app *Application) importPlanTasks(c *gin.Context, projectID int, plan []*planTask, warnings []string, note string) {
+	if len(plan) == 0 {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "No tasks provided", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	if len(plan) > maxImportTasks {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, fmt.Sprintf("Too many tasks (max %d)", maxImportTasks), nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	ctx := c.Request.Context()
+	workflow, ok := app.projectWorkflow(c, projectID, "import tasks")
+	if !ok {
+		return
+	}
+	fields, ok := app.projectCustomFields(c, projectID, "import tasks")
+	if !ok {
+		return
+	}
+	members, err := app.db.Members().List(ctx, projectID)
+	if err != nil {
+		app.logger.Printf("Error getting project members: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to import tasks", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	memberIDs := make(map[string]int, len(members))
+	for _, member := range members {
+		memberIDs[member.Username] = member.UserID
+	}
+
+	var errs []models.ValidationError
+	keys := make(map[string]int, len(plan))
+	tasks := make([]*models.Task, len(plan))
+	parents := make([]int, len(plan))
+	for i, item := range plan {
+		prefix := fmt.Sprintf("tasks[%d]", i)
+		parents[i] = -1
+
+		if item.Key != "" {
+			if _, ok := keys[item.Key]; ok {
+				errs = append(errs, models.ValidationError{Field: prefix + ".id", Message: "Task ID is used by another task", Value: item.Key})
+			}
+			keys[item.Key] = i
+		}
+
+		task := &models.Task{
+			ProjectID:      projectID,
+			Title:          item.Title,
+			Description:    item.Description,
+			Status:         item.Status,
+			DueDate:        item.DueDate,
+			Priority:       item.Priority,
+			EstimatedHours: item.EstimatedHours,
+			Progress:       item.Progress,
+			Tags:           item.Tags,
+		}
+		if task.Title == "" || len(task.Title) > 255 {
+			errs = append(errs, models.ValidationError{Field: prefix + ".title", Message: "Title must be 1-255 characters"})
+		}
+		if task.Priority == "" {
+			task.Priority = "medium"
+		}
+		if !validTaskPriorities[task.Priority] {
+			errs = append(errs, models.ValidationError{Field: prefix + ".priority", Message: "Priority must be one of low, medium, high", Value: task.Priority})
+		}
+		if task.EstimatedHours != nil && *task.EstimatedHours < 0 {
+			errs = append(errs, models.ValidationError{Field: prefix + ".estimated_hours", Message: "Estimated hours must not be negative"})
+		}
+		if task.Progress < 0 || task.Progress > 100 {
+			errs = append(errs, models.ValidationError{Field: prefix + ".progress", Message: "Progress must be between 0 and 100"})
+		}
+
+		switch {
+		case task.Status == "" && task.Progress >= 100:
+			task.Status = workflow.FirstStatus(models.StatusCategoryDone)
+		case task.Status == "" && task.Progress > 0:
+			task.Status = workflow.FirstStatus(models.StatusCategoryDoing)
+			if task.Status == "" {
+				task.Status = workflow.FirstStatus(models.StatusCategoryTodo)
+			}
+		case task.Status == "":
+			task.Status = workflow.FirstStatus(models.StatusCategoryTodo)
+		case workflow.Status(task.Status) == nil:
+			errs = append(errs, models.ValidationError{
+				Field:   prefix + ".status",
+				Message: "Status must be one of " + strings.Join(statusKeys(workflow), ", "),
+				Value:   task.Status,
+			})
+		}
+
+		if item.ParentKey != "" {
+			parent, ok := keys[item.ParentKey]
+			switch {
+			case !ok || parent == i:
+				errs = append(errs, models.ValidationError{Field: prefix + ".parent_id", Message: "Parent task must be listed before its subtasks", Value: item.ParentKey})
+			case tasks[parent].Level >= maxTaskLevel:
+				errs = append(errs, models.ValidationError{Field: prefix + ".parent_id", Message: fmt.Sprintf("Tasks can be nested at most %d levels deep", maxTaskLevel+1), Value: item.ParentKey})
+			default:
+				parents[i] = parent
+				task.Level = tasks[parent].Level + 1
+			}
+		}
+
+		if item.Assignee != "" {
+			if userID, ok := memberIDs[item.Assignee]; ok {
+				task.AssigneeID = &userID
+			} else {
+				warnings = append(warnings, fmt.Sprintf("Task %d: %s is not a project member and was not assigned", i+1, item.Assignee))
+			}
+		}
+
+		values, fieldErrs, err := app.validateCustomFields(ctx, fields, nil, prefix+".custom_fields")
+		if err != nil {
+			app.logger.Printf("Error validating custom fields: %v", err)
+			response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to import tasks", nil)
+			c.JSON(http.StatusInternalServerError, response)
+			return
+		}
+		task.CustomFields = values
+		errs = append(errs, fieldErrs...)
+
+		tasks[i] = task
+	}
+
+	// Blockers may appear after the tasks waiting for them
+	for i, item := range plan {
+		for _, dependency := range item.Dependencies {
+			if _, ok := keys[dependency.Key]; !ok {
+				warnings = append(warnings, fmt.Sprintf("Task %d: skipped dependency on unknown task %s", i+1, dependency.Key))
+				continue
+			}
+			if !validDependencyTypes[dependency.Type] {
+				errs = append(errs, models.ValidationError{Field: fmt.Sprintf("tasks[%d].depends_on", i), Message: "Type must be one of FS, SS", Value: dependency.Type})
+			}
+		}
+	}
+	if len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, models.NewValidationErrorResponse(errs))
+		return
+	}
+
+	tx, err := app.db.BeginTx(ctx)
+	if err != nil {
+		app.logger.Printf("Error starting transaction: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to import tasks", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	defer tx.Rollback()
+
+	for i, task := range tasks {
+		if parents[i] >= 0 {
+			task.ParentID = &tasks[parents[i]].ID
+		}
+		if _, err := tx.Tasks().Create(ctx, task); err != nil {
+			app.logger.Printf("Error creating task: %v", err)
+			response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to import tasks", nil)
+			c.JSON(http.StatusInternalServerError, response)
+			return
+		}
+	}
+
+	claims := currentUser(c)
+	if err := recordTasksCreated(ctx, tx.TaskUpdates(), tasks, &claims.UserID, note); err != nil {
+		app.logger.Printf("Error recording task update: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to import tasks", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	if err := tx.Dependencies().LockProject(ctx, projectID); err != nil {
+		app.logger.Printf("Error locking project dependencies: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to import tasks", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	for i, item := range plan {
+		for _, link := range item.Dependencies {
+			blocker, ok := keys[link.Key]
+			if !ok || blocker == i {
+				continue
+			}
+			cycle, err := tx.Dependencies().DependsOn(ctx, tasks[blocker].ID, tasks[i].ID)
+			if err != nil {
+				app.logger.Printf("Error checking dependency chain: %v", err)
+				response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to import tasks", nil)
+				c.JSON(http.StatusInternalServerError, response)
+				return
+			}
+			if cycle {
+				errs := []models.ValidationError{{Field: fmt.Sprintf("tasks[%d].depends_on", i), Message: "Dependency would create a cycle", Value: link.Key}}
+				c.JSON(http.StatusBadRequest, models.NewValidationErrorResponse(errs))
+				return
+			}
+			_, err = tx.Dependencies().Add(ctx, &models.TaskDependency{
+				TaskID:      tasks[i].ID,
+				DependsOnID: tasks[blocker].ID,
+				Type:        link.Type,
+				CreatedBy:   &claims.UserID,
+			})
+			if err != nil && err.Error() != "dependency already exists" {
+				app.logger.Printf("Error adding task dependency: %v", err)
+				response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to import tasks", nil)
+				c.JSON(http.StatusInternalServerError, response)
+				return
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		app.logger.Printf("Error committing transaction: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to import tasks", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	// Roll up from the parents of imported leaf tasks; each roll-up walks
+	// on to the top of the tree
+	hasChildren := make(map[int]bool)
+	for _, parent := range parents {
+		hasChildren[parent] = true
+	}
+	rolledUp := make(map[int]bool)
+	importedIDs := make([]int, len(tasks))
+	for i, task := range tasks {
+		importedIDs[i] = task.ID
+		if parents[i] < 0 || hasChildren[i] || rolledUp[parents[i]] {
+			continue
+		}
+		rolledUp[parents[i]] = true
+		if err := app.rollUpTask(ctx, app.db.Tasks(), app.db.TaskUpdates(), task.ParentID); err != nil {
+			app.logger.Printf("Error rolling up parent task: %v", err)
+		}
+	}
+
+	bulkResponse := models.BulkImportResponse{
+		TotalTasks:    len(plan),
+		SuccessCount:  len(tasks),
+		FailureCount:  0,
+		ImportedTasks: importedIDs,
+	}
+
+	response := models.NewSuccessResponse(bulkResponse, "Tasks imported successfully")
+	response.Warnings = warnings
+	c.JSON(http.StatusCreated, response)
+}
+
+func (app *Application) exportProjectHandler(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid project ID", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	format := c.Param("format")
+	if format != formatMSProject && format != formatCSV {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Format must be one of msproject, csv", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	opts, ok := bindScheduleQuery(c)
+	if !ok {
+		return
+	}
+
+	if _, ok := app.authorizeProject(c, projectID, policy.ProjectRoleViewer); !ok {
+		return
+	}
+
+	project, err := app.db.Projects().GetByID(c.Request.Context(), projectID)
+	if err != nil {
+		if err.Error() == "project not found" {
+			response := models.NewErrorResponse(models.ErrCodeNotFound, "Project not found", nil)
+			c.JSON(http.StatusNotFound, response)
+			return
+		}
+		app.logger.Printf("Error getting project: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to export project", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	plan, ok := app.loadProjectPlan(c, projectID, opts, "export project")
+	if !ok {
+		return
+	}
+	usernames, ok := app.projectUsernames(c, projectID, plan.tasks, "export project")
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	contentType := "application/xml"
+	filename := fmt.Sprintf("project-%d.xml", projectID)
+	if format == formatCSV {
+		contentType = "text/csv"
+		filename = fmt.Sprintf("project-%d.csv", projectID)
+		err = writePlanCSV(&buf, plan, usernames)
+	} else {
+		err = msproject.Encode(&buf, buildMSProject(project, plan, usernames))
+	}
+	if err != nil {
+		app.logger.Printf("Error encoding project export: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to export project", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, contentType+"; charset=utf-8", buf.Bytes())
+}
+
+func (app *Application) importProjectHandler(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid project ID", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	format := c.Param("format")
+	if format != formatMSProject && format != formatCSV {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Format must be one of msproject, csv", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if _, ok := app.authorizeProject(c, projectID, policy.ProjectRoleMember); !ok {
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize))
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, fmt.Sprintf("Import file must be at most %d MB", maxImportSize>>20), nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	var plan []*planTask
+	var warnings []string
+	note := "Imported from MS Project"
+	if format == formatCSV {
+		note = "Imported from CSV"
+		var errs []models.ValidationError
+		plan, errs, err = readPlanCSV(bytes.NewReader(body))
+		if err != nil {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid CSV file", nil)
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+		if len(errs) > 0 {
+			c.JSON(http.StatusBadRequest, models.NewValidationErrorResponse(errs))
+			return
+		}
+	} else {
+		doc, err := msproject.Decode(bytes.NewReader(body))
+		if err != nil {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid MS Project XML file", nil)
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+		plan, warnings = readPlanMSProject(doc)
+	}
+
+	app.importPlanTasks(c, projectID, plan, warnings, note)
+}
\ No newline at end of file
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"ai-project-backend/models"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Scopes of the login_failures counters
+const (
+	loginScopeUsername = "username"
+	loginScopeIP       = "ip"
+)
+
+// loginKey identifies one failure counter
+type loginKey struct {
+	scope   string
+	subject string
+}
+
+// loginKeys returns the counters a login attempt for username is tracked under
+func loginKeys(c *gin.Context, username string) []loginKey {
+	return []loginKey{
+		{scope: loginScopeUsername, subject: username},
+		{scope: loginScopeIP, subject: c.ClientIP()},
+	}
+}
+
+// loginBackoff returns the delay enforced after the given number of consecutive failures
+func (app *Application) loginBackoff(failures int) time.Duration {
+	cfg := app.config.Login
+	if failures <= 0 || cfg.BackoffBase <= 0 {
+		return 0
+	}
+
+	delay := cfg.BackoffBase
+	for i := 1; i < failures && (cfg.BackoffMax <= 0 || delay < cfg.BackoffMax); i++ {
+		delay *= 2
+	}
+	if cfg.BackoffMax > 0 && delay > cfg.BackoffMax {
+		delay = cfg.BackoffMax
+	}
+
+	return delay
+}
+
+// loginRetryAfter returns how long a counter still blocks logins and whether
+// that is because of a lockout rather than backoff
+func (app *Application) loginRetryAfter(failure *models.LoginFailure, now time.Time) (time.Duration, bool) {
+	if failure.LockedUntil != nil && failure.LockedUntil.After(now) {
+		return failure.LockedUntil.Sub(now), true
+	}
+
+	if now.Sub(failure.LastFailedAt) > app.config.Login.FailureWindow {
+		return 0, false
+	}
+
+	wait := failure.LastFailedAt.Add(app.loginBackoff(failure.FailureCount)).Sub(now)
+	if wait < 0 {
+		wait = 0
+	}
+	return wait, false
+}
+
+// checkLoginAllowed refuses a login for username while the username or the client
+// IP is locked out or backing off. It writes the error response, audits the
+// refused attempt and returns false when the login must not proceed.
+// user may be nil for unknown usernames.
+func (app *Application) checkLoginAllowed(c *gin.Context, username string, user *models.User) bool {
+	if !app.config.Login.ProtectionEnabled {
+		return true
+	}
+
+	now := time.Now()
+	var wait time.Duration
+	locked := false
+
+	for _, key := range loginKeys(c, username) {
+		failure, err := app.db.LoginFailures().Get(c.Request.Context(), key.scope, key.subject)
+		if err != nil {
+			if err.Error() == "login failure not found" {
+				continue
+			}
+			app.logger.Printf("Error getting login failures: %v", err)
+			response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to login", nil)
+			c.JSON(http.StatusInternalServerError, response)
+			return false
+		}
+
+		keyWait, keyLocked := app.loginRetryAfter(failure, now)
+		if keyWait > wait {
+			wait = keyWait
+		}
+		locked = locked || keyLocked
+	}
+
+	if wait <= 0 {
+		return true
+	}
+
+	reason := "rate_limited"
+	message := "Too many failed login attempts, please try again later"
+	if locked {
+		reason = "locked_out"
+		message = "Account is temporarily locked due to too many failed login attempts"
+	}
+
+	app.auditLoginFailure(c, username, user, map[string]interface{}{"reason": reason})
+
+	retryAfter := int(math.Ceil(wait.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	response := models.NewErrorResponse(
+		models.ErrCodeTooManyRequests,
+		message,
+		map[string]interface{}{"retry_after_seconds": retryAfter},
+	)
+	c.JSON(http.StatusTooManyRequests, response)
+	return false
+}
+
+// recordLoginFailure counts a failed login against the username and client IP,
+// locks whichever reaches its threshold, and audits the attempt
+func (app *Application) recordLoginFailure(c *gin.Context, username string, user *models.User, reason string) {
+	entityData := map[string]interface{}{"reason": reason}
+
+	if app.config.Login.ProtectionEnabled {
+		for _, key := range loginKeys(c, username) {
+			failure, err := app.db.LoginFailures().RecordFailure(c.Request.Context(), key.scope, key.subject, app.config.Login.FailureWindow)
+			if err != nil {
+				app.logger.Printf("Error recording login failure: %v", err)
+				continue
+			}
+
+			threshold := app.config.Login.MaxFailures
+			if key.scope == loginScopeIP {
+				threshold = app.config.Login.IPMaxFailures
+			} else {
+				entityData["failure_count"] = failure.FailureCount
+			}
+
+			if threshold <= 0 || failure.FailureCount < threshold || failure.LockedUntil != nil {
+				continue
+			}
+
+			until := time.Now().Add(app.config.Login.LockoutDuration)
+			if err := app.db.LoginFailures().Lock(c.Request.Context(), key.scope, key.subject, until); err != nil {
+				app.logger.Printf("Error locking login: %v", err)
+				continue
+			}
+
+			app.logger.Printf("Locked logins for %s %q until %s after %d failures", key.scope, key.subject, until.Format(time.RFC3339), failure.FailureCount)
+			entityData["locked_"+key.scope] = true
+			entityData["locked_until"] = until
+		}
+	}
+
+	app.auditLoginFailure(c, username, user, entityData)
+}
+
+// resetLoginFailures clears the username's failure counter after a successful login.
+// The IP counter is left to expire so one valid account cannot be used to
+// reset it while guessing passwords for others.
+func (app *Application) resetLoginFailures(c *gin.Context, username string) {
+	if !app.config.Login.ProtectionEnabled {
+		return
+	}
+
+	if _, err := app.db.LoginFailures().Reset(c.Request.Context(), loginScopeUsername, username); err != nil {
+		app.logger.Printf("Error resetting login failures: %v", err)
+	}
+}
+
+// auditLoginFailure writes a LOGIN_FAILED audit entry with the client IP and user agent
+func (app *Application) auditLoginFailure(c *gin.Context, username string, user *models.User, entityData map[string]interface{}) {
+	entityData["username"] = username
+
+	var userID *int
+	entityID := 0
+	if user != nil {
+		userID = &user.ID
+		entityID = user.ID
+	}
+
+	if err := app.db.System().LogAction(c.Request.Context(), userID, "LOGIN_FAILED", "user", entityID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+}
+
+func (app *Application) unlockUserHandler(c *gin.Context) {
+	user, ok := app.loadUserParam(c)
+	if !ok {
+		return
+	}
+
+	cleared, err := app.db.LoginFailures().Reset(c.Request.Context(), loginScopeUsername, user.Username)
+	if err != nil {
+		app.logger.Printf("Error resetting login failures: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to unlock user", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	claims := currentUser(c)
+	entityData := map[string]interface{}{"unlocked": true, "username": user.Username}
+	if err := app.db.System().LogAction(c.Request.Context(), &claims.UserID, "UPDATE", "user", user.ID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
+	data := map[string]interface{}{
+		"user_id":          user.ID,
+		"username":         user.Username,
+		"failures_cleared": cleared,
+	}
+
+	response := models.NewSuccessResponse(data, "User unlocked successfully")
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,61 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+)
+
+// FileSender appends messages to a file instead of delivering them.
+// It is meant for development and tests.
+type FileSender struct {
+	mu   sync.Mutex
+	path string
+	from string
+}
+
+// NewFileSender creates a sender that appends messages to path
+func NewFileSender(path, from string) *FileSender {
+	return &FileSender{path: path, from: from}
+}
+
+// Send appends the formatted message to the file, followed by a separator line
+func (s *FileSender) Send(ctx context.Context, msg Message) error {
+	body, err := format(s.from, msg)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open mail file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(body, "\r\n----\r\n"...)); err != nil {
+		return fmt.Errorf("failed to write mail file: %w", err)
+	}
+
+	return nil
+}
+
+// LogSender writes messages to a logger instead of delivering them
+type LogSender struct {
+	logger *log.Logger
+}
+
+// NewLogSender creates a sender that writes messages to logger
+func NewLogSender(logger *log.Logger) *LogSender {
+	return &LogSender{logger: logger}
+}
+
+// Send logs the recipient, subject and body
+func (s *LogSender) Send(ctx context.Context, msg Message) error {
+	s.logger.Printf("Mail to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
@@ -3,11 +3,16 @@ package main
 import (
 	"ai-project-backend/config"
 	"ai-project-backend/database"
+	"ai-project-backend/mail"
 	"ai-project-backend/models"
+	"ai-project-backend/oidc"
+	"ai-project-backend/policy"
+	"ai-project-backend/utils"
 	"fmt"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -23,9 +28,13 @@ var (
 
 // Application holds the application dependencies
 type Application struct {
-	config *config.Config
-	db     database.DB
-	logger *log.Logger
+	config     *config.Config
+	db         database.DB
+	logger     *log.Logger
+	jwtManager *utils.JWTManager
+	policy     *policy.Policy
+	oidc       *oidcState
+	mailer     mail.Sender
 }
 
 // NewApplication creates a new application instance
@@ -42,10 +51,16 @@ func NewApplication() (*Application, error) {
 		return nil, fmt.Errorf("failed to initialize database: %v", err)
 	}
 
+	logger := log.New(log.Writer(), "[API] ", log.LstdFlags)
+
 	return &Application{
-		config: cfg,
-		db:     db,
-		logger: log.New(log.Writer(), "[API] ", log.LstdFlags),
+		config:     cfg,
+		db:         db,
+		logger:     logger,
+		jwtManager: utils.NewJWTManager(cfg.JWT.Secret, cfg.JWT.Expiration),
+		policy:     policy.DefaultPolicy(),
+		oidc:       &oidcState{logins: oidc.NewStateStore(oidcLoginTTL)},
+		mailer:     newMailSender(cfg.Mail, logger),
 	}, nil
 }
 
@@ -98,11 +113,22 @@ func (app *Application) setupRouter() *gin.Engine {
 		{
 			auth.POST("/login", app.loginHandler)
 			auth.POST("/logout", app.logoutHandler)
+			auth.POST("/refresh", app.refreshHandler)
+			auth.POST("/2fa/verify", app.verifyMFAHandler)
+			auth.POST("/verify-email", app.verifyEmailHandler)
+			auth.POST("/forgot-password", app.forgotPasswordHandler)
+			auth.POST("/reset-password", app.resetPasswordHandler)
+			auth.GET("/sessions", app.authMiddleware(), app.getSessionsHandler)
+			auth.DELETE("/sessions/:id", app.authMiddleware(), app.deleteSessionHandler)
+
+			// Single sign-on
+			auth.GET("/oidc/login", app.oidcLoginHandler)
+			auth.GET("/oidc/callback", app.oidcCallbackHandler)
 		}
 
-		// Protected routes (will be implemented with auth middleware)
+		// Protected routes
 		authorized := api.Group("/")
-		// authorized.Use(app.authMiddleware()) // Will be implemented in next task
+		authorized.Use(app.authMiddleware())
 		{
 			// Projects routes
 			projects := authorized.Group("/projects")
@@ -112,6 +138,7 @@ func (app *Application) setupRouter() *gin.Engine {
 				projects.GET("/:id", app.getProjectHandler)
 				projects.PUT("/:id", app.updateProjectHandler)
 				projects.DELETE("/:id", app.deleteProjectHandler)
+				projects.GET("/:id/timeline", app.getProjectTimelineHandler)
 
 				// Tasks routes
 				projects.GET("/:id/tasks", app.getTasksHandler)
@@ -120,6 +147,101 @@ func (app *Application) setupRouter() *gin.Engine {
 				projects.GET("/:id/tasks/:taskId", app.getTaskHandler)
 				projects.PUT("/:id/tasks/:taskId", app.updateTaskHandler)
 				projects.DELETE("/:id/tasks/:taskId", app.deleteTaskHandler)
+				projects.GET("/:id/tasks/:taskId/children", app.getTaskChildrenHandler)
+				projects.GET("/:id/tasks/:taskId/tree", app.getTaskTreeHandler)
+				projects.PUT("/:id/tasks/:taskId/parent", app.moveTaskHandler)
+				projects.PUT("/:id/tasks/:taskId/status", app.updateTaskStatusHandler)
+				projects.GET("/:id/tasks/:taskId/updates", app.getTaskUpdatesHandler)
+				projects.GET("/:id/tasks/:taskId/timeline", app.getTaskTimelineHandler)
+
+				// Task dependency routes
+				projects.GET("/:id/tasks/:taskId/dependencies", app.getTaskDependenciesHandler)
+				projects.POST("/:id/tasks/:taskId/dependencies", app.addTaskDependencyHandler)
+				projects.DELETE("/:id/tasks/:taskId/dependencies/:dependsOnId", app.removeTaskDependencyHandler)
+
+				// Schedule routes
+				projects.GET("/:id/schedule", app.getProjectScheduleHandler)
+				projects.GET("/:id/gantt", app.getProjectGanttHandler)
+
+				// Interchange routes
+				projects.GET("/:id/export/:format", app.exportProjectHandler)
+				projects.POST("/:id/import/:format", app.importProjectHandler)
+
+				// Workflow routes
+				projects.GET("/:id/workflow", app.getWorkflowHandler)
+				projects.PUT("/:id/workflow", app.updateWorkflowHandler)
+
+				// Custom field routes
+				projects.GET("/:id/fields", app.getCustomFieldsHandler)
+				projects.POST("/:id/fields", app.createCustomFieldHandler)
+				projects.PUT("/:id/fields/:fieldId", app.updateCustomFieldHandler)
+				projects.DELETE("/:id/fields/:fieldId", app.deleteCustomFieldHandler)
+
+				// Milestone routes
+				projects.GET("/:id/milestones", app.getMilestonesHandler)
+				projects.POST("/:id/milestones", app.createMilestoneHandler)
+				projects.GET("/:id/milestones/:milestoneId", app.getMilestoneHandler)
+				projects.PUT("/:id/milestones/:milestoneId", app.updateMilestoneHandler)
+				projects.DELETE("/:id/milestones/:milestoneId", app.deleteMilestoneHandler)
+
+				// Sprint routes
+				projects.GET("/:id/sprints", app.getSprintsHandler)
+				projects.POST("/:id/sprints", app.createSprintHandler)
+				projects.GET("/:id/sprints/:sprintId", app.getSprintHandler)
+				projects.PUT("/:id/sprints/:sprintId", app.updateSprintHandler)
+				projects.DELETE("/:id/sprints/:sprintId", app.deleteSprintHandler)
+				projects.POST("/:id/sprints/:sprintId/start", app.startSprintHandler)
+				projects.POST("/:id/sprints/:sprintId/close", app.closeSprintHandler)
+				projects.POST("/:id/sprints/:sprintId/tasks", app.addSprintTasksHandler)
+				projects.POST("/:id/backlog", app.moveToBacklogHandler)
+				projects.GET("/:id/velocity", app.getVelocityHandler)
+
+				// Project member routes
+				projects.GET("/:id/members", app.getProjectMembersHandler)
+				projects.POST("/:id/members", app.addProjectMemberHandler)
+				projects.PUT("/:id/members/:userId", app.updateProjectMemberHandler)
+				projects.DELETE("/:id/members/:userId", app.removeProjectMemberHandler)
+			}
+
+			// Search routes
+			authorized.GET("/search", app.searchHandler)
+
+			// User administration routes (admin only)
+			users := authorized.Group("/users")
+			users.Use(app.requirePermission(policy.ActionUserManage))
+			{
+				users.GET("", app.getUsersHandler)
+				users.POST("", app.createUserHandler)
+				users.GET("/:id", app.getUserHandler)
+				users.PUT("/:id", app.updateUserHandler)
+				users.DELETE("/:id", app.deactivateUserHandler)
+				users.POST("/:id/activate", app.activateUserHandler)
+				users.PUT("/:id/role", app.updateUserRoleHandler)
+				users.POST("/:id/reset-password", app.resetUserPasswordHandler)
+				users.POST("/:id/unlock", app.unlockUserHandler)
+				users.GET("/:id/timeline", app.getUserTimelineHandler)
+			}
+
+			// Current user routes
+			me := authorized.Group("/me")
+			{
+				me.GET("", app.getMeHandler)
+				me.PUT("/password", app.changePasswordHandler)
+				me.PUT("/email", app.setEmailHandler)
+				me.POST("/email/verification", app.resendVerificationHandler)
+				me.GET("/timeline", app.getMyTimelineHandler)
+				me.GET("/tasks", app.getMyTasksHandler)
+
+				me.GET("/tokens", app.getAPITokensHandler)
+				me.POST("/tokens", app.createAPITokenHandler)
+				me.DELETE("/tokens/:id", app.revokeAPITokenHandler)
+
+				// Two-factor authentication
+				me.GET("/2fa", app.getTwoFactorHandler)
+				me.POST("/2fa/enroll", app.enrollTwoFactorHandler)
+				me.POST("/2fa/activate", app.activateTwoFactorHandler)
+				me.POST("/2fa/disable", app.disableTwoFactorHandler)
+				me.POST("/2fa/recovery-codes", app.regenerateRecoveryCodesHandler)
 			}
 
 			// System management routes (admin only)
@@ -128,21 +250,30 @@ func (app *Application) setupRouter() *gin.Engine {
 				// Recycle bin routes
 				recycle := system.Group("/recycle")
 				{
-					recycle.GET("/projects", app.getRecycledProjectsHandler)
-					recycle.POST("/projects/:id/restore", app.restoreProjectHandler)
-					recycle.DELETE("/projects/:id", app.hardDeleteProjectHandler)
-					
-					recycle.GET("/tasks", app.getRecycledTasksHandler)
-					recycle.POST("/tasks/:id/restore", app.restoreTaskHandler)
-					recycle.DELETE("/tasks/:id", app.hardDeleteTaskHandler)
+					recycle.GET("/projects", app.requirePermission(policy.ActionRecycleList), app.getRecycledProjectsHandler)
+					recycle.POST("/projects/:id/restore", app.requirePermission(policy.ActionRecycleRestore), app.restoreProjectHandler)
+					recycle.DELETE("/projects/:id", app.requirePermission(policy.ActionRecycleHardDelete), app.hardDeleteProjectHandler)
+
+					recycle.GET("/tasks", app.requirePermission(policy.ActionRecycleList), app.getRecycledTasksHandler)
+					recycle.POST("/tasks/:id/restore", app.requirePermission(policy.ActionRecycleRestore), app.restoreTaskHandler)
+					recycle.DELETE("/tasks/:id", app.requirePermission(policy.ActionRecycleHardDelete), app.hardDeleteTaskHandler)
 				}
 
 				// Audit log routes
 				audit := system.Group("/audit")
+				audit.Use(app.requirePermission(policy.ActionAuditRead))
 				{
 					audit.GET("/logs", app.getAuditLogsHandler)
 				}
 
+				// System settings routes
+				settings := system.Group("/settings")
+				settings.Use(app.requirePermission(policy.ActionSettingsManage))
+				{
+					settings.GET("/security", app.getSecuritySettingsHandler)
+					settings.PUT("/security", app.updateSecuritySettingsHandler)
+				}
+
 			}
 		}
 	}
@@ -155,11 +286,13 @@ func (app *Application) setupRouter() *gin.Engine {
 		{
 			auth.POST("/login", app.loginHandler)
 			auth.POST("/logout", app.logoutHandler)
+			auth.POST("/refresh", app.refreshHandler)
+			auth.POST("/2fa/verify", app.verifyMFAHandler)
 		}
 
-		// Protected routes (will be implemented with auth middleware)
+		// Protected routes
 		authorized := legacyApi.Group("/")
-		// authorized.Use(app.authMiddleware()) // Will be implemented in next task
+		authorized.Use(app.authMiddleware())
 		{
 			// Projects routes
 			projects := authorized.Group("/projects")
@@ -169,6 +302,7 @@ func (app *Application) setupRouter() *gin.Engine {
 				projects.GET("/:id", app.getProjectHandler)
 				projects.PUT("/:id", app.updateProjectHandler)
 				projects.DELETE("/:id", app.deleteProjectHandler)
+				projects.GET("/:id/timeline", app.getProjectTimelineHandler)
 
 				// Tasks routes
 				projects.GET("/:id/tasks", app.getTasksHandler)
@@ -177,6 +311,12 @@ func (app *Application) setupRouter() *gin.Engine {
 				projects.GET("/:id/tasks/:taskId", app.getTaskHandler)
 				projects.PUT("/:id/tasks/:taskId", app.updateTaskHandler)
 				projects.DELETE("/:id/tasks/:taskId", app.deleteTaskHandler)
+				projects.GET("/:id/tasks/:taskId/children", app.getTaskChildrenHandler)
+				projects.GET("/:id/tasks/:taskId/tree", app.getTaskTreeHandler)
+				projects.PUT("/:id/tasks/:taskId/parent", app.moveTaskHandler)
+				projects.PUT("/:id/tasks/:taskId/status", app.updateTaskStatusHandler)
+				projects.GET("/:id/tasks/:taskId/updates", app.getTaskUpdatesHandler)
+				projects.GET("/:id/tasks/:taskId/timeline", app.getTaskTimelineHandler)
 			}
 		}
 	}
@@ -200,6 +340,124 @@ func (app *Application) corsMiddleware() gin.HandlerFunc {
 	}
 }
 
+// contextKeyClaims is the gin.Context key holding the authenticated JWT claims
+const contextKeyClaims = "claims"
+
+// authMiddleware validates the Bearer token and stores its claims on the context.
+// Both JWT access tokens and personal API tokens are accepted.
+func (app *Application) authMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := extractBearerToken(c)
+		if tokenString == "" {
+			response := models.NewErrorResponse(models.ErrCodeAuthentication, "Missing authorization token", nil)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, response)
+			return
+		}
+
+		if strings.HasPrefix(tokenString, apiTokenPrefix) {
+			claims, ok := app.authenticateAPIToken(c, tokenString)
+			if !ok {
+				return
+			}
+			c.Set(contextKeyClaims, claims)
+			c.Next()
+			return
+		}
+
+		// Purpose tokens such as MFA challenges are not access tokens
+		claims, err := app.jwtManager.ValidateToken(tokenString)
+		if err != nil || claims.Purpose != "" {
+			response := models.NewErrorResponse(models.ErrCodeAuthentication, "Invalid or expired token", nil)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, response)
+			return
+		}
+
+		revoked, err := app.db.Tokens().IsTokenRevoked(c.Request.Context(), claims.ID)
+		if err != nil {
+			app.logger.Printf("Error checking token revocation: %v", err)
+			response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to verify token", nil)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, response)
+			return
+		}
+		if revoked {
+			response := models.NewErrorResponse(models.ErrCodeAuthentication, "Token has been revoked", nil)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, response)
+			return
+		}
+
+		// Access tokens die with the session they were issued for
+		if claims.SessionID != "" {
+			active, err := app.db.Sessions().IsFamilyActive(c.Request.Context(), claims.SessionID)
+			if err != nil {
+				app.logger.Printf("Error checking session: %v", err)
+				response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to verify token", nil)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, response)
+				return
+			}
+			if !active {
+				response := models.NewErrorResponse(models.ErrCodeAuthentication, "Session has been revoked", nil)
+				c.AbortWithStatusJSON(http.StatusUnauthorized, response)
+				return
+			}
+		}
+
+		c.Set(contextKeyClaims, claims)
+		c.Next()
+	}
+}
+
+// currentUser returns the claims of the authenticated caller, or nil outside authMiddleware
+func currentUser(c *gin.Context) *utils.JWTClaims {
+	value, exists := c.Get(contextKeyClaims)
+	if !exists {
+		return nil
+	}
+	claims, _ := value.(*utils.JWTClaims)
+	return claims
+}
+
+// requirePermission rejects callers whose role the policy does not allow for the action.
+// Admins must also have passed a second factor when the security policy requires it.
+func (app *Application) requirePermission(action policy.Action) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := currentUser(c)
+		if claims == nil {
+			response := models.NewErrorResponse(models.ErrCodeAuthentication, "Authentication required", nil)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, response)
+			return
+		}
+
+		if err := app.policy.Authorize(claims.Role, action); err != nil {
+			response := models.NewErrorResponse(
+				models.ErrCodeAuthorization,
+				"You do not have permission to perform this action",
+				map[string]interface{}{"action": action, "allowed_roles": app.policy.Roles(action)},
+			)
+			c.AbortWithStatusJSON(http.StatusForbidden, response)
+			return
+		}
+
+		missing, err := app.secondFactorMissing(c.Request.Context(), claims)
+		if err != nil {
+			app.logger.Printf("Error getting security settings: %v", err)
+			response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to verify permissions", nil)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, response)
+			return
+		}
+		if missing {
+			response := models.NewErrorResponse(
+				models.ErrCodeAuthorization,
+				"Two-factor authentication is required for administrators; enable it and log in again",
+				map[string]interface{}{"action": action},
+			)
+			c.AbortWithStatusJSON(http.StatusForbidden, response)
+			return
+		}
+
+		c.Next()
+	}
+}
+
 // Health check handler
 func (app *Application) healthHandler(c *gin.Context) {
 	// Check database connection
@@ -240,20 +498,127 @@ func (app *Application) versionHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
-// Placeholder handlers - to be implemented in upcoming tasks
+// extractBearerToken returns the token from the Authorization header
+func extractBearerToken(c *gin.Context) string {
+	header := c.GetHeader("Authorization")
+	if len(header) < 7 || !strings.EqualFold(header[:7], "Bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(header[7:])
+}
+
 func (app *Application) loginHandler(c *gin.Context) {
-	response := models.NewSuccessResponse(
-		map[string]string{"status": "placeholder"},
-		"Login endpoint - to be implemented in task 2.3",
-	)
+	var req models.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid request body", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validate required fields
+	if req.Username == "" || req.Password == "" {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Username and password are required", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	user, err := app.db.Users().GetByUsername(c.Request.Context(), req.Username)
+	if err != nil && err.Error() != "user not found" {
+		app.logger.Printf("Error getting user: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to login", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	if !app.checkLoginAllowed(c, req.Username, user) {
+		return
+	}
+
+	// Same response for unknown users and wrong passwords to avoid user enumeration
+	if user == nil || !utils.CheckPassword(req.Password, user.PasswordHash) {
+		reason := "invalid_password"
+		if user == nil {
+			reason = "unknown_user"
+		}
+		app.recordLoginFailure(c, req.Username, user, reason)
+
+		response := models.NewErrorResponse(models.ErrCodeAuthentication, "Invalid username or password", nil)
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	if !user.IsActive {
+		app.recordLoginFailure(c, req.Username, user, "deactivated")
+
+		response := models.NewErrorResponse(models.ErrCodeAuthorization, "Account is deactivated", nil)
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	loginResponse, challenge, err := app.beginLogin(c, user)
+	if err != nil {
+		app.logger.Printf("Error issuing session: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to generate token", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	// Two-step login: the session is issued by verifyMFAHandler, which also
+	// clears the failure counter so the second factor cannot be guessed freely
+	if challenge != nil {
+		response := models.NewSuccessResponse(challenge, "Two-factor authentication required")
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	app.resetLoginFailures(c, user.Username)
+
+	if err := app.db.System().LogAction(c.Request.Context(), &user.ID, "LOGIN", "user", user.ID, nil, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
+	response := models.NewSuccessResponse(loginResponse, "Login successful")
 	c.JSON(http.StatusOK, response)
 }
 
 func (app *Application) logoutHandler(c *gin.Context) {
-	response := models.NewSuccessResponse(
-		map[string]string{"status": "placeholder"},
-		"Logout endpoint - to be implemented in task 2.3",
-	)
+	tokenString := extractBearerToken(c)
+	if tokenString == "" {
+		response := models.NewErrorResponse(models.ErrCodeAuthentication, "Missing authorization token", nil)
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	claims, err := app.jwtManager.ValidateToken(tokenString)
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeAuthentication, "Invalid or expired token", nil)
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	// Revoke the token so it cannot be used again before it expires
+	if err := app.db.Tokens().RevokeToken(c.Request.Context(), claims.ID, claims.UserID, claims.ExpiresAt.Time); err != nil {
+		app.logger.Printf("Error revoking token: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to logout", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	// End the refresh-token session the access token belongs to
+	if claims.SessionID != "" {
+		if err := app.db.Sessions().RevokeFamily(c.Request.Context(), claims.SessionID); err != nil {
+			app.logger.Printf("Error revoking session: %v", err)
+			response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to logout", nil)
+			c.JSON(http.StatusInternalServerError, response)
+			return
+		}
+	}
+
+	if err := app.db.System().LogAction(c.Request.Context(), &claims.UserID, "LOGOUT", "user", claims.UserID, nil, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
+	response := models.NewSuccessResponse(nil, "Logout successful")
 	c.JSON(http.StatusOK, response)
 }
 
@@ -276,8 +641,20 @@ func (app *Application) getProjectsHandler(c *gin.Context) {
 
 	offset := (pagination.Page - 1) * pagination.PageSize
 
-	// Get projects from database
-	projects, total, err := app.db.Projects().List(c.Request.Context(), pagination.PageSize, offset)
+	// Admins see every project, everyone else only projects they belong to
+	admin, ok := app.adminAccess(c, "retrieve projects")
+	if !ok {
+		return
+	}
+	var projects []*models.Project
+	var total int
+	var err error
+	claims := currentUser(c)
+	if admin {
+		projects, total, err = app.db.Projects().List(c.Request.Context(), pagination.PageSize, offset)
+	} else {
+		projects, total, err = app.db.Projects().GetByUserID(c.Request.Context(), claims.UserID, pagination.PageSize, offset)
+	}
 	if err != nil {
 		app.logger.Printf("Error getting projects: %v", err)
 		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to retrieve projects", nil)
@@ -326,15 +703,27 @@ func (app *Application) createProjectHandler(c *gin.Context) {
 		return
 	}
 
-	// Create project model (for now, use owner_id = 1 as default)
+	// Create project model owned by the authenticated user
 	project := &models.Project{
 		Name:        req.Name,
 		Description: req.Description,
-		OwnerID:     1, // TODO: Get from authenticated user context
+		OwnerID:     currentUser(c).UserID,
+	}
+	if req.StrictDependencies != nil {
+		project.StrictDependencies = *req.StrictDependencies
+	}
+
+	// Create project and its owner membership in one transaction
+	tx, err := app.db.BeginTx(c.Request.Context())
+	if err != nil {
+		app.logger.Printf("Error starting transaction: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to create project", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
 	}
+	defer tx.Rollback()
 
-	// Create project in database
-	createdProject, err := app.db.Projects().Create(c.Request.Context(), project)
+	createdProject, err := tx.Projects().Create(c.Request.Context(), project)
 	if err != nil {
 		app.logger.Printf("Error creating project: %v", err)
 		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to create project", nil)
@@ -342,6 +731,30 @@ func (app *Application) createProjectHandler(c *gin.Context) {
 		return
 	}
 
+	owner := &models.ProjectMember{
+		ProjectID: createdProject.ID,
+		UserID:    createdProject.OwnerID,
+		Role:      policy.ProjectRoleOwner,
+	}
+	if _, err := tx.Members().Add(c.Request.Context(), owner); err != nil {
+		app.logger.Printf("Error adding project owner: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to create project", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		app.logger.Printf("Error committing project: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to create project", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	entityData := map[string]interface{}{"name": createdProject.Name}
+	if err := app.db.System().LogAction(c.Request.Context(), &project.OwnerID, "CREATE", "project", createdProject.ID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
 	response := models.NewSuccessResponse(createdProject.ToResponse(), "Project created successfully")
 	c.JSON(http.StatusCreated, response)
 }
@@ -355,6 +768,10 @@ func (app *Application) getProjectHandler(c *gin.Context) {
 		return
 	}
 
+	if _, ok := app.authorizeProject(c, projectID, policy.ProjectRoleViewer); !ok {
+		return
+	}
+
 	project, err := app.db.Projects().GetByID(c.Request.Context(), projectID)
 	if err != nil {
 		if err.Error() == "project not found" {
@@ -368,7 +785,22 @@ func (app *Application) getProjectHandler(c *gin.Context) {
 		return
 	}
 
-	response := models.NewSuccessResponse(project.ToResponse(), "Project retrieved successfully")
+	// Task statistics follow the categories of the project's workflow
+	workflow, ok := app.projectWorkflow(c, projectID, "retrieve project")
+	if !ok {
+		return
+	}
+	counts, err := app.db.Tasks().CountByStatus(c.Request.Context(), projectID)
+	if err != nil {
+		app.logger.Printf("Error counting tasks: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to retrieve project", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	projectResponse := project.ToResponse()
+	projectResponse.TaskStats = taskStats(workflow, counts)
+
+	response := models.NewSuccessResponse(projectResponse, "Project retrieved successfully")
 	c.JSON(http.StatusOK, response)
 }
 
@@ -388,6 +820,10 @@ func (app *Application) updateProjectHandler(c *gin.Context) {
 		return
 	}
 
+	if _, ok := app.authorizeProject(c, projectID, policy.ProjectRoleMaintainer); !ok {
+		return
+	}
+
 	// Get existing project
 	existingProject, err := app.db.Projects().GetByID(c.Request.Context(), projectID)
 	if err != nil {
@@ -409,6 +845,9 @@ func (app *Application) updateProjectHandler(c *gin.Context) {
 	if req.Description != "" {
 		existingProject.Description = req.Description
 	}
+	if req.StrictDependencies != nil {
+		existingProject.StrictDependencies = *req.StrictDependencies
+	}
 
 	// Update project in database
 	updatedProject, err := app.db.Projects().Update(c.Request.Context(), existingProject)
@@ -419,6 +858,12 @@ func (app *Application) updateProjectHandler(c *gin.Context) {
 		return
 	}
 
+	claims := currentUser(c)
+	entityData := map[string]interface{}{"name": updatedProject.Name}
+	if err := app.db.System().LogAction(c.Request.Context(), &claims.UserID, "UPDATE", "project", updatedProject.ID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
 	response := models.NewSuccessResponse(updatedProject.ToResponse(), "Project updated successfully")
 	c.JSON(http.StatusOK, response)
 }
@@ -432,6 +877,10 @@ func (app *Application) deleteProjectHandler(c *gin.Context) {
 		return
 	}
 
+	if _, ok := app.authorizeProject(c, projectID, policy.ProjectRoleOwner); !ok {
+		return
+	}
+
 	err = app.db.Projects().Delete(c.Request.Context(), projectID)
 	if err != nil {
 		if err.Error() == "project not found" {
@@ -445,6 +894,11 @@ func (app *Application) deleteProjectHandler(c *gin.Context) {
 		return
 	}
 
+	claims := currentUser(c)
+	if err := app.db.System().LogAction(c.Request.Context(), &claims.UserID, "DELETE", "project", projectID, nil, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
 	response := models.NewSuccessResponse(nil, "Project deleted successfully")
 	c.JSON(http.StatusOK, response)
 }
@@ -476,9 +930,23 @@ func (app *Application) getTasksHandler(c *gin.Context) {
 
 	offset := (pagination.Page - 1) * pagination.PageSize
 
+	filter, ok := bindTaskFilter(c)
+	if !ok {
+		return
+	}
+
+	if _, ok := app.authorizeProject(c, projectID, policy.ProjectRoleViewer); !ok {
+		return
+	}
+
 	// Get tasks from database
-	tasks, total, err := app.db.Tasks().GetByProjectID(c.Request.Context(), projectID, pagination.PageSize, offset)
+	tasks, total, err := app.db.Tasks().List(c.Request.Context(), projectID, filter, pagination.PageSize, offset)
 	if err != nil {
+		if err.Error() == "invalid sort key" {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid sort key", nil)
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
 		app.logger.Printf("Error getting tasks: %v", err)
 		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to retrieve tasks", nil)
 		c.JSON(http.StatusInternalServerError, response)
@@ -534,8 +1002,35 @@ func (app *Application) createTaskHandler(c *gin.Context) {
 		return
 	}
 
+	if msg := validateTaskFields(&req); msg != "" {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, msg, nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if _, ok := app.authorizeProject(c, projectID, policy.ProjectRoleMember); !ok {
+		return
+	}
+
+	// New tasks start in the first todo status of the workflow by default
+	workflow, ok := app.projectWorkflow(c, projectID, "create task")
+	if !ok {
+		return
+	}
 	if req.Status == "" {
-		req.Status = "todo"
+		req.Status = workflow.FirstStatus(models.StatusCategoryTodo)
+	}
+	if !checkStatusChange(c, workflow, "", req.Status) {
+		return
+	}
+
+	fields, ok := app.projectCustomFields(c, projectID, "create task")
+	if !ok {
+		return
+	}
+	req.CustomFields, ok = app.checkTaskCustomFields(c, fields, req.CustomFields, "custom_fields", "create task")
+	if !ok {
+		return
 	}
 
 	// Create task model
@@ -547,6 +1042,58 @@ func (app *Application) createTaskHandler(c *gin.Context) {
 		AssigneeID:   req.AssigneeID,
 		DueDate:      req.DueDate,
 		CustomFields: req.CustomFields,
+		Priority:     "medium",
+		Tags:         []string{},
+	}
+	applyTaskFields(task, &req)
+
+	if req.ParentID != nil {
+		parent, msg, err := checkParentTask(c.Request.Context(), app.db.Tasks(), projectID, *req.ParentID)
+		if err != nil {
+			app.logger.Printf("Error getting parent task: %v", err)
+			response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to create task", nil)
+			c.JSON(http.StatusInternalServerError, response)
+			return
+		}
+		if msg != "" {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, msg, nil)
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+		task.ParentID = &parent.ID
+		task.Level = parent.Level + 1
+	}
+
+	if req.MilestoneID != nil {
+		milestoneID, msg, err := checkTaskMilestone(c.Request.Context(), app.db.Milestones(), projectID, *req.MilestoneID)
+		if err != nil {
+			app.logger.Printf("Error getting milestone: %v", err)
+			response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to create task", nil)
+			c.JSON(http.StatusInternalServerError, response)
+			return
+		}
+		if msg != "" {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, msg, nil)
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+		task.MilestoneID = milestoneID
+	}
+
+	if req.SprintID != nil {
+		sprintID, msg, err := checkTaskSprint(c.Request.Context(), app.db.Sprints(), projectID, *req.SprintID)
+		if err != nil {
+			app.logger.Printf("Error getting sprint: %v", err)
+			response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to create task", nil)
+			c.JSON(http.StatusInternalServerError, response)
+			return
+		}
+		if msg != "" {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, msg, nil)
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+		task.SprintID = sprintID
 	}
 
 	// Create task in database
@@ -558,6 +1105,14 @@ func (app *Application) createTaskHandler(c *gin.Context) {
 		return
 	}
 
+	claims := currentUser(c)
+	if err := recordTasksCreated(c.Request.Context(), app.db.TaskUpdates(), []*models.Task{createdTask}, &claims.UserID, req.Note); err != nil {
+		app.logger.Printf("Error recording task update: %v", err)
+	}
+	if err := app.rollUpTask(c.Request.Context(), app.db.Tasks(), app.db.TaskUpdates(), createdTask.ParentID); err != nil {
+		app.logger.Printf("Error rolling up parent task: %v", err)
+	}
+
 	response := models.NewSuccessResponse(createdTask.ToResponse(), "Task created successfully")
 	c.JSON(http.StatusCreated, response)
 }
@@ -590,8 +1145,44 @@ func (app *Application) bulkImportTasksHandler(c *gin.Context) {
 		return
 	}
 
+	if _, ok := app.authorizeProject(c, projectID, policy.ProjectRoleMember); !ok {
+		return
+	}
+
+	workflow, ok := app.projectWorkflow(c, projectID, "create tasks")
+	if !ok {
+		return
+	}
+
+	fields, ok := app.projectCustomFields(c, projectID, "create tasks")
+	if !ok {
+		return
+	}
+
+	// Validate custom fields of every task before reporting errors
+	var fieldErrors []models.ValidationError
+	for i := range req.Tasks {
+		promoteTaskFields(&req.Tasks[i])
+		values, errs, err := app.validateCustomFields(c.Request.Context(), fields, req.Tasks[i].CustomFields, fmt.Sprintf("tasks[%d].custom_fields", i))
+		if err != nil {
+			app.logger.Printf("Error validating custom fields: %v", err)
+			response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to create tasks", nil)
+			c.JSON(http.StatusInternalServerError, response)
+			return
+		}
+		req.Tasks[i].CustomFields = values
+		fieldErrors = append(fieldErrors, errs...)
+	}
+	if len(fieldErrors) > 0 {
+		c.JSON(http.StatusBadRequest, models.NewValidationErrorResponse(fieldErrors))
+		return
+	}
+
 	// Convert TaskRequest to Task models
 	tasks := make([]*models.Task, len(req.Tasks))
+	parents := make(map[int]*models.Task)
+	milestones := make(map[int]*int)
+	sprints := make(map[int]*int)
 	for i, taskReq := range req.Tasks {
 		if taskReq.Title == "" {
 			response := models.NewErrorResponse(models.ErrCodeBadRequest, fmt.Sprintf("Task %d: title is required", i+1), nil)
@@ -599,7 +1190,18 @@ func (app *Application) bulkImportTasksHandler(c *gin.Context) {
 			return
 		}
 		if taskReq.Status == "" {
-			taskReq.Status = "todo"
+			taskReq.Status = workflow.FirstStatus(models.StatusCategoryTodo)
+		}
+		if workflow.Status(taskReq.Status) == nil {
+			message := fmt.Sprintf("Task %d: status must be one of %s", i+1, strings.Join(statusKeys(workflow), ", "))
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, message, nil)
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+		if msg := validateTaskFields(&taskReq); msg != "" {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, fmt.Sprintf("Task %d: %s", i+1, msg), nil)
+			c.JSON(http.StatusBadRequest, response)
+			return
 		}
 
 		tasks[i] = &models.Task{
@@ -610,6 +1212,73 @@ func (app *Application) bulkImportTasksHandler(c *gin.Context) {
 			AssigneeID:   taskReq.AssigneeID,
 			DueDate:      taskReq.DueDate,
 			CustomFields: taskReq.CustomFields,
+			Priority:     "medium",
+			Tags:         []string{},
+		}
+		applyTaskFields(tasks[i], &taskReq)
+
+		if taskReq.ParentID != nil {
+			parent, ok := parents[*taskReq.ParentID]
+			if !ok {
+				var msg string
+				parent, msg, err = checkParentTask(c.Request.Context(), app.db.Tasks(), projectID, *taskReq.ParentID)
+				if err != nil {
+					app.logger.Printf("Error getting parent task: %v", err)
+					response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to create tasks", nil)
+					c.JSON(http.StatusInternalServerError, response)
+					return
+				}
+				if msg != "" {
+					response := models.NewErrorResponse(models.ErrCodeBadRequest, fmt.Sprintf("Task %d: %s", i+1, msg), nil)
+					c.JSON(http.StatusBadRequest, response)
+					return
+				}
+				parents[parent.ID] = parent
+			}
+			tasks[i].ParentID = &parent.ID
+			tasks[i].Level = parent.Level + 1
+		}
+
+		if taskReq.MilestoneID != nil {
+			milestoneID, ok := milestones[*taskReq.MilestoneID]
+			if !ok {
+				var msg string
+				milestoneID, msg, err = checkTaskMilestone(c.Request.Context(), app.db.Milestones(), projectID, *taskReq.MilestoneID)
+				if err != nil {
+					app.logger.Printf("Error getting milestone: %v", err)
+					response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to create tasks", nil)
+					c.JSON(http.StatusInternalServerError, response)
+					return
+				}
+				if msg != "" {
+					response := models.NewErrorResponse(models.ErrCodeBadRequest, fmt.Sprintf("Task %d: %s", i+1, msg), nil)
+					c.JSON(http.StatusBadRequest, response)
+					return
+				}
+				milestones[*taskReq.MilestoneID] = milestoneID
+			}
+			tasks[i].MilestoneID = milestoneID
+		}
+
+		if taskReq.SprintID != nil {
+			sprintID, ok := sprints[*taskReq.SprintID]
+			if !ok {
+				var msg string
+				sprintID, msg, err = checkTaskSprint(c.Request.Context(), app.db.Sprints(), projectID, *taskReq.SprintID)
+				if err != nil {
+					app.logger.Printf("Error getting sprint: %v", err)
+					response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to create tasks", nil)
+					c.JSON(http.StatusInternalServerError, response)
+					return
+				}
+				if msg != "" {
+					response := models.NewErrorResponse(models.ErrCodeBadRequest, fmt.Sprintf("Task %d: %s", i+1, msg), nil)
+					c.JSON(http.StatusBadRequest, response)
+					return
+				}
+				sprints[*taskReq.SprintID] = sprintID
+			}
+			tasks[i].SprintID = sprintID
 		}
 	}
 
@@ -622,6 +1291,16 @@ func (app *Application) bulkImportTasksHandler(c *gin.Context) {
 		return
 	}
 
+	claims := currentUser(c)
+	if err := recordTasksCreated(c.Request.Context(), app.db.TaskUpdates(), createdTasks, &claims.UserID, ""); err != nil {
+		app.logger.Printf("Error recording task update: %v", err)
+	}
+	for parentID := range parents {
+		if err := app.rollUpTask(c.Request.Context(), app.db.Tasks(), app.db.TaskUpdates(), &parentID); err != nil {
+			app.logger.Printf("Error rolling up parent task: %v", err)
+		}
+	}
+
 	// Prepare response
 	importedIDs := make([]int, len(createdTasks))
 	for i, task := range createdTasks {
@@ -640,6 +1319,14 @@ func (app *Application) bulkImportTasksHandler(c *gin.Context) {
 }
 
 func (app *Application) getTaskHandler(c *gin.Context) {
+	projectIDStr := c.Param("id")
+	projectID, err := strconv.Atoi(projectIDStr)
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid project ID", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
 	taskIDStr := c.Param("taskId")
 	taskID, err := strconv.Atoi(taskIDStr)
 	if err != nil {
@@ -648,7 +1335,14 @@ func (app *Application) getTaskHandler(c *gin.Context) {
 		return
 	}
 
+	if _, ok := app.authorizeProject(c, projectID, policy.ProjectRoleViewer); !ok {
+		return
+	}
+
 	task, err := app.db.Tasks().GetByID(c.Request.Context(), taskID)
+	if err == nil && task.ProjectID != projectID {
+		err = fmt.Errorf("task not found")
+	}
 	if err != nil {
 		if err.Error() == "task not found" {
 			response := models.NewErrorResponse(models.ErrCodeNotFound, "Task not found", nil)
@@ -666,6 +1360,14 @@ func (app *Application) getTaskHandler(c *gin.Context) {
 }
 
 func (app *Application) updateTaskHandler(c *gin.Context) {
+	projectIDStr := c.Param("id")
+	projectID, err := strconv.Atoi(projectIDStr)
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid project ID", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
 	taskIDStr := c.Param("taskId")
 	taskID, err := strconv.Atoi(taskIDStr)
 	if err != nil {
@@ -681,8 +1383,21 @@ func (app *Application) updateTaskHandler(c *gin.Context) {
 		return
 	}
 
+	if msg := validateTaskFields(&req); msg != "" {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, msg, nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if _, ok := app.authorizeProject(c, projectID, policy.ProjectRoleMember); !ok {
+		return
+	}
+
 	// Get existing task
 	existingTask, err := app.db.Tasks().GetByID(c.Request.Context(), taskID)
+	if err == nil && existingTask.ProjectID != projectID {
+		err = fmt.Errorf("task not found")
+	}
 	if err != nil {
 		if err.Error() == "task not found" {
 			response := models.NewErrorResponse(models.ErrCodeNotFound, "Task not found", nil)
@@ -695,6 +1410,86 @@ func (app *Application) updateTaskHandler(c *gin.Context) {
 		return
 	}
 
+	var warnings []string
+	if req.Status != "" {
+		workflow, ok := app.projectWorkflow(c, projectID, "update task")
+		if !ok {
+			return
+		}
+		if !checkStatusChange(c, workflow, existingTask.Status, req.Status) {
+			return
+		}
+		warnings, ok = app.checkBlockers(c, workflow, existingTask, req.Status)
+		if !ok {
+			return
+		}
+	}
+
+	// Validate the remaining fields before the move, which commits on its own
+	var customFields models.CustomFields
+	if req.CustomFields != nil {
+		fields, ok := app.projectCustomFields(c, projectID, "update task")
+		if !ok {
+			return
+		}
+		customFields, ok = app.checkTaskCustomFields(c, fields, req.CustomFields, "custom_fields", "update task")
+		if !ok {
+			return
+		}
+	}
+	var milestoneID *int
+	if req.MilestoneID != nil {
+		var msg string
+		milestoneID, msg, err = checkTaskMilestone(c.Request.Context(), app.db.Milestones(), projectID, *req.MilestoneID)
+		if err != nil {
+			app.logger.Printf("Error getting milestone: %v", err)
+			response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to update task", nil)
+			c.JSON(http.StatusInternalServerError, response)
+			return
+		}
+		if msg != "" {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, msg, nil)
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+	}
+	var sprintID *int
+	if req.SprintID != nil {
+		var msg string
+		sprintID, msg, err = checkTaskSprint(c.Request.Context(), app.db.Sprints(), projectID, *req.SprintID)
+		if err != nil {
+			app.logger.Printf("Error getting sprint: %v", err)
+			response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to update task", nil)
+			c.JSON(http.StatusInternalServerError, response)
+			return
+		}
+		if msg != "" {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, msg, nil)
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+	}
+
+	before := cloneTask(existingTask)
+
+	// The move and the field update commit together, so a failed update
+	// leaves the task where it was
+	tx, err := app.db.BeginTx(c.Request.Context())
+	if err != nil {
+		app.logger.Printf("Error starting transaction: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to update task", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	defer tx.Rollback()
+
+	// Move the task when a different parent is given
+	if req.ParentID != nil && (existingTask.ParentID == nil || *existingTask.ParentID != *req.ParentID) {
+		if !app.moveTaskTx(c, tx, existingTask, req.ParentID) {
+			return
+		}
+	}
+
 	// Update task fields
 	if req.Title != "" {
 		existingTask.Title = req.Title
@@ -712,11 +1507,18 @@ func (app *Application) updateTaskHandler(c *gin.Context) {
 		existingTask.DueDate = req.DueDate
 	}
 	if req.CustomFields != nil {
-		existingTask.CustomFields = req.CustomFields
+		existingTask.CustomFields = customFields
+	}
+	if req.MilestoneID != nil {
+		existingTask.MilestoneID = milestoneID
+	}
+	if req.SprintID != nil {
+		existingTask.SprintID = sprintID
 	}
+	applyTaskFields(existingTask, &req)
 
 	// Update task in database
-	updatedTask, err := app.db.Tasks().Update(c.Request.Context(), existingTask)
+	updatedTask, err := tx.Tasks().Update(c.Request.Context(), existingTask)
 	if err != nil {
 		app.logger.Printf("Error updating task: %v", err)
 		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to update task", nil)
@@ -724,11 +1526,35 @@ func (app *Application) updateTaskHandler(c *gin.Context) {
 		return
 	}
 
+	if err := tx.Commit(); err != nil {
+		app.logger.Printf("Error committing transaction: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to update task", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	claims := currentUser(c)
+	if err := recordTaskChanges(c.Request.Context(), app.db.TaskUpdates(), before, updatedTask, &claims.UserID, req.Note); err != nil {
+		app.logger.Printf("Error recording task update: %v", err)
+	}
+	if err := app.rollUpTask(c.Request.Context(), app.db.Tasks(), app.db.TaskUpdates(), updatedTask.ParentID); err != nil {
+		app.logger.Printf("Error rolling up parent task: %v", err)
+	}
+
 	response := models.NewSuccessResponse(updatedTask.ToResponse(), "Task updated successfully")
+	response.Warnings = warnings
 	c.JSON(http.StatusOK, response)
 }
 
 func (app *Application) deleteTaskHandler(c *gin.Context) {
+	projectIDStr := c.Param("id")
+	projectID, err := strconv.Atoi(projectIDStr)
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid project ID", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
 	taskIDStr := c.Param("taskId")
 	taskID, err := strconv.Atoi(taskIDStr)
 	if err != nil {
@@ -737,7 +1563,18 @@ func (app *Application) deleteTaskHandler(c *gin.Context) {
 		return
 	}
 
-	err = app.db.Tasks().Delete(c.Request.Context(), taskID)
+	if _, ok := app.authorizeProject(c, projectID, policy.ProjectRoleMember); !ok {
+		return
+	}
+
+	// Make sure the task belongs to the project in the URL
+	task, err := app.db.Tasks().GetByID(c.Request.Context(), taskID)
+	if err == nil && task.ProjectID != projectID {
+		err = fmt.Errorf("task not found")
+	}
+	if err == nil {
+		err = app.db.Tasks().Delete(c.Request.Context(), taskID)
+	}
 	if err != nil {
 		if err.Error() == "task not found" {
 			response := models.NewErrorResponse(models.ErrCodeNotFound, "Task not found", nil)
@@ -750,6 +1587,16 @@ func (app *Application) deleteTaskHandler(c *gin.Context) {
 		return
 	}
 
+	if err := app.rollUpTask(c.Request.Context(), app.db.Tasks(), app.db.TaskUpdates(), task.ParentID); err != nil {
+		app.logger.Printf("Error rolling up parent task: %v", err)
+	}
+
+	claims := currentUser(c)
+	entityData := map[string]interface{}{"project_id": task.ProjectID, "title": task.Title}
+	if err := app.db.System().LogAction(c.Request.Context(), &claims.UserID, "DELETE", "task", task.ID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
 	response := models.NewSuccessResponse(nil, "Task deleted successfully")
 	c.JSON(http.StatusOK, response)
 }
@@ -813,6 +1660,11 @@ func (app *Application) restoreProjectHandler(c *gin.Context) {
 		return
 	}
 
+	claims := currentUser(c)
+	if err := app.db.System().LogAction(c.Request.Context(), &claims.UserID, "RESTORE", "project", projectID, nil, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
 	response := models.NewSuccessResponse(nil, "Project restored successfully")
 	c.JSON(http.StatusOK, response)
 }
@@ -894,12 +1746,32 @@ func (app *Application) restoreTaskHandler(c *gin.Context) {
 			c.JSON(http.StatusNotFound, response)
 			return
 		}
+		if err.Error() == "parent task is deleted" {
+			response := models.NewErrorResponse(models.ErrCodeConflict, "Cannot restore task: parent task is deleted", nil)
+			c.JSON(http.StatusConflict, response)
+			return
+		}
 		app.logger.Printf("Error restoring task: %v", err)
 		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to restore task", nil)
 		c.JSON(http.StatusInternalServerError, response)
 		return
 	}
 
+	// The restored subtree counts towards its parent again
+	entityData := map[string]interface{}{}
+	if task, err := app.db.Tasks().GetByID(c.Request.Context(), taskID); err == nil {
+		entityData["project_id"] = task.ProjectID
+		entityData["title"] = task.Title
+		if err := app.rollUpTask(c.Request.Context(), app.db.Tasks(), app.db.TaskUpdates(), task.ParentID); err != nil {
+			app.logger.Printf("Error rolling up parent task: %v", err)
+		}
+	}
+
+	claims := currentUser(c)
+	if err := app.db.System().LogAction(c.Request.Context(), &claims.UserID, "RESTORE", "task", taskID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
 	response := models.NewSuccessResponse(nil, "Task restored successfully")
 	c.JSON(http.StatusOK, response)
 }
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"ai-project-backend/models"
+	"ai-project-backend/policy"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// adminAccess reports whether the caller may act as a system admin across
+// all projects. Admins whose session has not passed a second factor that the
+// security policy requires are treated as regular users. It writes the error
+// response and returns false when the check fails.
+func (app *Application) adminAccess(c *gin.Context, action string) (bool, bool) {
+	claims := currentUser(c)
+	if claims.Role != policy.RoleAdmin {
+		return false, true
+	}
+
+	missing, err := app.secondFactorMissing(c.Request.Context(), claims)
+	if err != nil {
+		app.logger.Printf("Error getting security settings: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to "+action, nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return false, false
+	}
+	return !missing, true
+}
+
+// authorizeProject checks that the caller holds at least the required role in the project.
+// It writes the error response and returns false when access is denied.
+// System admins are treated as project owners, unless the security policy
+// requires a second factor their session has not passed.
+func (app *Application) authorizeProject(c *gin.Context, projectID int, required string) (string, bool) {
+	claims := currentUser(c)
+	if claims == nil {
+		response := models.NewErrorResponse(models.ErrCodeAuthentication, "Authentication required", nil)
+		c.JSON(http.StatusUnauthorized, response)
+		return "", false
+	}
+
+	role, err := app.db.Members().GetRole(c.Request.Context(), projectID, claims.UserID)
+	if err != nil {
+		if err.Error() == "project not found" {
+			response := models.NewErrorResponse(models.ErrCodeNotFound, "Project not found", nil)
+			c.JSON(http.StatusNotFound, response)
+			return "", false
+		}
+		app.logger.Printf("Error getting project role: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to verify project access", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return "", false
+	}
+
+	admin, ok := app.adminAccess(c, "verify project access")
+	if !ok {
+		return "", false
+	}
+	if admin {
+		return policy.ProjectRoleOwner, true
+	}
+
+	if role == "" {
+		response := models.NewErrorResponse(models.ErrCodeAuthorization, "You are not a member of this project", nil)
+		c.JSON(http.StatusForbidden, response)
+		return "", false
+	}
+
+	if !policy.HasProjectRole(role, required) {
+		response := models.NewErrorResponse(
+			models.ErrCodeAuthorization,
+			"Insufficient project role",
+			map[string]string{"role": role, "required_role": required},
+		)
+		c.JSON(http.StatusForbidden, response)
+		return "", false
+	}
+
+	return role, true
+}
+
+func (app *Application) getProjectMembersHandler(c *gin.Context) {
+	projectIDStr := c.Param("id")
+	projectID, err := strconv.Atoi(projectIDStr)
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid project ID", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if _, ok := app.authorizeProject(c, projectID, policy.ProjectRoleViewer); !ok {
+		return
+	}
+
+	members, err := app.db.Members().List(c.Request.Context(), projectID)
+	if err != nil {
+		app.logger.Printf("Error getting project members: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to retrieve project members", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := models.NewSuccessResponse(members, "Project members retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) addProjectMemberHandler(c *gin.Context) {
+	projectIDStr := c.Param("id")
+	projectID, err := strconv.Atoi(projectIDStr)
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid project ID", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	var req models.ProjectMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid request body", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if req.UserID == 0 {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "User ID is required", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	if req.Role == "" {
+		req.Role = policy.ProjectRoleMember
+	}
+	if !policy.IsValidProjectRole(req.Role) {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid project role", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	actorRole, ok := app.authorizeProject(c, projectID, policy.ProjectRoleMaintainer)
+	if !ok {
+		return
+	}
+	if !policy.CanManageMember(actorRole, "", req.Role) {
+		response := models.NewErrorResponse(models.ErrCodeAuthorization, "You cannot grant this role", nil)
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	if _, err := app.db.Users().GetByID(c.Request.Context(), req.UserID); err != nil {
+		if err.Error() == "user not found" {
+			response := models.NewErrorResponse(models.ErrCodeNotFound, "User not found", nil)
+			c.JSON(http.StatusNotFound, response)
+			return
+		}
+		app.logger.Printf("Error getting user: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to add project member", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	member := &models.ProjectMember{
+		ProjectID: projectID,
+		UserID:    req.UserID,
+		Role:      req.Role,
+	}
+
+	if _, err := app.db.Members().Add(c.Request.Context(), member); err != nil {
+		if err.Error() == "member already exists" {
+			response := models.NewErrorResponse(models.ErrCodeConflict, "User is already a member of this project", nil)
+			c.JSON(http.StatusConflict, response)
+			return
+		}
+		app.logger.Printf("Error adding project member: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to add project member", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	createdMember, err := app.db.Members().Get(c.Request.Context(), projectID, req.UserID)
+	if err != nil {
+		app.logger.Printf("Error getting project member: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to retrieve project member", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := models.NewSuccessResponse(createdMember, "Project member added successfully")
+	c.JSON(http.StatusCreated, response)
+}
+
+func (app *Application) updateProjectMemberHandler(c *gin.Context) {
+	projectID, userID, ok := parseMemberParams(c)
+	if !ok {
+		return
+	}
+
+	var req models.ProjectMemberRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid request body", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	if !policy.IsValidProjectRole(req.Role) {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid project role", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	actorRole, ok := app.authorizeProject(c, projectID, policy.ProjectRoleMaintainer)
+	if !ok {
+		return
+	}
+
+	member, ok := app.loadManagedMember(c, projectID, userID)
+	if !ok {
+		return
+	}
+	if !policy.CanManageMember(actorRole, member.Role, req.Role) {
+		response := models.NewErrorResponse(models.ErrCodeAuthorization, "You cannot change this member's role", nil)
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	if err := app.db.Members().UpdateRole(c.Request.Context(), projectID, userID, req.Role); err != nil {
+		app.logger.Printf("Error updating project member: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to update project member", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	member.Role = req.Role
+	response := models.NewSuccessResponse(member, "Project member updated successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) removeProjectMemberHandler(c *gin.Context) {
+	projectID, userID, ok := parseMemberParams(c)
+	if !ok {
+		return
+	}
+
+	actorRole, ok := app.authorizeProject(c, projectID, policy.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+
+	member, ok := app.loadManagedMember(c, projectID, userID)
+	if !ok {
+		return
+	}
+
+	// Anyone may leave a project; removing others requires a managing role
+	if userID != currentUser(c).UserID && !policy.CanManageMember(actorRole, member.Role, "") {
+		response := models.NewErrorResponse(models.ErrCodeAuthorization, "You cannot remove this member", nil)
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	if err := app.db.Members().Remove(c.Request.Context(), projectID, userID); err != nil {
+		app.logger.Printf("Error removing project member: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to remove project member", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := models.NewSuccessResponse(nil, "Project member removed successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// parseMemberParams parses the project and user IDs of member routes
+func parseMemberParams(c *gin.Context) (int, int, bool) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid project ID", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return 0, 0, false
+	}
+
+	userID, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid user ID", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return 0, 0, false
+	}
+
+	return projectID, userID, true
+}
+
+// loadManagedMember loads a member that is about to be changed or removed.
+// The project's owner_id user cannot be changed, so the project always keeps an owner.
+func (app *Application) loadManagedMember(c *gin.Context, projectID, userID int) (*models.ProjectMember, bool) {
+	project, err := app.db.Projects().GetByID(c.Request.Context(), projectID)
+	if err != nil {
+		app.logger.Printf("Error getting project: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to retrieve project", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return nil, false
+	}
+	if project.OwnerID == userID {
+		response := models.NewErrorResponse(models.ErrCodeConflict, "The project owner's membership cannot be changed", nil)
+		c.JSON(http.StatusConflict, response)
+		return nil, false
+	}
+
+	member, err := app.db.Members().Get(c.Request.Context(), projectID, userID)
+	if err != nil {
+		if err.Error() == "member not found" {
+			response := models.NewErrorResponse(models.ErrCodeNotFound, "Member not found", nil)
+			c.JSON(http.StatusNotFound, response)
+			return nil, false
+		}
+		app.logger.Printf("Error getting project member: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to retrieve project member", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return nil, false
+	}
+
+	return member, true
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"ai-project-backend/database"
+	"ai-project-backend/models"
+	"ai-project-backend/policy"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+// validMilestoneStatuses lists the statuses allowed by chk_milestones_status
+var validMilestoneStatuses = map[string]bool{
+	models.MilestoneOpen:      true,
+	models.MilestoneCompleted: true,
+	models.MilestoneCancelled: true,
+}
+
+// checkTaskMilestone resolves the milestone a task is assigned to. 0 removes
+// the task from its milestone and yields nil. It returns a client error
+// message when the milestone is missing or belongs to another project.
+func checkTaskMilestone(ctx context.Context, milestones database.MilestoneRepository, projectID, milestoneID int) (*int, string, error) {
+	if milestoneID == 0 {
+		return nil, "", nil
+	}
+
+	milestone, err := milestones.GetByID(ctx, milestoneID)
+	if err != nil {
+		if err.Error() == "milestone not found" {
+			return nil, "Milestone not found", nil
+		}
+		return nil, "", err
+	}
+	if milestone.ProjectID != projectID {
+		return nil, "Milestone not found", nil
+	}
+
+	return &milestone.ID, "", nil
+}
+
+// applyMilestoneRequest copies the fields given in a request onto a
+// milestone and validates the result, returning an error message or "" when
+// it is valid
+func applyMilestoneRequest(milestone *models.Milestone, req *models.MilestoneRequest) string {
+	if req.Name != "" {
+		milestone.Name = strings.TrimSpace(req.Name)
+	}
+	if req.Description != nil {
+		milestone.Description = *req.Description
+	}
+	if req.TargetDate != "" {
+		targetDate, err := time.Parse("2006-01-02", req.TargetDate)
+		if err != nil {
+			return "Target date must be a date (YYYY-MM-DD)"
+		}
+		milestone.TargetDate = targetDate
+	}
+	if req.Status != "" {
+		milestone.Status = req.Status
+	}
+
+	if milestone.Name == "" || utf8.RuneCountInString(milestone.Name) > 255 {
+		return "Name must be 1-255 characters"
+	}
+	if milestone.TargetDate.IsZero() {
+		return "Target date is required"
+	}
+	if !validMilestoneStatuses[milestone.Status] {
+		return "Status must be one of open, completed, cancelled"
+	}
+
+	return ""
+}
+
+// loadMilestoneParam loads the milestone named by the route after checking
+// the caller's project role. It writes the error response and returns false
+// on failure.
+func (app *Application) loadMilestoneParam(c *gin.Context, required string) (*models.Milestone, bool) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid project ID", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return nil, false
+	}
+
+	milestoneID, err := strconv.Atoi(c.Param("milestoneId"))
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid milestone ID", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return nil, false
+	}
+
+	if _, ok := app.authorizeProject(c, projectID, required); !ok {
+		return nil, false
+	}
+
+	milestone, err := app.db.Milestones().GetByID(c.Request.Context(), milestoneID)
+	if err == nil && milestone.ProjectID != projectID {
+		err = fmt.Errorf("milestone not found")
+	}
+	if err != nil {
+		if err.Error() == "milestone not found" {
+			response := models.NewErrorResponse(models.ErrCodeNotFound, "Milestone not found", nil)
+			c.JSON(http.StatusNotFound, response)
+			return nil, false
+		}
+		app.logger.Printf("Error getting milestone: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to retrieve milestone", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return nil, false
+	}
+
+	return milestone, true
+}
+
+func (app *Application) getMilestonesHandler(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid project ID", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if _, ok := app.authorizeProject(c, projectID, policy.ProjectRoleViewer); !ok {
+		return
+	}
+
+	milestones, err := app.db.Milestones().List(c.Request.Context(), projectID)
+	if err != nil {
+		app.logger.Printf("Error getting milestones: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to retrieve milestones", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := models.NewSuccessResponse(milestones, "Milestones retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) getMilestoneHandler(c *gin.Context) {
+	milestone, ok := app.loadMilestoneParam(c, policy.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+
+	response := models.NewSuccessResponse(milestone, "Milestone retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) createMilestoneHandler(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid project ID", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	var req models.MilestoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid request body", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	claims := currentUser(c)
+	milestone := &models.Milestone{
+		ProjectID: projectID,
+		Status:    models.MilestoneOpen,
+		CreatedBy: &claims.UserID,
+	}
+	if msg := applyMilestoneRequest(milestone, &req); msg != "" {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, msg, nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if _, ok := app.authorizeProject(c, projectID, policy.ProjectRoleMaintainer); !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	created, err := app.db.Milestones().Create(ctx, milestone)
+	if err != nil {
+		app.logger.Printf("Error creating milestone: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to create milestone", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	entityData := map[string]interface{}{"project_id": created.ProjectID, "name": created.Name, "status": created.Status}
+	if err := app.db.System().LogAction(ctx, &claims.UserID, "CREATE", "milestone", created.ID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
+	// Reload to fill in the progress
+	if reloaded, err := app.db.Milestones().GetByID(ctx, created.ID); err == nil {
+		created = reloaded
+	} else {
+		app.logger.Printf("Error getting milestone: %v", err)
+	}
+
+	response := models.NewSuccessResponse(created, "Milestone created successfully")
+	c.JSON(http.StatusCreated, response)
+}
+
+func (app *Application) updateMilestoneHandler(c *gin.Context) {
+	var req models.MilestoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid request body", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	milestone, ok := app.loadMilestoneParam(c, policy.ProjectRoleMaintainer)
+	if !ok {
+		return
+	}
+	oldStatus := milestone.Status
+
+	if msg := applyMilestoneRequest(milestone, &req); msg != "" {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, msg, nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	ctx := c.Request.Context()
+	updated, err := app.db.Milestones().Update(ctx, milestone)
+	if err != nil {
+		if err.Error() == "milestone not found" {
+			response := models.NewErrorResponse(models.ErrCodeNotFound, "Milestone not found", nil)
+			c.JSON(http.StatusNotFound, response)
+			return
+		}
+		app.logger.Printf("Error updating milestone: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to update milestone", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	// The old status lets the timeline tell when a milestone was completed
+	claims := currentUser(c)
+	entityData := map[string]interface{}{"project_id": updated.ProjectID, "name": updated.Name, "status": updated.Status, "old_status": oldStatus}
+	if err := app.db.System().LogAction(ctx, &claims.UserID, "UPDATE", "milestone", updated.ID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
+	if reloaded, err := app.db.Milestones().GetByID(ctx, updated.ID); err == nil {
+		updated = reloaded
+	} else {
+		app.logger.Printf("Error getting milestone: %v", err)
+	}
+
+	response := models.NewSuccessResponse(updated, "Milestone updated successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) deleteMilestoneHandler(c *gin.Context) {
+	milestone, ok := app.loadMilestoneParam(c, policy.ProjectRoleMaintainer)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+
+	tx, err := app.db.BeginTx(ctx)
+	if err != nil {
+		app.logger.Printf("Error starting transaction: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to delete milestone", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	defer tx.Rollback()
+
+	tasks, err := tx.Tasks().GetAllByProjectID(ctx, milestone.ProjectID)
+	if err != nil {
+		app.logger.Printf("Error getting tasks: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to delete milestone", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	if err := tx.Milestones().Delete(ctx, milestone.ID); err != nil {
+		app.logger.Printf("Error deleting milestone: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to delete milestone", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	// The foreign key detaches the tasks; record it in their history
+	claims := currentUser(c)
+	detached := 0
+	note := "Milestone " + milestone.Name + " deleted"
+	for _, task := range tasks {
+		if task.MilestoneID == nil || *task.MilestoneID != milestone.ID {
+			continue
+		}
+		before := cloneTask(task)
+		task.MilestoneID = nil
+		if err := recordTaskChanges(ctx, tx.TaskUpdates(), before, task, &claims.UserID, note); err != nil {
+			app.logger.Printf("Error recording task update: %v", err)
+			response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to delete milestone", nil)
+			c.JSON(http.StatusInternalServerError, response)
+			return
+		}
+		detached++
+	}
+
+	if err := tx.Commit(); err != nil {
+		app.logger.Printf("Error committing transaction: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to delete milestone", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	entityData := map[string]interface{}{"project_id": milestone.ProjectID, "name": milestone.Name, "detached_tasks": detached}
+	if err := app.db.System().LogAction(ctx, &claims.UserID, "DELETE", "milestone", milestone.ID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
+	response := models.NewSuccessResponse(nil, "Milestone deleted successfully")
+	c.JSON(http.StatusOK, response)
+}
\ No newline at end of file
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Custom field types
+const (
+	CustomFieldString = "string"
+	CustomFieldNumber = "number"
+	CustomFieldBool   = "bool"
+	CustomFieldDate   = "date"
+	CustomFieldEnum   = "enum"
+	CustomFieldUser   = "user"
+)
+
+// Ways to handle task values that cannot be migrated to a changed field
+const (
+	CustomFieldOnInvalidReject  = "reject"
+	CustomFieldOnInvalidClear   = "clear"
+	CustomFieldOnInvalidDefault = "default"
+)
+
+// CustomFieldDefinition types one custom field of a project's tasks.
+// Dates are stored as YYYY-MM-DD strings and users as user IDs.
+type CustomFieldDefinition struct {
+	ID           int         `json:"id" db:"id"`
+	ProjectID    int         `json:"project_id" db:"project_id"`
+	Name         string      `json:"name" db:"name" validate:"required,max=100"`
+	Type         string      `json:"type" db:"field_type" validate:"required,oneof=string number bool date enum user"`
+	Required     bool        `json:"required" db:"required"`
+	DefaultValue interface{} `json:"default_value" db:"default_value"`
+	Options      []string    `json:"options" db:"options"`
+	CreatedAt    time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at" db:"updated_at"`
+}
+
+// CustomFieldDefinitionRequest represents a custom field creation/update
+// request. On update, omitted fields are unchanged and a null default_value
+// removes the default.
+type CustomFieldDefinitionRequest struct {
+	Name         string                `json:"name"`
+	Type         string                `json:"type"`
+	Required     *bool                 `json:"required"`
+	DefaultValue json.RawMessage       `json:"default_value"`
+	Options      []string              `json:"options"`
+	Migration    *CustomFieldMigration `json:"migration"`
+}
+
+// CustomFieldMigration controls how existing task values follow a changed
+// field. A renamed field moves its values to the new key, and values are
+// converted to a new type where possible. ValueMap replaces values first,
+// keyed by their text form (e.g. {"3": "high"}). OnInvalid decides what
+// happens to values that still do not fit: reject the change (default),
+// clear them or use the field's default.
+type CustomFieldMigration struct {
+	ValueMap  map[string]interface{} `json:"value_map"`
+	OnInvalid string                 `json:"on_invalid" validate:"omitempty,oneof=reject clear default"`
+}
+
+// CustomFieldUpdateResponse represents a changed field and the number of
+// tasks whose values were migrated
+type CustomFieldUpdateResponse struct {
+	Field         *CustomFieldDefinition `json:"field"`
+	MigratedTasks int                    `json:"migrated_tasks"`
+}
\ No newline at end of file
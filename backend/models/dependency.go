@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+)
+
+// Dependency types
+const (
+	DependencyFinishToStart = "FS"
+	DependencyStartToStart  = "SS"
+)
+
+// TaskDependency makes a task wait for another task of the same project.
+// A finish-to-start dependency holds the task until DependsOnID is done; a
+// start-to-start dependency holds it until DependsOnID has started.
+type TaskDependency struct {
+	ID          int       `json:"id" db:"id"`
+	TaskID      int       `json:"task_id" db:"task_id"`
+	DependsOnID int       `json:"depends_on_id" db:"depends_on_id"`
+	Type        string    `json:"type" db:"dependency_type" validate:"oneof=FS SS"`
+	CreatedBy   *int      `json:"created_by" db:"created_by"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// TaskDependencyRequest represents a request to add a dependency to a task.
+// Type defaults to FS.
+type TaskDependencyRequest struct {
+	DependsOnID int    `json:"depends_on_id" validate:"required"`
+	Type        string `json:"type" validate:"omitempty,oneof=FS SS"`
+}
+
+// DependencyLink is one dependency seen from a task, with the task at the
+// other end
+type DependencyLink struct {
+	ID     int    `json:"id"`
+	Type   string `json:"type"`
+	TaskID int    `json:"task_id"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+}
+
+// TaskDependencies lists the tasks a task waits for and the tasks waiting
+// for it. Deleted tasks are left out.
+type TaskDependencies struct {
+	BlockedBy []*DependencyLink `json:"blocked_by"`
+	Blocks    []*DependencyLink `json:"blocks"`
+}
\ No newline at end of file
@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// Gantt bar types
+const (
+	GanttBarTask      = "task"
+	GanttBarSummary   = "summary"
+	GanttBarMilestone = "milestone"
+)
+
+// GanttBar is one row of a Gantt chart. Bars are listed depth first, so
+// every bar follows its parent. Start and End are the first and last days
+// the task is scheduled to run when it starts as early as possible.
+type GanttBar struct {
+	ID                int        `json:"id"`
+	ParentID          *int       `json:"parent_id"`
+	Level             int        `json:"level"`
+	Title             string     `json:"title"`
+	Type              string     `json:"type"`
+	Status            string     `json:"status"`
+	Category          string     `json:"category"`
+	AssigneeID        *int       `json:"assignee_id"`
+	MilestoneID       *int       `json:"milestone_id"`
+	Start             time.Time  `json:"start"`
+	End               time.Time  `json:"end"`
+	DueDate           *time.Time `json:"due_date"`
+	Progress          int        `json:"progress"`
+	Critical          bool       `json:"critical"`
+	SlackDays         float64    `json:"slack_days"`
+	DueDateInfeasible bool       `json:"due_date_infeasible"`
+}
+
+// GanttLink draws a dependency from the blocking task Source to Target
+type GanttLink struct {
+	ID     int    `json:"id"`
+	Source int    `json:"source"`
+	Target int    `json:"target"`
+	Type   string `json:"type"`
+}
+
+// GanttMilestone marks a date on a Gantt chart. Project milestones are
+// drawn at their target date, with their progress. Tasks planned with zero
+// estimated hours are milestones too; their date is the due date when set.
+type GanttMilestone struct {
+	MilestoneID *int      `json:"milestone_id,omitempty"`
+	TaskID      *int      `json:"task_id,omitempty"`
+	Title       string    `json:"title"`
+	Date        time.Time `json:"date"`
+	Done        bool      `json:"done"`
+	Progress    float64   `json:"progress"`
+}
+
+// GanttChart holds everything needed to draw a project's Gantt chart
+type GanttChart struct {
+	ProjectID  int               `json:"project_id"`
+	Start      time.Time         `json:"start"`
+	Finish     time.Time         `json:"finish"`
+	Bars       []*GanttBar       `json:"bars"`
+	Links      []*GanttLink      `json:"links"`
+	Milestones []*GanttMilestone `json:"milestones"`
+}
\ No newline at end of file
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// Milestone statuses
+const (
+	MilestoneOpen      = "open"
+	MilestoneCompleted = "completed"
+	MilestoneCancelled = "cancelled"
+)
+
+// Milestone marks a release or delivery point of a project. Tasks are
+// linked to it through their milestone_id.
+type Milestone struct {
+	ID          int                `json:"id" db:"id"`
+	ProjectID   int                `json:"project_id" db:"project_id"`
+	Name        string             `json:"name" db:"name" validate:"required,max=255"`
+	Description string             `json:"description" db:"description"`
+	TargetDate  time.Time          `json:"target_date" db:"target_date"`
+	Status      string             `json:"status" db:"status" validate:"oneof=open completed cancelled"`
+	CreatedBy   *int               `json:"created_by" db:"created_by"`
+	CreatedAt   time.Time          `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at" db:"updated_at"`
+	Progress    *MilestoneProgress `json:"progress,omitempty"`
+}
+
+// MilestoneProgress sums up the non-deleted tasks of a milestone. Tasks in a
+// done status of the project's workflow count as done. Percent is the share
+// of done estimated hours, or of done tasks when no hours are estimated.
+type MilestoneProgress struct {
+	TotalTasks int     `json:"total_tasks"`
+	DoneTasks  int     `json:"done_tasks"`
+	TotalHours float64 `json:"total_hours"`
+	DoneHours  float64 `json:"done_hours"`
+	Percent    float64 `json:"percent"`
+	Overdue    bool    `json:"overdue"`
+}
+
+// MilestoneRequest represents a milestone creation/update request. On
+// update, omitted fields are unchanged. TargetDate is a YYYY-MM-DD date.
+type MilestoneRequest struct {
+	Name        string  `json:"name" validate:"max=255"`
+	Description *string `json:"description"`
+	TargetDate  string  `json:"target_date"`
+	Status      string  `json:"status" validate:"omitempty,oneof=open completed cancelled"`
+}
\ No newline at end of file
@@ -4,42 +4,49 @@ import (
 	"time"
 )
 
-// Project represents a project in the system
+// Project represents a project in the system. StrictDependencies rejects
+// starting a task with open blockers instead of warning.
 type Project struct {
-	ID          int        `json:"id" db:"id"`
-	Name        string     `json:"name" db:"name" validate:"required,min=1,max=100"`
-	Description string     `json:"description" db:"description"`
-	OwnerID     int        `json:"owner_id" db:"owner_id"`
-	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
-	DeletedAt   *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	ID                 int        `json:"id" db:"id"`
+	Name               string     `json:"name" db:"name" validate:"required,min=1,max=100"`
+	Description        string     `json:"description" db:"description"`
+	OwnerID            int        `json:"owner_id" db:"owner_id"`
+	StrictDependencies bool       `json:"strict_dependencies" db:"strict_dependencies"`
+	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt          *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
 }
 
 // ProjectRequest represents a project creation/update request
 type ProjectRequest struct {
-	Name        string `json:"name" validate:"required,min=1,max=100"`
-	Description string `json:"description"`
+	Name               string `json:"name" validate:"required,min=1,max=100"`
+	Description        string `json:"description"`
+	StrictDependencies *bool  `json:"strict_dependencies"`
 }
 
 // ProjectResponse represents a project response with additional info
 type ProjectResponse struct {
-	ID          int       `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	OwnerID     int       `json:"owner_id"`
-	OwnerName   string    `json:"owner_name,omitempty"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-	TaskStats   *TaskStats `json:"task_stats,omitempty"`
+	ID                 int        `json:"id"`
+	Name               string     `json:"name"`
+	Description        string     `json:"description"`
+	OwnerID            int        `json:"owner_id"`
+	OwnerName          string     `json:"owner_name,omitempty"`
+	StrictDependencies bool       `json:"strict_dependencies"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+	TaskStats          *TaskStats `json:"task_stats,omitempty"`
 }
 
-// TaskStats represents task statistics for a project
+// TaskStats represents task statistics for a project. Completed, in
+// progress and todo counts follow the status categories of the project
+// workflow; StatusCounts has the count of each status.
 type TaskStats struct {
-	TotalTasks      int     `json:"total_tasks"`
-	CompletedTasks  int     `json:"completed_tasks"`
-	InProgressTasks int     `json:"in_progress_tasks"`
-	TodoTasks       int     `json:"todo_tasks"`
-	CompletionRate  float64 `json:"completion_rate"`
+	TotalTasks      int            `json:"total_tasks"`
+	CompletedTasks  int            `json:"completed_tasks"`
+	InProgressTasks int            `json:"in_progress_tasks"`
+	TodoTasks       int            `json:"todo_tasks"`
+	CompletionRate  float64        `json:"completion_rate"`
+	StatusCounts    map[string]int `json:"status_counts"`
 }
 
 // RecycledProject represents a deleted project in the recycle bin
@@ -55,6 +62,32 @@ type RecycledProject struct {
 	DeletedTasksCount  int        `json:"deleted_tasks_count" db:"deleted_tasks_count"`
 }
 
+// ProjectMember represents a user's membership in a project
+type ProjectMember struct {
+	ProjectID int       `json:"project_id" db:"project_id"`
+	UserID    int       `json:"user_id" db:"user_id"`
+	Username  string    `json:"username" db:"username"`
+	Role      string    `json:"role" db:"role" validate:"required,oneof=owner maintainer member viewer"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ProjectMemberRequest represents a request to add a member to a project
+type ProjectMemberRequest struct {
+	UserID int    `json:"user_id" validate:"required"`
+	Role   string `json:"role" validate:"required,oneof=owner maintainer member viewer"`
+}
+
+// ProjectMemberRoleRequest represents a request to change a member's role
+type ProjectMemberRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=owner maintainer member viewer"`
+}
+
+// SecuritySettings holds admin-configurable security policy
+type SecuritySettings struct {
+	RequireTwoFactorForAdmins bool `json:"require_2fa_for_admins"`
+}
+
 // AuditLog represents a system audit log entry
 type AuditLog struct {
 	ID         int                    `json:"id" db:"id"`
@@ -71,11 +104,12 @@ type AuditLog struct {
 // ToResponse converts Project to ProjectResponse
 func (p *Project) ToResponse() ProjectResponse {
 	return ProjectResponse{
-		ID:          p.ID,
-		Name:        p.Name,
-		Description: p.Description,
-		OwnerID:     p.OwnerID,
-		CreatedAt:   p.CreatedAt,
-		UpdatedAt:   p.UpdatedAt,
+		ID:                 p.ID,
+		Name:               p.Name,
+		Description:        p.Description,
+		OwnerID:            p.OwnerID,
+		StrictDependencies: p.StrictDependencies,
+		CreatedAt:          p.CreatedAt,
+		UpdatedAt:          p.UpdatedAt,
 	}
 }
\ No newline at end of file
@@ -5,11 +5,13 @@ import (
 	"time"
 )
 
-// APIResponse represents a standard API response
+// APIResponse represents a standard API response. Warnings report problems
+// that did not stop a successful request.
 type APIResponse struct {
 	Success   bool        `json:"success"`
 	Message   string      `json:"message,omitempty"`
 	Data      interface{} `json:"data,omitempty"`
+	Warnings  []string    `json:"warnings,omitempty"`
 	Error     *APIError   `json:"error,omitempty"`
 	Timestamp time.Time   `json:"timestamp"`
 }
@@ -73,6 +75,7 @@ const (
 	ErrCodeConflict      = "CONFLICT"
 	ErrCodeInternal      = "INTERNAL_ERROR"
 	ErrCodeBadRequest    = "BAD_REQUEST"
+	ErrCodeTooManyRequests = "TOO_MANY_REQUESTS"
 )
 
 // Common HTTP status codes mapping
@@ -84,6 +87,7 @@ var ErrorStatusCodes = map[string]int{
 	ErrCodeConflict:       http.StatusConflict,
 	ErrCodeInternal:       http.StatusInternalServerError,
 	ErrCodeBadRequest:     http.StatusBadRequest,
+	ErrCodeTooManyRequests: http.StatusTooManyRequests,
 }
 
 // GetStatusCode returns the HTTP status code for an error code
@@ -0,0 +1,8 @@
+package models
+
+// ScheduleQuery represents project schedule query parameters. Start is a
+// YYYY-MM-DD date and defaults to today; HoursPerDay defaults to 8.
+type ScheduleQuery struct {
+	Start       string  `form:"start"`
+	HoursPerDay float64 `form:"hours_per_day" validate:"omitempty,gt=0,max=24"`
+}
\ No newline at end of file
@@ -0,0 +1,81 @@
+package models
+
+// Search result types
+const (
+	SearchTypeProject = "project"
+	SearchTypeTask    = "task"
+)
+
+// SearchQuery represents search query parameters. Multi-valued parameters
+// may be repeated or comma separated.
+type SearchQuery struct {
+	Q         string   `form:"q" validate:"required"`
+	Type      []string `form:"type"`
+	ProjectID []int    `form:"project_id"`
+	Status    []string `form:"status"`
+	Page      int      `form:"page,default=1" validate:"min=1"`
+	PageSize  int      `form:"page_size,default=20" validate:"min=1,max=100"`
+}
+
+// SearchFilter selects search hits. Every term must occur in the title or
+// description of a hit. MemberID limits hits to the projects a user owns or
+// belongs to; it is nil for admins.
+type SearchFilter struct {
+	Query      string
+	Terms      []string
+	Types      []string
+	ProjectIDs []int
+	Statuses   []string
+	MemberID   *int
+	Limit      int
+	Offset     int
+}
+
+// SearchHit represents one project or task matching a search
+type SearchHit struct {
+	Type        string           `json:"type"`
+	ID          int              `json:"id"`
+	ProjectID   int              `json:"project_id"`
+	ProjectName string           `json:"project_name"`
+	Title       string           `json:"title"`
+	Description string           `json:"-"`
+	Status      *string          `json:"status,omitempty"`
+	Rank        float64          `json:"rank"`
+	Highlight   *SearchHighlight `json:"highlight,omitempty"`
+}
+
+// SearchHighlight holds the matched title and a description snippet with
+// every match wrapped in <mark> tags. The remaining text is HTML escaped.
+type SearchHighlight struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+}
+
+// SearchFacet counts the hits sharing a project or a status
+type SearchFacet struct {
+	Value interface{} `json:"value"`
+	Label string      `json:"label"`
+	Count int         `json:"count"`
+}
+
+// SearchFacets groups the facet counts of a search. Each facet ignores its
+// own filter, so the counts show what selecting another value would return.
+type SearchFacets struct {
+	Projects []*SearchFacet `json:"projects"`
+	Statuses []*SearchFacet `json:"statuses"`
+}
+
+// SearchResult is the repository result of a search
+type SearchResult struct {
+	Hits   []*SearchHit
+	Total  int
+	Facets SearchFacets
+}
+
+// SearchResponse represents a page of search results
+type SearchResponse struct {
+	Query      string       `json:"query"`
+	Results    []*SearchHit `json:"results"`
+	Facets     SearchFacets `json:"facets"`
+	Pagination Pagination   `json:"pagination"`
+}
\ No newline at end of file
@@ -0,0 +1,100 @@
+package models
+
+import "time"
+
+// Sprint states
+const (
+	SprintPlanned = "planned"
+	SprintActive  = "active"
+	SprintClosed  = "closed"
+)
+
+// Sprint is a time-boxed iteration of a project. Tasks are linked to it
+// through their sprint_id; tasks without a sprint are in the backlog.
+// CommittedHours is recorded when the sprint starts and the completed
+// figures when it closes.
+type Sprint struct {
+	ID               int             `json:"id" db:"id"`
+	ProjectID        int             `json:"project_id" db:"project_id"`
+	Name             string          `json:"name" db:"name" validate:"required,max=255"`
+	Goal             string          `json:"goal" db:"goal"`
+	StartDate        time.Time       `json:"start_date" db:"start_date"`
+	EndDate          time.Time       `json:"end_date" db:"end_date"`
+	State            string          `json:"state" db:"state" validate:"oneof=planned active closed"`
+	CommittedHours   *float64        `json:"committed_hours" db:"committed_hours"`
+	CompletedHours   *float64        `json:"completed_hours" db:"completed_hours"`
+	CompletedTasks   *int            `json:"completed_tasks" db:"completed_tasks"`
+	CarriedOverTasks *int            `json:"carried_over_tasks" db:"carried_over_tasks"`
+	StartedAt        *time.Time      `json:"started_at" db:"started_at"`
+	ClosedAt         *time.Time      `json:"closed_at" db:"closed_at"`
+	CreatedBy        *int            `json:"created_by" db:"created_by"`
+	CreatedAt        time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at" db:"updated_at"`
+	Progress         *SprintProgress `json:"progress,omitempty"`
+}
+
+// SprintProgress sums up the non-deleted tasks currently in a sprint. Tasks
+// in a done status of the project's workflow count as done.
+type SprintProgress struct {
+	TotalTasks int     `json:"total_tasks"`
+	DoneTasks  int     `json:"done_tasks"`
+	TotalHours float64 `json:"total_hours"`
+	DoneHours  float64 `json:"done_hours"`
+}
+
+// SprintRequest represents a sprint creation/update request. On update,
+// omitted fields are unchanged. Dates are YYYY-MM-DD dates.
+type SprintRequest struct {
+	Name      string  `json:"name" validate:"max=255"`
+	Goal      *string `json:"goal"`
+	StartDate string  `json:"start_date"`
+	EndDate   string  `json:"end_date"`
+}
+
+// SprintCloseRequest closes a sprint. Incomplete tasks are carried over to
+// the sprint CarryOverTo, or to the backlog when it is null.
+type SprintCloseRequest struct {
+	CarryOverTo *int `json:"carry_over_to"`
+}
+
+// SprintCloseResponse represents a closed sprint and the tasks carried over
+type SprintCloseResponse struct {
+	Sprint      *Sprint `json:"sprint"`
+	CarriedOver []int   `json:"carried_over"`
+}
+
+// SprintTasksRequest moves tasks into a sprint or back to the backlog
+type SprintTasksRequest struct {
+	TaskIDs []int `json:"task_ids" validate:"required,min=1,max=1000"`
+}
+
+// SprintTasksResponse lists the tasks that were moved
+type SprintTasksResponse struct {
+	SprintID *int  `json:"sprint_id"`
+	MovedIDs []int `json:"moved_ids"`
+}
+
+// SprintVelocity is the outcome of one closed sprint
+type SprintVelocity struct {
+	SprintID         int       `json:"sprint_id"`
+	Name             string    `json:"name"`
+	StartDate        time.Time `json:"start_date"`
+	EndDate          time.Time `json:"end_date"`
+	CommittedHours   float64   `json:"committed_hours"`
+	CompletedHours   float64   `json:"completed_hours"`
+	CompletedTasks   int       `json:"completed_tasks"`
+	CarriedOverTasks int       `json:"carried_over_tasks"`
+}
+
+// VelocityQuery represents velocity query parameters
+type VelocityQuery struct {
+	Limit int `form:"limit,default=10" validate:"min=1,max=100"`
+}
+
+// VelocityReport lists the most recent closed sprints of a project, oldest
+// first, with the average hours completed per sprint
+type VelocityReport struct {
+	ProjectID    int               `json:"project_id"`
+	Sprints      []*SprintVelocity `json:"sprints"`
+	AverageHours float64           `json:"average_hours"`
+}
\ No newline at end of file
@@ -4,7 +4,11 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
 	"time"
+	"unicode"
 )
 
 // CustomFields represents JSONB custom fields
@@ -39,29 +43,47 @@ type Task struct {
 	ProjectID    int          `json:"project_id" db:"project_id" validate:"required"`
 	Title        string       `json:"title" db:"title" validate:"required,min=1,max=255"`
 	Description  string       `json:"description" db:"description"`
-	Status       string       `json:"status" db:"status" validate:"required,oneof=todo in_progress completed cancelled"`
+	Status       string       `json:"status" db:"status" validate:"required,max=20"`
 	AssigneeID   *int         `json:"assignee_id" db:"assignee_id"`
 	DueDate      *time.Time   `json:"due_date" db:"due_date"`
 	CustomFields CustomFields `json:"custom_fields" db:"custom_fields"`
+	ParentID     *int         `json:"parent_id" db:"parent_id"`
+	Level        int          `json:"level" db:"level"`
+	MilestoneID  *int         `json:"milestone_id" db:"milestone_id"`
+	SprintID     *int         `json:"sprint_id" db:"sprint_id"`
 	CreatedAt    time.Time    `json:"created_at" db:"created_at"`
 	UpdatedAt    time.Time    `json:"updated_at" db:"updated_at"`
 	DeletedAt    *time.Time   `json:"deleted_at,omitempty" db:"deleted_at"`
+
+	// Planning fields
+	Priority       string       `json:"priority" db:"priority" validate:"oneof=low medium high"`
+	EstimatedHours *float64     `json:"estimated_hours" db:"estimated_hours" validate:"omitempty,min=0"`
+	ActualHours    *float64     `json:"actual_hours" db:"actual_hours" validate:"omitempty,min=0"`
+	Progress       int          `json:"progress" db:"progress" validate:"min=0,max=100"`
+	Tags           []string     `json:"tags" db:"tags"`
+	Metadata       CustomFields `json:"metadata" db:"metadata"`
 }
 
-// TaskRequest represents a task creation/update request
+// TaskRequest represents a task creation/update request. A milestone_id of
+// 0 removes the task from its milestone and a sprint_id of 0 moves it to
+// the backlog.
 type TaskRequest struct {
-	Title        string       `json:"title" validate:"required,min=1,max=255"`
-	Description  string       `json:"description"`
-	Status       string       `json:"status" validate:"required,oneof=todo in_progress completed cancelled"`
-	AssigneeID   *int         `json:"assignee_id"`
-	DueDate      *time.Time   `json:"due_date"`
-	CustomFields CustomFields `json:"custom_fields"`
-	Priority       string       `json:"priority" db:"priority" validate:"oneof=low medium high"` 
-	EstimatedHours *float64     `json:"estimated_hours" db:"estimated_hours" validate:"min=0"` 
-	ActualHours    *float64     `json:"actual_hours" db:"actual_hours" validate:"min=0"` 
-	Progress       *int         `json:"progress" db:"progress" validate:"min=0,max=100"` 
-	Tags           []string     `json:"tags" db:"tags"` 
+	Title          string       `json:"title" validate:"required,min=1,max=255"`
+	Description    string       `json:"description"`
+	Status         string       `json:"status" validate:"required,max=20"`
+	AssigneeID     *int         `json:"assignee_id"`
+	DueDate        *time.Time   `json:"due_date"`
+	CustomFields   CustomFields `json:"custom_fields"`
+	ParentID       *int         `json:"parent_id"`
+	MilestoneID    *int         `json:"milestone_id"`
+	SprintID       *int         `json:"sprint_id"`
+	Priority       string       `json:"priority" db:"priority" validate:"oneof=low medium high"`
+	EstimatedHours *float64     `json:"estimated_hours" db:"estimated_hours" validate:"min=0"`
+	ActualHours    *float64     `json:"actual_hours" db:"actual_hours" validate:"min=0"`
+	Progress       *int         `json:"progress" db:"progress" validate:"min=0,max=100"`
+	Tags           []string     `json:"tags" db:"tags"`
 	Metadata       CustomFields `json:"metadata" db:"metadata"`
+	Note           string       `json:"note"`
 }
 
 // TaskResponse represents a task response with additional info
@@ -76,10 +98,51 @@ type TaskResponse struct {
 	AssigneeName   string       `json:"assignee_name,omitempty"`
 	DueDate        *time.Time   `json:"due_date"`
 	CustomFields   CustomFields `json:"custom_fields"`
+	ParentID       *int         `json:"parent_id"`
+	Level          int          `json:"level"`
+	MilestoneID    *int         `json:"milestone_id"`
+	SprintID       *int         `json:"sprint_id"`
+	Priority       string       `json:"priority"`
+	EstimatedHours *float64     `json:"estimated_hours"`
+	ActualHours    *float64     `json:"actual_hours"`
+	Progress       int          `json:"progress"`
+	Tags           []string     `json:"tags"`
+	Metadata       CustomFields `json:"metadata,omitempty"`
 	CreatedAt      time.Time    `json:"created_at"`
 	UpdatedAt      time.Time    `json:"updated_at"`
 }
 
+// TaskStatusRequest represents a task status change
+type TaskStatusRequest struct {
+	Status string `json:"status" validate:"required,max=20"`
+	Note   string `json:"note"`
+}
+
+// TaskUpdate represents one recorded change to a task. UpdateType is the
+// changed field, "created" or "note"; UpdatedBy is nil for system changes.
+type TaskUpdate struct {
+	ID            int         `json:"id" db:"id"`
+	TaskID        int         `json:"task_id" db:"task_id"`
+	UpdateType    string      `json:"update_type" db:"update_type"`
+	OldValue      interface{} `json:"old_value" db:"old_value"`
+	NewValue      interface{} `json:"new_value" db:"new_value"`
+	Notes         *string     `json:"notes,omitempty" db:"notes"`
+	UpdatedBy     *int        `json:"updated_by" db:"updated_by"`
+	UpdatedByName *string     `json:"updated_by_name,omitempty" db:"updated_by_name"`
+	CreatedAt     time.Time   `json:"created_at" db:"created_at"`
+}
+
+// TaskParentRequest moves a task under another task, or to the root when ParentID is null
+type TaskParentRequest struct {
+	ParentID *int `json:"parent_id"`
+}
+
+// TaskTreeNode represents a task with its subtasks
+type TaskTreeNode struct {
+	TaskResponse
+	Children []*TaskTreeNode `json:"children"`
+}
+
 // BulkImportRequest represents a bulk task import request
 type BulkImportRequest struct {
 	Tasks []TaskRequest `json:"tasks" validate:"required,min=1,max=1000,dive"`
@@ -94,13 +157,96 @@ type BulkImportResponse struct {
 	ImportedTasks []int `json:"imported_tasks"`
 }
 
-// TaskFilter represents task filtering options
+// TaskFilter represents task filtering options. Multi-valued parameters may
+// be repeated or comma separated and match any of their values.
 type TaskFilter struct {
-	Status     string `form:"status"`
-	AssigneeID *int   `form:"assignee_id"`
-	DueAfter   string `form:"due_after"`
-	DueBefore  string `form:"due_before"`
-	Search     string `form:"search"`
+	Status     []string `form:"status"`
+	AssigneeID []string `form:"assignee_id"` // user IDs, "me" or "none"
+	DueAfter   string   `form:"due_after"`
+	DueBefore  string   `form:"due_before"`
+	Search     string   `form:"search"`
+
+	// Planning fields
+	Priority          []string `form:"priority"`
+	Tag               []string `form:"tag"`
+	MinProgress       *int     `form:"min_progress" validate:"omitempty,min=0,max=100"`
+	MaxProgress       *int     `form:"max_progress" validate:"omitempty,min=0,max=100"`
+	MinEstimatedHours *float64 `form:"min_estimated_hours"`
+	MaxEstimatedHours *float64 `form:"max_estimated_hours"`
+	MinActualHours    *float64 `form:"min_actual_hours"`
+	MaxActualHours    *float64 `form:"max_actual_hours"`
+
+	// MilestoneID accepts milestone IDs or "none" for tasks without a milestone
+	MilestoneID []string `form:"milestone_id"`
+
+	// SprintID accepts sprint IDs or "none" for backlog tasks
+	SprintID []string `form:"sprint_id"`
+
+	// ProjectID narrows cross-project listings
+	ProjectID []int `form:"project_id"`
+
+	// Sort is a comma separated list of sort keys; a leading "-" sorts descending
+	Sort string `form:"sort"`
+
+	// Resolved by the handler from the values above
+	AssigneeIDs  []int                  `form:"-"`
+	Unassigned   bool                   `form:"-"`
+	DueFrom      *time.Time             `form:"-"`
+	DueTo        *time.Time             `form:"-"`
+	MilestoneIDs []int                  `form:"-"`
+	NoMilestone  bool                   `form:"-"`
+	SprintIDs    []int                  `form:"-"`
+	Backlog      bool                   `form:"-"`
+	CustomFields []CustomFieldCondition `form:"-"`
+}
+
+// CustomFieldCondition is a filter on a custom field, written in a query
+// string as cf.<path><operator><value>, e.g. cf.category=backend or
+// cf.story_points>=3. Nested keys are separated by dots. An equality
+// may list several comma separated values and matches any of them; a comma
+// within a value is escaped as %2C.
+type CustomFieldCondition struct {
+	Path     []string
+	Operator string
+	Values   []string
+}
+
+// CustomFieldOperators lists the operators of custom field conditions,
+// longest first so that ">=" is not read as ">"
+var CustomFieldOperators = []string{">=", "<=", "!=", "=", ">", "<"}
+
+// ParseCustomFieldPath splits a dotted custom field path into keys. Keys may
+// contain letters, digits, "_" and "-" only.
+func ParseCustomFieldPath(path string) ([]string, bool) {
+	keys := strings.Split(path, ".")
+	for _, key := range keys {
+		if key == "" {
+			return nil, false
+		}
+		for _, r := range key {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' {
+				return nil, false
+			}
+		}
+	}
+	return keys, true
+}
+
+// CustomFieldValue converts a query string value to the JSON value it most
+// likely stands for: a boolean, null, a number or else a string
+func CustomFieldValue(raw string) interface{} {
+	switch raw {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+	if f, err := strconv.ParseFloat(raw, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+		return f
+	}
+	return raw
 }
 
 // PaginationParams represents pagination parameters
@@ -139,20 +285,31 @@ type RecycledTask struct {
 	DeletedAt        time.Time    `json:"deleted_at" db:"deleted_at"`
 	ProjectName      string       `json:"project_name" db:"project_name"`
 	AssigneeUsername *string      `json:"assignee_username" db:"assignee_username"`
+	ParentID         *int         `json:"parent_id" db:"parent_id"`
 }
 
 // ToResponse converts Task to TaskResponse
 func (t *Task) ToResponse() TaskResponse {
 	return TaskResponse{
-		ID:           t.ID,
-		ProjectID:    t.ProjectID,
-		Title:        t.Title,
-		Description:  t.Description,
-		Status:       t.Status,
-		AssigneeID:   t.AssigneeID,
-		DueDate:      t.DueDate,
-		CustomFields: t.CustomFields,
-		CreatedAt:    t.CreatedAt,
-		UpdatedAt:    t.UpdatedAt,
+		ID:             t.ID,
+		ProjectID:      t.ProjectID,
+		Title:          t.Title,
+		Description:    t.Description,
+		Status:         t.Status,
+		AssigneeID:     t.AssigneeID,
+		DueDate:        t.DueDate,
+		CustomFields:   t.CustomFields,
+		ParentID:       t.ParentID,
+		Level:          t.Level,
+		MilestoneID:    t.MilestoneID,
+		SprintID:       t.SprintID,
+		Priority:       t.Priority,
+		EstimatedHours: t.EstimatedHours,
+		ActualHours:    t.ActualHours,
+		Progress:       t.Progress,
+		Tags:           t.Tags,
+		Metadata:       t.Metadata,
+		CreatedAt:      t.CreatedAt,
+		UpdatedAt:      t.UpdatedAt,
 	}
 }
\ No newline at end of file
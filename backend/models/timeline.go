@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+// Timeline event types
+const (
+	TimelineCreated   = "created"
+	TimelineUpdated   = "updated"
+	TimelineCompleted = "completed"
+	TimelineDeleted   = "deleted"
+	TimelineRestored  = "restored"
+)
+
+// TimelineEvent represents one entry of an activity timeline. Events come
+// from the task update history (Source "u") or the audit log (Source "a").
+type TimelineEvent struct {
+	Source     string      `json:"source"`
+	ID         int         `json:"id"`
+	EventType  string      `json:"event_type"`
+	EntityType string      `json:"entity_type"`
+	EntityID   int         `json:"entity_id"`
+	ProjectID  *int        `json:"project_id"`
+	Title      string      `json:"title"`
+	UpdateType *string     `json:"update_type,omitempty"`
+	OldValue   interface{} `json:"old_value,omitempty"`
+	NewValue   interface{} `json:"new_value,omitempty"`
+	Notes      *string     `json:"notes,omitempty"`
+	UserID     *int        `json:"user_id"`
+	Username   *string     `json:"username,omitempty"`
+	OccurredAt time.Time   `json:"occurred_at"`
+}
+
+// TimelineCursor is the position of the last event of a timeline page
+type TimelineCursor struct {
+	OccurredAt time.Time
+	Source     string
+	ID         int
+}
+
+// TimelineFilter selects timeline events. At most one of TaskID, ProjectID
+// and UserID is usually set; events are returned newest first. MemberID
+// limits events to the projects a user owns or belongs to; it is nil for
+// admins.
+type TimelineFilter struct {
+	TaskID     *int
+	ProjectID  *int
+	UserID     *int
+	MemberID   *int
+	EventTypes []string
+	From       *time.Time
+	To         *time.Time
+	After      *TimelineCursor
+	Limit      int
+}
+
+// TimelineQuery represents timeline query parameters
+type TimelineQuery struct {
+	Cursor    string   `form:"cursor"`
+	Limit     int      `form:"limit,default=50" validate:"min=1,max=200"`
+	EventType []string `form:"event_type"`
+	From      string   `form:"from"`
+	To        string   `form:"to"`
+	GroupBy   string   `form:"group_by" validate:"omitempty,oneof=day"`
+	TZ        string   `form:"tz"`
+}
+
+// TimelineDay groups the events of one calendar day
+type TimelineDay struct {
+	Date   string           `json:"date"`
+	Events []*TimelineEvent `json:"events"`
+}
+
+// TimelineResponse represents a page of timeline events, either flat or grouped by day
+type TimelineResponse struct {
+	Events     []*TimelineEvent `json:"events,omitempty"`
+	Days       []*TimelineDay   `json:"days,omitempty"`
+	NextCursor *string          `json:"next_cursor"`
+	HasMore    bool             `json:"has_more"`
+}
\ No newline at end of file
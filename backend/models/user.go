@@ -6,21 +6,86 @@ import (
 
 // User represents a user in the system
 type User struct {
-	ID           int       `json:"id" db:"id"`
-	Username     string    `json:"username" db:"username" validate:"required,min=3,max=50"`
-	PasswordHash string    `json:"-" db:"password_hash"`
-	Role         string    `json:"role" db:"role" validate:"required,oneof=admin user"`
-	CreatedAt    time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+	ID              int        `json:"id" db:"id"`
+	Username        string     `json:"username" db:"username" validate:"required,min=3,max=50"`
+	Email           *string    `json:"email,omitempty" db:"email"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty" db:"email_verified_at"`
+	PasswordHash    string     `json:"-" db:"password_hash"`
+	Role            string     `json:"role" db:"role" validate:"required,oneof=admin user"`
+	AuthProvider    string     `json:"auth_provider" db:"auth_provider"`
+	ExternalID      *string    `json:"-" db:"external_id"`
+	TOTPSecret      *string    `json:"-" db:"totp_secret"`
+	TOTPEnabled     bool       `json:"totp_enabled" db:"totp_enabled"`
+	TOTPLastStep    int64      `json:"-" db:"totp_last_step"`
+	IsActive        bool       `json:"is_active" db:"is_active"`
+	DeactivatedAt   *time.Time `json:"deactivated_at,omitempty" db:"deactivated_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
 }
 
 // UserRequest represents a user creation/update request
 type UserRequest struct {
 	Username string `json:"username" validate:"required,min=3,max=50"`
+	Email    string `json:"email" validate:"omitempty,email"`
 	Password string `json:"password" validate:"required,min=6"`
 	Role     string `json:"role" validate:"required,oneof=admin user"`
 }
 
+// UserUpdateRequest represents an admin update of a user's profile
+type UserUpdateRequest struct {
+	Username string  `json:"username" validate:"required,min=3,max=50"`
+	Email    *string `json:"email" validate:"omitempty,email"`
+}
+
+// UserRoleRequest represents a change of a user's system role
+type UserRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=admin user"`
+}
+
+// PasswordResetRequest represents an admin setting a new password for a user
+type PasswordResetRequest struct {
+	Password string `json:"password" validate:"required,min=6"`
+}
+
+// ChangePasswordRequest represents a user changing their own password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=6"`
+}
+
+// EmailRequest represents a user setting their email address
+type EmailRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// VerifyEmailRequest confirms an email address with the token from the verification link
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// ForgotPasswordRequest asks for a password reset link to be emailed
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ResetPasswordRequest sets a new password with the token from a reset link
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=6"`
+}
+
+// UserToken is a single-use, time-limited token emailed to a user
+type UserToken struct {
+	ID        int        `json:"id" db:"id"`
+	UserID    int        `json:"user_id" db:"user_id"`
+	Purpose   string     `json:"purpose" db:"purpose"`
+	TokenHash string     `json:"-" db:"token_hash"`
+	Email     *string    `json:"email,omitempty" db:"email"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty" db:"used_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
 // LoginRequest represents a login request
 type LoginRequest struct {
 	Username string `json:"username" validate:"required"`
@@ -29,26 +94,139 @@ type LoginRequest struct {
 
 // LoginResponse represents a login response
 type LoginResponse struct {
+	Token            string    `json:"token"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+	User             User      `json:"user"`
+}
+
+// LoginFailure tracks failed logins for a username or client IP
+type LoginFailure struct {
+	Scope        string     `json:"scope" db:"scope"`
+	Subject      string     `json:"subject" db:"subject"`
+	FailureCount int        `json:"failure_count" db:"failure_count"`
+	LastFailedAt time.Time  `json:"last_failed_at" db:"last_failed_at"`
+	LockedUntil  *time.Time `json:"locked_until,omitempty" db:"locked_until"`
+}
+
+// MFAChallengeResponse is returned by login when a second factor is required
+type MFAChallengeResponse struct {
+	MFARequired bool      `json:"mfa_required"`
+	MFAToken    string    `json:"mfa_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// MFAVerifyRequest completes a two-step login with a TOTP or recovery code
+type MFAVerifyRequest struct {
+	MFAToken     string `json:"mfa_token" validate:"required"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// TOTPEnrollResponse carries the secret and QR provisioning URI for enrollment
+type TOTPEnrollResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// TOTPCodeRequest carries a TOTP code to confirm a two-factor operation
+type TOTPCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// RecoveryCodesResponse returns newly generated recovery codes, shown only once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// RefreshRequest represents a refresh token exchange request
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// AuthSession represents a single refresh token in a session family
+type AuthSession struct {
+	ID         int        `json:"id" db:"id"`
+	UserID     int        `json:"user_id" db:"user_id"`
+	FamilyID   string     `json:"family_id" db:"family_id"`
+	TokenHash  string     `json:"-" db:"token_hash"`
+	MFA        bool       `json:"mfa_verified" db:"mfa_verified"`
+	ReplacedBy *int       `json:"replaced_by,omitempty" db:"replaced_by"`
+	IPAddress  *string    `json:"ip_address,omitempty" db:"ip_address"`
+	UserAgent  *string    `json:"user_agent,omitempty" db:"user_agent"`
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// SessionInfo represents an active login session shown to its user
+type SessionInfo struct {
+	ID         string    `json:"id"`
+	IPAddress  *string   `json:"ip_address,omitempty"`
+	UserAgent  *string   `json:"user_agent,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+// APIToken represents a personal API token
+type APIToken struct {
+	ID          int        `json:"id" db:"id"`
+	UserID      int        `json:"user_id" db:"user_id"`
+	Name        string     `json:"name" db:"name"`
+	TokenPrefix string     `json:"token_prefix" db:"token_prefix"`
+	TokenHash   string     `json:"-" db:"token_hash"`
+	Scopes      []string   `json:"scopes" db:"scopes"`
+	ExpiresAt   time.Time  `json:"expires_at" db:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at" db:"last_used_at"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// APITokenRequest represents a personal API token creation request
+type APITokenRequest struct {
+	Name          string   `json:"name" validate:"required,min=1,max=100"`
+	Scopes        []string `json:"scopes" validate:"required,min=1"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"min=0,max=365"`
+}
+
+// APITokenCreateResponse is returned once when a token is created; the
+// plain token cannot be retrieved again
+type APITokenCreateResponse struct {
+	APIToken
 	Token string `json:"token"`
-	User  User   `json:"user"`
 }
 
 // UserResponse represents a user response (without sensitive data)
 type UserResponse struct {
-	ID        int       `json:"id"`
-	Username  string    `json:"username"`
-	Role      string    `json:"role"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID            int        `json:"id"`
+	Username      string     `json:"username"`
+	Email         *string    `json:"email,omitempty"`
+	EmailVerified bool       `json:"email_verified"`
+	Role          string     `json:"role"`
+	AuthProvider  string     `json:"auth_provider"`
+	TOTPEnabled   bool       `json:"totp_enabled"`
+	IsActive      bool       `json:"is_active"`
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
 }
 
 // ToResponse converts User to UserResponse
 func (u *User) ToResponse() UserResponse {
 	return UserResponse{
-		ID:        u.ID,
-		Username:  u.Username,
-		Role:      u.Role,
-		CreatedAt: u.CreatedAt,
-		UpdatedAt: u.UpdatedAt,
+		ID:            u.ID,
+		Username:      u.Username,
+		Email:         u.Email,
+		EmailVerified: u.EmailVerifiedAt != nil,
+		Role:          u.Role,
+		AuthProvider:  u.AuthProvider,
+		TOTPEnabled:   u.TOTPEnabled,
+		IsActive:      u.IsActive,
+		DeactivatedAt: u.DeactivatedAt,
+		CreatedAt:     u.CreatedAt,
+		UpdatedAt:     u.UpdatedAt,
 	}
 }
\ No newline at end of file
@@ -0,0 +1,128 @@
+package models
+
+// Status categories. Every workflow status belongs to one category, which
+// drives progress, statistics and parent task roll-up.
+const (
+	StatusCategoryTodo  = "todo"
+	StatusCategoryDoing = "doing"
+	StatusCategoryDone  = "done"
+)
+
+// WorkflowStatus represents one task status of a project workflow
+type WorkflowStatus struct {
+	Key      string `json:"key" db:"key"`
+	Name     string `json:"name" db:"name"`
+	Category string `json:"category" db:"category"`
+	Position int    `json:"position" db:"position"`
+}
+
+// WorkflowTransition allows tasks to move from one status to another
+type WorkflowTransition struct {
+	From string `json:"from" db:"from_status"`
+	To   string `json:"to" db:"to_status"`
+}
+
+// Workflow holds the statuses of a project and the transitions allowed
+// between them. Custom is false for projects using the default workflow.
+type Workflow struct {
+	ProjectID   int                   `json:"project_id"`
+	Custom      bool                  `json:"custom"`
+	Statuses    []*WorkflowStatus     `json:"statuses"`
+	Transitions []*WorkflowTransition `json:"transitions"`
+}
+
+// DefaultWorkflow returns the workflow used by projects without their own:
+// todo, in_progress, completed and cancelled, with every transition allowed
+func DefaultWorkflow(projectID int) *Workflow {
+	workflow := &Workflow{
+		ProjectID: projectID,
+		Statuses: []*WorkflowStatus{
+			{Key: "todo", Name: "To Do", Category: StatusCategoryTodo, Position: 0},
+			{Key: "in_progress", Name: "In Progress", Category: StatusCategoryDoing, Position: 1},
+			{Key: "completed", Name: "Completed", Category: StatusCategoryDone, Position: 2},
+			{Key: "cancelled", Name: "Cancelled", Category: StatusCategoryDone, Position: 3},
+		},
+	}
+	workflow.Transitions = AllTransitions(workflow.Statuses)
+	return workflow
+}
+
+// AllTransitions returns every transition between distinct statuses
+func AllTransitions(statuses []*WorkflowStatus) []*WorkflowTransition {
+	transitions := []*WorkflowTransition{}
+	for _, from := range statuses {
+		for _, to := range statuses {
+			if from.Key != to.Key {
+				transitions = append(transitions, &WorkflowTransition{From: from.Key, To: to.Key})
+			}
+		}
+	}
+	return transitions
+}
+
+// Status returns the workflow status with the given key, or nil
+func (w *Workflow) Status(key string) *WorkflowStatus {
+	for _, status := range w.Statuses {
+		if status.Key == key {
+			return status
+		}
+	}
+	return nil
+}
+
+// Category returns the category of a status, or "" when the status is not
+// part of the workflow
+func (w *Workflow) Category(key string) string {
+	if status := w.Status(key); status != nil {
+		return status.Category
+	}
+	return ""
+}
+
+// CanTransition reports whether a task may move between two statuses.
+// Keeping the current status is always allowed.
+func (w *Workflow) CanTransition(from, to string) bool {
+	if from == to {
+		return true
+	}
+	for _, transition := range w.Transitions {
+		if transition.From == from && transition.To == to {
+			return true
+		}
+	}
+	return false
+}
+
+// FirstStatus returns the key of the first status in a category, or ""
+func (w *Workflow) FirstStatus(category string) string {
+	for _, status := range w.Statuses {
+		if status.Category == category {
+			return status.Key
+		}
+	}
+	return ""
+}
+
+// Completed reports whether a status completes a task. Every done status
+// does, matching the task_status_category SQL function that milestone and
+// sprint progress, statistics and the timeline are computed with.
+func (w *Workflow) Completed(key string) bool {
+	return w.Category(key) == StatusCategoryDone
+}
+
+// WorkflowRequest replaces the workflow of a project. Statuses are kept in
+// the given order; a missing transitions list allows every transition.
+// StatusMap moves tasks from statuses that are removed to new ones, e.g.
+// {"in_progress": "doing"}.
+type WorkflowRequest struct {
+	Statuses    []*WorkflowStatus     `json:"statuses"`
+	Transitions []*WorkflowTransition `json:"transitions"`
+	StatusMap   map[string]string     `json:"status_map"`
+}
+
+// WorkflowUpdateResponse represents a changed workflow and the number of
+// tasks moved to new statuses
+type WorkflowUpdateResponse struct {
+	Workflow      *Workflow `json:"workflow"`
+	MigratedTasks int       `json:"migrated_tasks"`
+}
\ No newline at end of file
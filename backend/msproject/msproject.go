@@ -0,0 +1,155 @@
+// Package msproject reads and writes Microsoft Project XML (MSPDI) files.
+// Only the elements needed to exchange task plans are mapped: tasks with
+// their outline, work, deadlines, progress and predecessor links, plus
+// resources and their assignments.
+package msproject
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Namespace is the MSPDI XML namespace
+const Namespace = "http://schemas.microsoft.com/project"
+
+// TimeLayout is the layout of MSPDI date and time values
+const TimeLayout = "2006-01-02T15:04:05"
+
+// Predecessor link types
+const (
+	LinkFinishToFinish = 0
+	LinkFinishToStart  = 1
+	LinkStartToFinish  = 2
+	LinkStartToStart   = 3
+)
+
+// Project is the root element of an MSPDI file
+type Project struct {
+	XMLName       xml.Name     `xml:"Project"`
+	Xmlns         string       `xml:"xmlns,attr,omitempty"`
+	Name          string       `xml:"Name,omitempty"`
+	Title         string       `xml:"Title,omitempty"`
+	StartDate     string       `xml:"StartDate,omitempty"`
+	FinishDate    string       `xml:"FinishDate,omitempty"`
+	MinutesPerDay int          `xml:"MinutesPerDay,omitempty"`
+	Tasks         []Task       `xml:"Tasks>Task"`
+	Resources     []Resource   `xml:"Resources>Resource"`
+	Assignments   []Assignment `xml:"Assignments>Assignment"`
+}
+
+// Task is one task of an MSPDI file. The outline level gives the hierarchy:
+// a task is the child of the closest preceding task one level up. Level 0
+// is the project summary task.
+type Task struct {
+	UID              int               `xml:"UID"`
+	ID               int               `xml:"ID"`
+	Name             string            `xml:"Name,omitempty"`
+	IsNull           int               `xml:"IsNull,omitempty"`
+	OutlineNumber    string            `xml:"OutlineNumber,omitempty"`
+	OutlineLevel     int               `xml:"OutlineLevel"`
+	Priority         int               `xml:"Priority,omitempty"`
+	Start            string            `xml:"Start,omitempty"`
+	Finish           string            `xml:"Finish,omitempty"`
+	Duration         string            `xml:"Duration,omitempty"`
+	Work             string            `xml:"Work,omitempty"`
+	Milestone        int               `xml:"Milestone"`
+	Summary          int               `xml:"Summary"`
+	PercentComplete  int               `xml:"PercentComplete"`
+	Deadline         string            `xml:"Deadline,omitempty"`
+	Notes            string            `xml:"Notes,omitempty"`
+	PredecessorLinks []PredecessorLink `xml:"PredecessorLink"`
+}
+
+// PredecessorLink makes a task wait for the task with PredecessorUID
+type PredecessorLink struct {
+	PredecessorUID int `xml:"PredecessorUID"`
+	Type           int `xml:"Type"`
+}
+
+// Resource is a person tasks can be assigned to
+type Resource struct {
+	UID          int    `xml:"UID"`
+	ID           int    `xml:"ID"`
+	Name         string `xml:"Name"`
+	Type         int    `xml:"Type"`
+	EmailAddress string `xml:"EmailAddress,omitempty"`
+}
+
+// Assignment assigns a resource to a task
+type Assignment struct {
+	UID         int    `xml:"UID"`
+	TaskUID     int    `xml:"TaskUID"`
+	ResourceUID int    `xml:"ResourceUID"`
+	Work        string `xml:"Work,omitempty"`
+}
+
+// Encode writes a project as an MSPDI document
+func Encode(w io.Writer, project *Project) error {
+	if project.Xmlns == "" {
+		project.Xmlns = Namespace
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(project); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// Decode reads an MSPDI document
+func Decode(r io.Reader) (*Project, error) {
+	project := &Project{}
+	if err := xml.NewDecoder(r).Decode(project); err != nil {
+		return nil, fmt.Errorf("invalid MSPDI document: %w", err)
+	}
+	return project, nil
+}
+
+// durationPattern matches the ISO 8601 durations used by MSPDI, e.g. PT16H0M0S
+var durationPattern = regexp.MustCompile(`^P(?:(\d+(?:\.\d+)?)D)?(?:T(?:(\d+(?:\.\d+)?)H)?(?:(\d+(?:\.\d+)?)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// FormatDuration formats a number of hours as an MSPDI duration
+func FormatDuration(hours float64) string {
+	seconds := int64(hours*3600 + 0.5)
+	return fmt.Sprintf("PT%dH%dM%dS", seconds/3600, seconds%3600/60, seconds%60)
+}
+
+// ParseDuration parses an MSPDI duration into hours. Days count as 24
+// hours; MS Project itself writes work in hours.
+func ParseDuration(value string) (float64, error) {
+	match := durationPattern.FindStringSubmatch(value)
+	if match == nil || value == "P" || value == "PT" {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	var hours float64
+	for i, scale := range []float64{24, 1, 1.0 / 60, 1.0 / 3600} {
+		if match[i+1] == "" {
+			continue
+		}
+		n, err := strconv.ParseFloat(match[i+1], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		hours += n * scale
+	}
+	return hours, nil
+}
+
+// FormatTime formats an MSPDI date and time
+func FormatTime(t time.Time) string {
+	return t.Format(TimeLayout)
+}
+
+// ParseTime parses an MSPDI date and time
+func ParseTime(value string) (time.Time, error) {
+	return time.Parse(TimeLayout, value)
+}
\ No newline at end of file
@@ -0,0 +1,599 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID     = "test-client"
+	testClientSecret = "test-secret"
+	testRedirectURL  = "http://localhost:8080/api/v1/auth/oidc/callback"
+)
+
+// pendingCode is an authorization code issued by the mock IdP
+type pendingCode struct {
+	nonce         string
+	codeChallenge string
+	redirectURI   string
+}
+
+// mockIdP is an in-process OpenID Connect provider. It issues codes from
+// its authorization endpoint, checks PKCE at its token endpoint and signs
+// ID tokens with an RSA key published in its JWKS document.
+type mockIdP struct {
+	t      *testing.T
+	server *httptest.Server
+	issuer string
+
+	mu           sync.Mutex
+	key          *rsa.PrivateKey
+	kid          string
+	codes        map[string]pendingCode
+	jwksRequests int
+
+	// mutate adjusts the claims of issued ID tokens
+	mutate func(claims jwt.MapClaims)
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	t.Helper()
+
+	idp := &mockIdP{
+		t:     t,
+		key:   generateKey(t),
+		kid:   "key-1",
+		codes: make(map[string]pendingCode),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", idp.handleDiscovery)
+	mux.HandleFunc("/authorize", idp.handleAuthorize)
+	mux.HandleFunc("/token", idp.handleToken)
+	mux.HandleFunc("/jwks", idp.handleJWKS)
+	idp.server = httptest.NewServer(mux)
+	idp.issuer = idp.server.URL
+	t.Cleanup(idp.server.Close)
+
+	return idp
+}
+
+func generateKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return key
+}
+
+func (idp *mockIdP) config() Config {
+	return Config{
+		IssuerURL:    idp.server.URL,
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURL:  testRedirectURL,
+	}
+}
+
+func (idp *mockIdP) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(Discovery{
+		Issuer:                idp.issuer,
+		AuthorizationEndpoint: idp.server.URL + "/authorize",
+		TokenEndpoint:         idp.server.URL + "/token",
+		JWKSURI:               idp.server.URL + "/jwks",
+	})
+}
+
+// handleAuthorize logs the user in straight away and redirects back with a code
+func (idp *mockIdP) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("response_type") != "code" || query.Get("client_id") != testClientID ||
+		query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+
+	code, err := randomURLString(16)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	idp.mu.Lock()
+	idp.codes[code] = pendingCode{
+		nonce:         query.Get("nonce"),
+		codeChallenge: query.Get("code_challenge"),
+		redirectURI:   query.Get("redirect_uri"),
+	}
+	idp.mu.Unlock()
+
+	redirect := query.Get("redirect_uri") + "?" + url.Values{"code": {code}, "state": {query.Get("state")}}.Encode()
+	http.Redirect(w, r, redirect, http.StatusFound)
+}
+
+func (idp *mockIdP) handleToken(w http.ResponseWriter, r *http.Request) {
+	tokenError := func(code string) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": code, "error_description": "rejected by mock IdP"})
+	}
+
+	if r.Method != http.MethodPost || r.ParseForm() != nil {
+		tokenError("invalid_request")
+		return
+	}
+	if r.PostForm.Get("client_id") != testClientID || r.PostForm.Get("client_secret") != testClientSecret {
+		tokenError("invalid_client")
+		return
+	}
+
+	idp.mu.Lock()
+	pending, ok := idp.codes[r.PostForm.Get("code")]
+	delete(idp.codes, r.PostForm.Get("code"))
+	idp.mu.Unlock()
+
+	if r.PostForm.Get("grant_type") != "authorization_code" || !ok ||
+		r.PostForm.Get("redirect_uri") != pending.redirectURI ||
+		CodeChallenge(r.PostForm.Get("code_verifier")) != pending.codeChallenge {
+		tokenError("invalid_grant")
+		return
+	}
+
+	claims := jwt.MapClaims{
+		"iss":                idp.issuer,
+		"sub":                "user-123",
+		"aud":                testClientID,
+		"exp":                time.Now().Add(time.Hour).Unix(),
+		"iat":                time.Now().Unix(),
+		"nonce":              pending.nonce,
+		"email":              "alice@example.com",
+		"email_verified":     true,
+		"preferred_username": "alice",
+		"name":               "Alice Example",
+		"groups":             []string{"developers", "admins"},
+	}
+	if idp.mutate != nil {
+		idp.mutate(claims)
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{
+		"access_token": "access-token",
+		"token_type":   "Bearer",
+		"id_token":     idp.sign(claims),
+	})
+}
+
+func (idp *mockIdP) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+
+	idp.jwksRequests++
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"keys": []jsonWebKey{{
+			Kty: "RSA",
+			Kid: idp.kid,
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(idp.key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(idp.key.E)).Bytes()),
+		}},
+	})
+}
+
+// sign signs claims with the IdP's current key
+func (idp *mockIdP) sign(claims jwt.MapClaims) string {
+	idp.mu.Lock()
+	key, kid := idp.key, idp.kid
+	idp.mu.Unlock()
+
+	return signToken(idp.t, key, kid, claims)
+}
+
+func signToken(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signed
+}
+
+// jwksFetches returns how often the JWKS document was fetched
+func (idp *mockIdP) jwksFetches() int {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	return idp.jwksRequests
+}
+
+// rotateKey replaces the IdP's signing key
+func (idp *mockIdP) rotateKey(kid string) {
+	key := generateKey(idp.t)
+
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	idp.key = key
+	idp.kid = kid
+}
+
+// login runs the browser side of the flow: it follows the authorization URL
+// and returns the code and state from the redirect back to the client
+func (idp *mockIdP) login(provider *Provider, state, nonce, codeChallenge string) (code, returnedState string) {
+	idp.t.Helper()
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Get(provider.AuthCodeURL(state, nonce, codeChallenge))
+	if err != nil {
+		idp.t.Fatalf("authorization request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusFound {
+		idp.t.Fatalf("authorization status = %d, want %d", resp.StatusCode, http.StatusFound)
+	}
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		idp.t.Fatalf("invalid redirect: %v", err)
+	}
+	if !strings.HasPrefix(location.String(), testRedirectURL+"?") {
+		idp.t.Fatalf("redirect = %s, want the callback URL", location)
+	}
+	return location.Query().Get("code"), location.Query().Get("state")
+}
+
+func newTestProvider(t *testing.T, idp *mockIdP) *Provider {
+	t.Helper()
+
+	provider, err := NewProvider(context.Background(), idp.config(), idp.server.Client())
+	if err != nil {
+		t.Fatalf("NewProvider returned error: %v", err)
+	}
+	return provider
+}
+
+func TestNewProviderDiscovery(t *testing.T) {
+	idp := newMockIdP(t)
+
+	provider := newTestProvider(t, idp)
+	if provider.discovery.TokenEndpoint != idp.server.URL+"/token" {
+		t.Errorf("TokenEndpoint = %q, want %q", provider.discovery.TokenEndpoint, idp.server.URL+"/token")
+	}
+
+	// A trailing slash on the configured issuer is ignored
+	config := idp.config()
+	config.IssuerURL += "/"
+	if _, err := NewProvider(context.Background(), config, idp.server.Client()); err != nil {
+		t.Errorf("NewProvider with trailing slash returned error: %v", err)
+	}
+}
+
+func TestNewProviderDiscoveryErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		document interface{}
+		status   int
+	}{
+		{
+			name:   "missing document",
+			status: http.StatusNotFound,
+		},
+		{
+			name:     "invalid document",
+			document: "not a discovery document",
+			status:   http.StatusOK,
+		},
+		{
+			name: "issuer mismatch",
+			document: Discovery{
+				Issuer:                "https://evil.example.com",
+				AuthorizationEndpoint: "https://evil.example.com/authorize",
+				TokenEndpoint:         "https://evil.example.com/token",
+				JWKSURI:               "https://evil.example.com/jwks",
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:     "missing endpoints",
+			document: map[string]string{"issuer": ""},
+			status:   http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var server *httptest.Server
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				document := tt.document
+				if missing, ok := document.(map[string]string); ok {
+					missing["issuer"] = server.URL
+				}
+				json.NewEncoder(w).Encode(document)
+			}))
+			defer server.Close()
+
+			config := Config{IssuerURL: server.URL, ClientID: testClientID}
+			if _, err := NewProvider(context.Background(), config, server.Client()); err == nil {
+				t.Error("NewProvider returned no error")
+			}
+		})
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := newTestProvider(t, idp)
+
+	authURL, err := url.Parse(provider.AuthCodeURL("state-1", "nonce-1", "challenge-1"))
+	if err != nil {
+		t.Fatalf("invalid authorization URL: %v", err)
+	}
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             testClientID,
+		"redirect_uri":          testRedirectURL,
+		"scope":                 "openid profile email",
+		"state":                 "state-1",
+		"nonce":                 "nonce-1",
+		"code_challenge":        "challenge-1",
+		"code_challenge_method": "S256",
+	}
+	for name, value := range want {
+		if got := authURL.Query().Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+	if authURL.Path != "/authorize" {
+		t.Errorf("path = %q, want /authorize", authURL.Path)
+	}
+}
+
+func TestCodeChallenge(t *testing.T) {
+	// RFC 7636 appendix B
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	want := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+	if got := CodeChallenge(verifier); got != want {
+		t.Errorf("CodeChallenge = %q, want %q", got, want)
+	}
+}
+
+func TestLoginFlow(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := newTestProvider(t, idp)
+	store := NewStateStore(time.Minute)
+
+	state, nonce, codeChallenge, err := store.Begin()
+	if err != nil {
+		t.Fatalf("Begin returned error: %v", err)
+	}
+	code, returnedState := idp.login(provider, state, nonce, codeChallenge)
+	if returnedState != state {
+		t.Fatalf("state = %q, want %q", returnedState, state)
+	}
+
+	login, err := store.Consume(returnedState)
+	if err != nil {
+		t.Fatalf("Consume returned error: %v", err)
+	}
+	if _, err := store.Consume(returnedState); err != ErrStateNotFound {
+		t.Errorf("second Consume error = %v, want ErrStateNotFound", err)
+	}
+
+	claims, err := provider.Exchange(context.Background(), code, login.CodeVerifier, login.Nonce)
+	if err != nil {
+		t.Fatalf("Exchange returned error: %v", err)
+	}
+	if claims.Subject != "user-123" || claims.Email != "alice@example.com" || !claims.EmailVerified ||
+		claims.PreferredUsername != "alice" || claims.Name != "Alice Example" || claims.Nonce != nonce {
+		t.Errorf("unexpected claims: %+v", claims)
+	}
+	if groups := claims.StringsClaim("groups"); len(groups) != 2 || groups[0] != "developers" || groups[1] != "admins" {
+		t.Errorf("groups = %v, want [developers admins]", groups)
+	}
+
+	// Codes are single use
+	if _, err := provider.Exchange(context.Background(), code, login.CodeVerifier, login.Nonce); err == nil {
+		t.Error("Exchange accepted a code twice")
+	}
+}
+
+func TestExchangeRejectsWrongCodeVerifier(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := newTestProvider(t, idp)
+	store := NewStateStore(time.Minute)
+
+	state, nonce, codeChallenge, err := store.Begin()
+	if err != nil {
+		t.Fatalf("Begin returned error: %v", err)
+	}
+	code, _ := idp.login(provider, state, nonce, codeChallenge)
+
+	_, err = provider.Exchange(context.Background(), code, "not-the-verifier", nonce)
+	if err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Errorf("Exchange error = %v, want invalid_grant", err)
+	}
+}
+
+func TestExchangeRejectsInvalidIDToken(t *testing.T) {
+	otherKey := generateKey(t)
+
+	tests := []struct {
+		name   string
+		mutate func(claims jwt.MapClaims)
+		nonce  string
+	}{
+		{
+			name:  "nonce mismatch",
+			nonce: "another-nonce",
+		},
+		{
+			name:   "missing nonce",
+			mutate: func(claims jwt.MapClaims) { delete(claims, "nonce") },
+		},
+		{
+			name:   "wrong audience",
+			mutate: func(claims jwt.MapClaims) { claims["aud"] = "another-client" },
+		},
+		{
+			name:   "missing audience",
+			mutate: func(claims jwt.MapClaims) { delete(claims, "aud") },
+		},
+		{
+			name:   "wrong issuer",
+			mutate: func(claims jwt.MapClaims) { claims["iss"] = "https://evil.example.com" },
+		},
+		{
+			name:   "expired",
+			mutate: func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Hour).Unix() },
+		},
+		{
+			name:   "missing expiry",
+			mutate: func(claims jwt.MapClaims) { delete(claims, "exp") },
+		},
+		{
+			name:   "missing issue time",
+			mutate: func(claims jwt.MapClaims) { delete(claims, "iat") },
+		},
+		{
+			name:   "issued in the future",
+			mutate: func(claims jwt.MapClaims) { claims["iat"] = time.Now().Add(time.Hour).Unix() },
+		},
+		{
+			name:   "missing subject",
+			mutate: func(claims jwt.MapClaims) { delete(claims, "sub") },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := newMockIdP(t)
+			idp.mutate = tt.mutate
+			provider := newTestProvider(t, idp)
+			store := NewStateStore(time.Minute)
+
+			state, nonce, codeChallenge, err := store.Begin()
+			if err != nil {
+				t.Fatalf("Begin returned error: %v", err)
+			}
+			code, _ := idp.login(provider, state, nonce, codeChallenge)
+			login, err := store.Consume(state)
+			if err != nil {
+				t.Fatalf("Consume returned error: %v", err)
+			}
+
+			expectedNonce := login.Nonce
+			if tt.nonce != "" {
+				expectedNonce = tt.nonce
+			}
+			if claims, err := provider.Exchange(context.Background(), code, login.CodeVerifier, expectedNonce); err == nil {
+				t.Errorf("Exchange accepted the ID token: %+v", claims)
+			}
+		})
+	}
+
+	t.Run("foreign signature", func(t *testing.T) {
+		idp := newMockIdP(t)
+		provider := newTestProvider(t, idp)
+
+		claims := jwt.MapClaims{
+			"iss":   idp.issuer,
+			"sub":   "user-123",
+			"aud":   testClientID,
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"iat":   time.Now().Unix(),
+			"nonce": "nonce-1",
+		}
+		if _, err := provider.VerifyIDToken(context.Background(), signToken(t, otherKey, idp.kid, claims), "nonce-1"); err == nil {
+			t.Error("VerifyIDToken accepted a token signed with another key")
+		}
+	})
+
+	t.Run("symmetric algorithm", func(t *testing.T) {
+		idp := newMockIdP(t)
+		provider := newTestProvider(t, idp)
+
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"iss":   idp.issuer,
+			"sub":   "user-123",
+			"aud":   testClientID,
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"iat":   time.Now().Unix(),
+			"nonce": "nonce-1",
+		})
+		token.Header["kid"] = idp.kid
+		signed, err := token.SignedString([]byte(testClientSecret))
+		if err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+		if _, err := provider.VerifyIDToken(context.Background(), signed, "nonce-1"); err == nil {
+			t.Error("VerifyIDToken accepted an HS256 token")
+		}
+	})
+}
+
+func TestVerifyIDTokenKeyRotation(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := newTestProvider(t, idp)
+
+	claims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":   idp.issuer,
+			"sub":   "user-123",
+			"aud":   testClientID,
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"iat":   time.Now().Unix(),
+			"nonce": "nonce-1",
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := provider.VerifyIDToken(context.Background(), idp.sign(claims()), "nonce-1"); err != nil {
+			t.Fatalf("VerifyIDToken returned error: %v", err)
+		}
+	}
+	if idp.jwksFetches() != 1 {
+		t.Errorf("JWKS fetched %d times, want 1", idp.jwksFetches())
+	}
+
+	// A token signed with a new key triggers one refresh of the key set
+	idp.rotateKey("key-2")
+	if _, err := provider.VerifyIDToken(context.Background(), idp.sign(claims()), "nonce-1"); err != nil {
+		t.Fatalf("VerifyIDToken after rotation returned error: %v", err)
+	}
+	if idp.jwksFetches() != 2 {
+		t.Errorf("JWKS fetched %d times, want 2", idp.jwksFetches())
+	}
+
+	// A token without a kid matches the only published key
+	if _, err := provider.VerifyIDToken(context.Background(), signToken(t, idp.key, "", claims()), "nonce-1"); err != nil {
+		t.Errorf("VerifyIDToken without kid returned error: %v", err)
+	}
+
+	// An unknown kid is rejected after one more refresh
+	if _, err := provider.VerifyIDToken(context.Background(), signToken(t, idp.key, "key-3", claims()), "nonce-1"); err == nil {
+		t.Error("VerifyIDToken accepted an unknown key ID")
+	}
+	if idp.jwksFetches() != 3 {
+		t.Errorf("JWKS fetched %d times, want 3", idp.jwksFetches())
+	}
+}
\ No newline at end of file
@@ -0,0 +1,101 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrStateNotFound is returned for unknown, reused or expired login states
+var ErrStateNotFound = errors.New("login state not found or expired")
+
+// LoginState is what the server remembers between the login redirect and the callback
+type LoginState struct {
+	Nonce        string
+	CodeVerifier string
+	ExpiresAt    time.Time
+}
+
+// StateStore keeps pending logins in memory, keyed by the state parameter.
+// Each state can be consumed only once.
+type StateStore struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	states map[string]LoginState
+}
+
+// NewStateStore creates a state store whose entries live for ttl
+func NewStateStore(ttl time.Duration) *StateStore {
+	return &StateStore{
+		ttl:    ttl,
+		states: make(map[string]LoginState),
+	}
+}
+
+// Begin creates a new pending login and returns its state, nonce and PKCE code challenge
+func (s *StateStore) Begin() (state, nonce, codeChallenge string, err error) {
+	if state, err = randomURLString(32); err != nil {
+		return "", "", "", err
+	}
+	if nonce, err = randomURLString(32); err != nil {
+		return "", "", "", err
+	}
+	verifier, err := randomURLString(48)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.purgeExpired()
+	s.states[state] = LoginState{
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(s.ttl),
+	}
+
+	return state, nonce, CodeChallenge(verifier), nil
+}
+
+// Consume returns and removes the pending login for a state
+func (s *StateStore) Consume(state string) (LoginState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	login, exists := s.states[state]
+	delete(s.states, state)
+	if !exists || time.Now().After(login.ExpiresAt) {
+		return LoginState{}, ErrStateNotFound
+	}
+
+	return login, nil
+}
+
+// purgeExpired drops abandoned logins; callers must hold the lock
+func (s *StateStore) purgeExpired() {
+	now := time.Now()
+	for state, login := range s.states {
+		if now.After(login.ExpiresAt) {
+			delete(s.states, state)
+		}
+	}
+}
+
+// CodeChallenge derives the S256 PKCE code challenge for a code verifier
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// randomURLString returns n random bytes encoded as unpadded base64url
+func randomURLString(n int) (string, error) {
+	bytes := make([]byte, n)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
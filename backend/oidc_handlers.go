@@ -0,0 +1,285 @@
+package main
+
+import (
+	"ai-project-backend/models"
+	"ai-project-backend/oidc"
+	"ai-project-backend/policy"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// oidcLoginTTL is how long a user has to complete the login at the identity provider
+const oidcLoginTTL = 10 * time.Minute
+
+// oidcState holds the lazily discovered identity provider and pending logins
+type oidcState struct {
+	mu       sync.Mutex
+	provider *oidc.Provider
+	logins   *oidc.StateStore
+}
+
+// invalidUsernameChars matches characters not allowed in provisioned usernames
+var invalidUsernameChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+
+// oidcProvider returns the identity provider, running discovery on first use
+func (app *Application) oidcProvider(ctx context.Context) (*oidc.Provider, error) {
+	app.oidc.mu.Lock()
+	defer app.oidc.mu.Unlock()
+
+	if app.oidc.provider != nil {
+		return app.oidc.provider, nil
+	}
+
+	provider, err := oidc.NewProvider(ctx, oidc.Config{
+		IssuerURL:    app.config.OIDC.IssuerURL,
+		ClientID:     app.config.OIDC.ClientID,
+		ClientSecret: app.config.OIDC.ClientSecret,
+		RedirectURL:  app.config.OIDC.RedirectURL,
+		Scopes:       app.config.OIDC.Scopes,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	app.oidc.provider = provider
+	return provider, nil
+}
+
+func (app *Application) oidcLoginHandler(c *gin.Context) {
+	if !app.config.OIDC.Enabled {
+		response := models.NewErrorResponse(models.ErrCodeNotFound, "Single sign-on is not enabled", nil)
+		c.JSON(http.StatusNotFound, response)
+		return
+	}
+
+	provider, err := app.oidcProvider(c.Request.Context())
+	if err != nil {
+		app.logger.Printf("Error initializing OIDC provider: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Identity provider is unavailable", nil)
+		c.JSON(http.StatusServiceUnavailable, response)
+		return
+	}
+
+	state, nonce, codeChallenge, err := app.oidc.logins.Begin()
+	if err != nil {
+		app.logger.Printf("Error starting OIDC login: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to start login", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	c.Redirect(http.StatusFound, provider.AuthCodeURL(state, nonce, codeChallenge))
+}
+
+func (app *Application) oidcCallbackHandler(c *gin.Context) {
+	if !app.config.OIDC.Enabled {
+		response := models.NewErrorResponse(models.ErrCodeNotFound, "Single sign-on is not enabled", nil)
+		c.JSON(http.StatusNotFound, response)
+		return
+	}
+
+	if errCode := c.Query("error"); errCode != "" {
+		response := models.NewErrorResponse(
+			models.ErrCodeAuthentication,
+			"Identity provider rejected the login",
+			map[string]string{"error": errCode, "error_description": c.Query("error_description")},
+		)
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	login, err := app.oidc.logins.Consume(c.Query("state"))
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeAuthentication, "Login session is invalid or has expired", nil)
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	code := c.Query("code")
+	if code == "" {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Authorization code is required", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	provider, err := app.oidcProvider(c.Request.Context())
+	if err != nil {
+		app.logger.Printf("Error initializing OIDC provider: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Identity provider is unavailable", nil)
+		c.JSON(http.StatusServiceUnavailable, response)
+		return
+	}
+
+	claims, err := provider.Exchange(c.Request.Context(), code, login.CodeVerifier, login.Nonce)
+	if err != nil {
+		app.logger.Printf("Error completing OIDC login: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeAuthentication, "Failed to verify identity", nil)
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	user, err := app.provisionOIDCUser(c, claims)
+	if err != nil {
+		app.logger.Printf("Error provisioning OIDC user: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to provision user", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	if !user.IsActive {
+		response := models.NewErrorResponse(models.ErrCodeAuthorization, "Account is deactivated", nil)
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	loginResponse, challenge, err := app.beginLogin(c, user)
+	if err != nil {
+		app.logger.Printf("Error issuing session: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to generate token", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	// Two-step login: the frontend posts the MFA token with a code to /auth/2fa/verify
+	if challenge != nil {
+		if redirectURL := app.config.OIDC.PostLoginRedirectURL; redirectURL != "" {
+			fragment := url.Values{}
+			fragment.Set("mfa_token", challenge.MFAToken)
+			fragment.Set("expires_at", challenge.ExpiresAt.Format(time.RFC3339))
+			c.Redirect(http.StatusFound, redirectURL+"#"+fragment.Encode())
+			return
+		}
+
+		response := models.NewSuccessResponse(challenge, "Two-factor authentication required")
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	entityData := map[string]interface{}{"provider": "oidc"}
+	if err := app.db.System().LogAction(c.Request.Context(), &user.ID, "LOGIN", "user", user.ID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
+	// Browser flow: hand the tokens to the frontend in the URL fragment
+	if redirectURL := app.config.OIDC.PostLoginRedirectURL; redirectURL != "" {
+		fragment := url.Values{}
+		fragment.Set("token", loginResponse.Token)
+		fragment.Set("refresh_token", loginResponse.RefreshToken)
+		fragment.Set("expires_at", loginResponse.ExpiresAt.Format(time.RFC3339))
+		c.Redirect(http.StatusFound, redirectURL+"#"+fragment.Encode())
+		return
+	}
+
+	response := models.NewSuccessResponse(loginResponse, "Login successful")
+	c.JSON(http.StatusOK, response)
+}
+
+// oidcRole maps the identity provider's group claim to a user role
+func (app *Application) oidcRole(claims *oidc.IDTokenClaims) string {
+	for _, group := range claims.StringsClaim(app.config.OIDC.GroupsClaim) {
+		for _, adminGroup := range app.config.OIDC.AdminGroups {
+			if group == adminGroup {
+				return policy.RoleAdmin
+			}
+		}
+	}
+	return policy.RoleUser
+}
+
+// provisionOIDCUser finds the user linked to the ID token subject, creating it on
+// first login. The role is re-synced from the group claim on every login.
+func (app *Application) provisionOIDCUser(c *gin.Context, claims *oidc.IDTokenClaims) (*models.User, error) {
+	ctx := c.Request.Context()
+	role := app.oidcRole(claims)
+
+	user, err := app.db.Users().GetByExternalID(ctx, "oidc", claims.Subject)
+	if err == nil {
+		if user.Role != role {
+			user.Role = role
+			if user, err = app.db.Users().Update(ctx, user); err != nil {
+				return nil, err
+			}
+		}
+		return user, nil
+	}
+	if err.Error() != "user not found" {
+		return nil, err
+	}
+
+	username, err := app.availableUsername(ctx, oidcUsername(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	externalID := claims.Subject
+	user = &models.User{
+		Username:     username,
+		PasswordHash: "", // SSO users cannot log in with a password
+		Role:         role,
+		AuthProvider: "oidc",
+		ExternalID:   &externalID,
+	}
+
+	user, err = app.db.Users().Create(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	entityData := map[string]interface{}{"provider": "oidc", "username": user.Username, "role": user.Role}
+	if err := app.db.System().LogAction(ctx, &user.ID, "CREATE", "user", user.ID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
+	return user, nil
+}
+
+// oidcUsername picks a username candidate from the ID token claims
+func oidcUsername(claims *oidc.IDTokenClaims) string {
+	candidate := claims.PreferredUsername
+	if candidate == "" && claims.Email != "" {
+		candidate = strings.SplitN(claims.Email, "@", 2)[0]
+	}
+
+	candidate = invalidUsernameChars.ReplaceAllString(candidate, "_")
+	if len(candidate) > 40 {
+		candidate = candidate[:40]
+	}
+	if len(candidate) < 3 {
+		subject := invalidUsernameChars.ReplaceAllString(claims.Subject, "")
+		if len(subject) > 8 {
+			subject = subject[:8]
+		}
+		candidate = "sso_" + subject
+	}
+
+	return candidate
+}
+
+// availableUsername appends a numeric suffix until the username is unused
+func (app *Application) availableUsername(ctx context.Context, base string) (string, error) {
+	for i := 1; i <= 100; i++ {
+		candidate := base
+		if i > 1 {
+			candidate = base + "_" + strconv.Itoa(i)
+		}
+
+		_, err := app.db.Users().GetByUsername(ctx, candidate)
+		if err != nil && err.Error() == "user not found" {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return "", fmt.Errorf("no available username for %q", base)
+}
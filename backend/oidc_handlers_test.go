@@ -0,0 +1,422 @@
+package main
+
+import (
+	"ai-project-backend/config"
+	"ai-project-backend/models"
+	"ai-project-backend/oidc"
+	"ai-project-backend/policy"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testOIDCClientID = "test-client"
+
+// testIdP is an identity provider serving discovery, JWKS and a token
+// endpoint that returns an ID token with the configured claims for any code
+type testIdP struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	// claims are the claims of the next issued ID token
+	claims jwt.MapClaims
+}
+
+func newTestIdP(t *testing.T) *testIdP {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	idp := &testIdP{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "key-1",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(idp.key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(idp.key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, idp.claims)
+		token.Header["kid"] = "key-1"
+		signed, err := token.SignedString(idp.key)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{
+			"access_token": "access-token",
+			"token_type":   "Bearer",
+			"id_token":     signed,
+		})
+	})
+
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+// newOIDCTestApp creates an application with single sign-on through idp.
+// Members of the "admins" group become admins.
+func newOIDCTestApp(t *testing.T, idp *testIdP, db *fakeDB) *Application {
+	t.Helper()
+
+	app := newTestApplication(db)
+	app.config = &config.Config{
+		JWT: config.JWTConfig{RefreshExpiration: time.Hour},
+		OIDC: config.OIDCConfig{
+			Enabled:     true,
+			IssuerURL:   idp.server.URL,
+			ClientID:    testOIDCClientID,
+			RedirectURL: "http://localhost:8080/api/v1/auth/oidc/callback",
+			GroupsClaim: "groups",
+			AdminGroups: []string{"admins"},
+		},
+	}
+
+	provider, err := oidc.NewProvider(context.Background(), oidc.Config{
+		IssuerURL:   idp.server.URL,
+		ClientID:    testOIDCClientID,
+		RedirectURL: app.config.OIDC.RedirectURL,
+	}, idp.server.Client())
+	if err != nil {
+		t.Fatalf("NewProvider returned error: %v", err)
+	}
+	app.oidc = &oidcState{provider: provider, logins: oidc.NewStateStore(oidcLoginTTL)}
+	return app
+}
+
+// oidcLogin starts a login whose ID token is for the subject user-123 in the
+// given groups, and returns the callback URL. mutate may adjust the claims.
+func oidcLogin(t *testing.T, app *Application, idp *testIdP, groups []string, mutate func(claims jwt.MapClaims)) string {
+	t.Helper()
+
+	state, nonce, _, err := app.oidc.logins.Begin()
+	if err != nil {
+		t.Fatalf("Begin returned error: %v", err)
+	}
+	idp.claims = jwt.MapClaims{
+		"iss":                idp.server.URL,
+		"sub":                "user-123",
+		"aud":                testOIDCClientID,
+		"exp":                time.Now().Add(time.Hour).Unix(),
+		"iat":                time.Now().Unix(),
+		"nonce":              nonce,
+		"email":              "alice@example.com",
+		"preferred_username": "alice",
+		"groups":             groups,
+	}
+	if mutate != nil {
+		mutate(idp.claims)
+	}
+
+	return "/auth/oidc/callback?" + url.Values{"code": {"code-1"}, "state": {state}}.Encode()
+}
+
+// oidcCallback completes a login started with oidcLogin
+func oidcCallback(t *testing.T, app *Application, idp *testIdP, groups []string, mutate func(claims jwt.MapClaims)) *httptest.ResponseRecorder {
+	t.Helper()
+
+	target := oidcLogin(t, app, idp, groups, mutate)
+	return serve(t, nil, http.MethodGet, target, nil, app.oidcCallbackHandler)
+}
+
+// loginResponse decodes the login response of a successful callback
+func loginResponse(t *testing.T, recorder *httptest.ResponseRecorder) *models.LoginResponse {
+	t.Helper()
+
+	var body struct {
+		Data models.LoginResponse `json:"data"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid response body: %v", err)
+	}
+	if body.Data.Token == "" || body.Data.RefreshToken == "" {
+		t.Fatalf("response has no tokens: %s", recorder.Body.String())
+	}
+	return &body.Data
+}
+
+// auditActions lists the actions of the collected audit entries
+func auditActions(db *fakeDB) []string {
+	var actions []string
+	for _, entry := range db.system.audit {
+		actions = append(actions, entry.Action)
+	}
+	return actions
+}
+
+func TestOIDCCallbackProvisionsUser(t *testing.T) {
+	tests := []struct {
+		name   string
+		groups []string
+		role   string
+	}{
+		{name: "user", groups: []string{"developers"}, role: policy.RoleUser},
+		{name: "admin group", groups: []string{"developers", "admins"}, role: policy.RoleAdmin},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := newTestIdP(t)
+			db := newFakeDB()
+			app := newOIDCTestApp(t, idp, db)
+
+			recorder := oidcCallback(t, app, idp, tt.groups, nil)
+			requireStatus(t, recorder, http.StatusOK)
+
+			user := loginResponse(t, recorder).User
+			if user.Username != "alice" || user.Role != tt.role || user.AuthProvider != "oidc" {
+				t.Errorf("user = %s/%s/%s, want alice/%s/oidc", user.Username, user.Role, user.AuthProvider, tt.role)
+			}
+			stored, err := db.users.GetByExternalID(context.Background(), "oidc", "user-123")
+			if err != nil || stored.ID != user.ID {
+				t.Errorf("user not linked to the ID token subject: %v", err)
+			}
+			if stored.PasswordHash != "" {
+				t.Error("provisioned user has a password")
+			}
+			if len(db.sessions.created) != 1 || db.sessions.created[0].UserID != user.ID {
+				t.Errorf("sessions = %+v, want one session for the user", db.sessions.created)
+			}
+			if actions := strings.Join(auditActions(db), ","); actions != "CREATE,LOGIN" {
+				t.Errorf("audit actions = %s, want CREATE,LOGIN", actions)
+			}
+		})
+	}
+}
+
+func TestOIDCCallbackLinkedUser(t *testing.T) {
+	tests := []struct {
+		name   string
+		role   string
+		groups []string
+		want   string
+	}{
+		{name: "keeps role", role: policy.RoleUser, groups: []string{"developers"}, want: policy.RoleUser},
+		{name: "promotes", role: policy.RoleUser, groups: []string{"admins"}, want: policy.RoleAdmin},
+		{name: "demotes", role: policy.RoleAdmin, groups: nil, want: policy.RoleUser},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := newTestIdP(t)
+			db := newFakeDB()
+			app := newOIDCTestApp(t, idp, db)
+
+			externalID := "user-123"
+			id := db.users.add(&models.User{
+				Username:     "alice.smith",
+				Role:         tt.role,
+				AuthProvider: "oidc",
+				ExternalID:   &externalID,
+				IsActive:     true,
+			})
+
+			// The subject links the login to the existing account even
+			// though the preferred username has changed
+			recorder := oidcCallback(t, app, idp, tt.groups, nil)
+			requireStatus(t, recorder, http.StatusOK)
+
+			user := loginResponse(t, recorder).User
+			if user.ID != id || user.Username != "alice.smith" {
+				t.Errorf("logged in as %d/%s, want %d/alice.smith", user.ID, user.Username, id)
+			}
+			if len(db.users.users) != 1 {
+				t.Errorf("%d users, want the linked user only", len(db.users.users))
+			}
+			if user.Role != tt.want || db.users.users[id].Role != tt.want {
+				t.Errorf("role = %s, stored %s, want %s", user.Role, db.users.users[id].Role, tt.want)
+			}
+			if actions := strings.Join(auditActions(db), ","); actions != "LOGIN" {
+				t.Errorf("audit actions = %s, want LOGIN", actions)
+			}
+		})
+	}
+}
+
+func TestOIDCCallbackDoesNotLinkLocalAccount(t *testing.T) {
+	idp := newTestIdP(t)
+	db := newFakeDB()
+	app := newOIDCTestApp(t, idp, db)
+
+	localID := db.users.add(&models.User{
+		Username:     "alice",
+		PasswordHash: "hash",
+		Role:         policy.RoleAdmin,
+		AuthProvider: "local",
+		IsActive:     true,
+	})
+
+	recorder := oidcCallback(t, app, idp, nil, nil)
+	requireStatus(t, recorder, http.StatusOK)
+
+	user := loginResponse(t, recorder).User
+	if user.ID == localID {
+		t.Fatal("login was linked to the local account with the same username")
+	}
+	if user.Username != "alice_2" || user.Role != policy.RoleUser {
+		t.Errorf("user = %s/%s, want alice_2/user", user.Username, user.Role)
+	}
+	if local := db.users.users[localID]; local.ExternalID != nil || local.Role != policy.RoleAdmin {
+		t.Errorf("local account changed: %+v", local)
+	}
+}
+
+func TestOIDCCallbackDeactivatedUser(t *testing.T) {
+	idp := newTestIdP(t)
+	db := newFakeDB()
+	app := newOIDCTestApp(t, idp, db)
+
+	externalID := "user-123"
+	db.users.add(&models.User{
+		Username:     "alice",
+		Role:         policy.RoleUser,
+		AuthProvider: "oidc",
+		ExternalID:   &externalID,
+	})
+
+	recorder := oidcCallback(t, app, idp, nil, nil)
+	requireStatus(t, recorder, http.StatusForbidden)
+	if len(db.sessions.created) != 0 {
+		t.Error("a session was issued to a deactivated user")
+	}
+}
+
+func TestOIDCCallbackTwoFactor(t *testing.T) {
+	idp := newTestIdP(t)
+	db := newFakeDB()
+	app := newOIDCTestApp(t, idp, db)
+
+	externalID := "user-123"
+	db.users.add(&models.User{
+		Username:     "alice",
+		Role:         policy.RoleUser,
+		AuthProvider: "oidc",
+		ExternalID:   &externalID,
+		TOTPEnabled:  true,
+		IsActive:     true,
+	})
+
+	recorder := oidcCallback(t, app, idp, nil, nil)
+	requireStatus(t, recorder, http.StatusOK)
+
+	var body struct {
+		Data models.MFAChallengeResponse `json:"data"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid response body: %v", err)
+	}
+	if !body.Data.MFARequired || body.Data.MFAToken == "" {
+		t.Errorf("response = %s, want a two-factor challenge", recorder.Body.String())
+	}
+	if len(db.sessions.created) != 0 {
+		t.Error("a session was issued before the second factor")
+	}
+}
+
+func TestOIDCCallbackRedirect(t *testing.T) {
+	idp := newTestIdP(t)
+	db := newFakeDB()
+	app := newOIDCTestApp(t, idp, db)
+	app.config.OIDC.PostLoginRedirectURL = "http://localhost:3000/sso"
+
+	recorder := oidcCallback(t, app, idp, nil, nil)
+	requireStatus(t, recorder, http.StatusFound)
+
+	location, err := url.Parse(recorder.Header().Get("Location"))
+	if err != nil {
+		t.Fatalf("invalid redirect: %v", err)
+	}
+	fragment, err := url.ParseQuery(location.Fragment)
+	if err != nil {
+		t.Fatalf("invalid fragment: %v", err)
+	}
+	if location.Host != "localhost:3000" || fragment.Get("token") == "" || fragment.Get("refresh_token") == "" {
+		t.Errorf("redirect = %s, want the frontend with tokens in the fragment", location)
+	}
+	if location.RawQuery != "" {
+		t.Errorf("redirect puts data in the query: %s", location.RawQuery)
+	}
+}
+
+func TestOIDCCallbackRejected(t *testing.T) {
+	t.Run("identity provider error", func(t *testing.T) {
+		app := newOIDCTestApp(t, newTestIdP(t), newFakeDB())
+
+		recorder := serve(t, nil, http.MethodGet, "/auth/oidc/callback?error=access_denied", nil, app.oidcCallbackHandler)
+		requireStatus(t, recorder, http.StatusUnauthorized)
+	})
+
+	t.Run("unknown state", func(t *testing.T) {
+		app := newOIDCTestApp(t, newTestIdP(t), newFakeDB())
+
+		recorder := serve(t, nil, http.MethodGet, "/auth/oidc/callback?code=code-1&state=unknown", nil, app.oidcCallbackHandler)
+		requireStatus(t, recorder, http.StatusUnauthorized)
+	})
+
+	t.Run("state used twice", func(t *testing.T) {
+		idp := newTestIdP(t)
+		app := newOIDCTestApp(t, idp, newFakeDB())
+
+		target := oidcLogin(t, app, idp, nil, nil)
+		requireStatus(t, serve(t, nil, http.MethodGet, target, nil, app.oidcCallbackHandler), http.StatusOK)
+		requireStatus(t, serve(t, nil, http.MethodGet, target, nil, app.oidcCallbackHandler), http.StatusUnauthorized)
+	})
+
+	tests := []struct {
+		name   string
+		mutate func(claims jwt.MapClaims)
+	}{
+		{name: "nonce mismatch", mutate: func(claims jwt.MapClaims) { claims["nonce"] = "another-nonce" }},
+		{name: "wrong audience", mutate: func(claims jwt.MapClaims) { claims["aud"] = "another-client" }},
+		{name: "missing issue time", mutate: func(claims jwt.MapClaims) { delete(claims, "iat") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := newTestIdP(t)
+			db := newFakeDB()
+			app := newOIDCTestApp(t, idp, db)
+
+			recorder := oidcCallback(t, app, idp, nil, tt.mutate)
+			requireStatus(t, recorder, http.StatusUnauthorized)
+			if len(db.users.users) != 0 {
+				t.Error("a user was provisioned for a rejected ID token")
+			}
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		app := newOIDCTestApp(t, newTestIdP(t), newFakeDB())
+		app.config.OIDC.Enabled = false
+
+		recorder := serve(t, nil, http.MethodGet, "/auth/oidc/callback?code=code-1&state=x", nil, app.oidcCallbackHandler)
+		requireStatus(t, recorder, http.StatusNotFound)
+	})
+}
\ No newline at end of file
@@ -0,0 +1,238 @@
+package policy
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// User roles as stored in users.role
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
+// Action identifies an operation that is subject to role-based authorization
+type Action string
+
+// Actions guarded by the default policy
+const (
+	ActionRecycleList       Action = "recycle:list"
+	ActionRecycleRestore    Action = "recycle:restore"
+	ActionRecycleHardDelete Action = "recycle:hard_delete"
+	ActionAuditRead         Action = "audit:read"
+	ActionSettingsManage    Action = "settings:manage"
+	ActionUserManage        Action = "users:manage"
+)
+
+// ErrForbidden is returned when a role is not allowed to perform an action
+var ErrForbidden = errors.New("role is not allowed to perform this action")
+
+// Policy maps actions to the roles allowed to perform them.
+// Actions without a rule are denied for every role.
+type Policy struct {
+	mu    sync.RWMutex
+	rules map[Action]map[string]bool
+}
+
+// NewPolicy creates an empty policy that denies everything
+func NewPolicy() *Policy {
+	return &Policy{
+		rules: make(map[Action]map[string]bool),
+	}
+}
+
+// DefaultPolicy creates the policy used by the API server
+func DefaultPolicy() *Policy {
+	p := NewPolicy()
+
+	// Recycle bin and audit log are admin-only
+	p.Allow(ActionRecycleList, RoleAdmin)
+	p.Allow(ActionRecycleRestore, RoleAdmin)
+	p.Allow(ActionRecycleHardDelete, RoleAdmin)
+	p.Allow(ActionAuditRead, RoleAdmin)
+
+	// System settings such as the two-factor policy are admin-only
+	p.Allow(ActionSettingsManage, RoleAdmin)
+
+	// User administration is admin-only
+	p.Allow(ActionUserManage, RoleAdmin)
+
+	return p
+}
+
+// Allow grants the given roles permission to perform an action
+func (p *Policy) Allow(action Action, roles ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	allowed, exists := p.rules[action]
+	if !exists {
+		allowed = make(map[string]bool)
+		p.rules[action] = allowed
+	}
+	for _, role := range roles {
+		allowed[role] = true
+	}
+}
+
+// Revoke removes permission for the given roles to perform an action
+func (p *Policy) Revoke(action Action, roles ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	allowed, exists := p.rules[action]
+	if !exists {
+		return
+	}
+	for _, role := range roles {
+		delete(allowed, role)
+	}
+}
+
+// Allows reports whether the role may perform the action
+func (p *Policy) Allows(role string, action Action) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.rules[action][role]
+}
+
+// Authorize returns ErrForbidden if the role may not perform the action
+func (p *Policy) Authorize(role string, action Action) error {
+	if !p.Allows(role, action) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// Roles returns the roles allowed to perform the action
+func (p *Policy) Roles(action Action) []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var roles []string
+	for role, allowed := range p.rules[action] {
+		if allowed {
+			roles = append(roles, role)
+		}
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// Project membership roles as stored in project_members.role
+const (
+	ProjectRoleOwner      = "owner"
+	ProjectRoleMaintainer = "maintainer"
+	ProjectRoleMember     = "member"
+	ProjectRoleViewer     = "viewer"
+)
+
+// projectRoleRank orders project roles by privilege
+var projectRoleRank = map[string]int{
+	ProjectRoleViewer:     1,
+	ProjectRoleMember:     2,
+	ProjectRoleMaintainer: 3,
+	ProjectRoleOwner:      4,
+}
+
+// IsValidProjectRole reports whether role is a known project role
+func IsValidProjectRole(role string) bool {
+	_, ok := projectRoleRank[role]
+	return ok
+}
+
+// HasProjectRole reports whether a member with role has at least the required role
+func HasProjectRole(role, required string) bool {
+	rank, ok := projectRoleRank[role]
+	if !ok {
+		return false
+	}
+	return rank >= projectRoleRank[required]
+}
+
+// CanManageMember reports whether a member with actorRole may assign targetRole
+// to someone who currently holds currentRole (empty for new members).
+// Owners may manage anyone; maintainers may only manage members and viewers.
+func CanManageMember(actorRole, currentRole, targetRole string) bool {
+	if actorRole == ProjectRoleOwner {
+		return true
+	}
+	if actorRole != ProjectRoleMaintainer {
+		return false
+	}
+	if currentRole != "" && HasProjectRole(currentRole, ProjectRoleMaintainer) {
+		return false
+	}
+	return targetRole == "" || !HasProjectRole(targetRole, ProjectRoleMaintainer)
+}
+
+// Scope limits what a personal API token may do
+type Scope string
+
+// Scopes that can be granted to personal API tokens
+const (
+	ScopeProjectsRead  Scope = "projects:read"
+	ScopeProjectsWrite Scope = "projects:write"
+	ScopeTasksRead     Scope = "tasks:read"
+	ScopeTasksWrite    Scope = "tasks:write"
+)
+
+// validScopes lists every scope a token may be granted
+var validScopes = map[Scope]bool{
+	ScopeProjectsRead:  true,
+	ScopeProjectsWrite: true,
+	ScopeTasksRead:     true,
+	ScopeTasksWrite:    true,
+}
+
+// IsValidScope reports whether scope is a known token scope
+func IsValidScope(scope string) bool {
+	return validScopes[Scope(scope)]
+}
+
+// RequiredScope returns the scope a token needs to call the route given by
+// its HTTP method and gin route pattern. Routes that return false are not
+// available to API tokens at all.
+func RequiredScope(method, route string) (Scope, bool) {
+	for _, prefix := range []string{"/api/v1", "/api"} {
+		if strings.HasPrefix(route, prefix+"/") {
+			route = strings.TrimPrefix(route, prefix)
+			break
+		}
+	}
+
+	read := method == "GET" || method == "HEAD"
+
+	switch {
+	case strings.HasPrefix(route, "/projects/:id/tasks"), strings.HasPrefix(route, "/tasks"):
+		if read {
+			return ScopeTasksRead, true
+		}
+		return ScopeTasksWrite, true
+	case strings.HasPrefix(route, "/projects"):
+		if read {
+			return ScopeProjectsRead, true
+		}
+		return ScopeProjectsWrite, true
+	}
+
+	return "", false
+}
+
+// HasScope reports whether the granted scopes include the required one.
+// A write scope implies the matching read scope.
+func HasScope(granted []string, required Scope) bool {
+	for _, scope := range granted {
+		if Scope(scope) == required {
+			return true
+		}
+		if strings.HasSuffix(string(required), ":read") &&
+			Scope(scope) == Scope(strings.TrimSuffix(string(required), ":read")+":write") {
+			return true
+		}
+	}
+	return false
+}
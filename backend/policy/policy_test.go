@@ -0,0 +1,80 @@
+package policy
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDefaultPolicy(t *testing.T) {
+	actions := []Action{
+		ActionRecycleList,
+		ActionRecycleRestore,
+		ActionRecycleHardDelete,
+		ActionAuditRead,
+		ActionSettingsManage,
+		ActionUserManage,
+	}
+	p := DefaultPolicy()
+
+	for _, action := range actions {
+		tests := []struct {
+			role    string
+			allowed bool
+		}{
+			{role: RoleAdmin, allowed: true},
+			{role: RoleUser, allowed: false},
+			{role: "", allowed: false},
+			{role: "superuser", allowed: false},
+		}
+		for _, tt := range tests {
+			if got := p.Allows(tt.role, action); got != tt.allowed {
+				t.Errorf("Allows(%q, %q) = %v, want %v", tt.role, action, got, tt.allowed)
+			}
+			err := p.Authorize(tt.role, action)
+			if tt.allowed && err != nil {
+				t.Errorf("Authorize(%q, %q) = %v, want nil", tt.role, action, err)
+			}
+			if !tt.allowed && !errors.Is(err, ErrForbidden) {
+				t.Errorf("Authorize(%q, %q) = %v, want ErrForbidden", tt.role, action, err)
+			}
+		}
+		if roles := p.Roles(action); !reflect.DeepEqual(roles, []string{RoleAdmin}) {
+			t.Errorf("Roles(%q) = %v, want [admin]", action, roles)
+		}
+	}
+
+	if p.Allows(RoleAdmin, Action("unknown:action")) {
+		t.Error("Allows(admin, unknown:action) = true, want false")
+	}
+}
+
+func TestAllowRevoke(t *testing.T) {
+	p := NewPolicy()
+	action := Action("reports:read")
+
+	if p.Allows(RoleUser, action) {
+		t.Fatal("new policy allows an action")
+	}
+
+	p.Allow(action, RoleUser, RoleAdmin)
+	if !p.Allows(RoleUser, action) || !p.Allows(RoleAdmin, action) {
+		t.Error("Allow did not grant the action to every role")
+	}
+	if roles := p.Roles(action); !reflect.DeepEqual(roles, []string{RoleAdmin, RoleUser}) {
+		t.Errorf("Roles = %v, want [admin user]", roles)
+	}
+
+	p.Revoke(action, RoleUser)
+	if p.Allows(RoleUser, action) {
+		t.Error("Revoke did not remove the role")
+	}
+	if !p.Allows(RoleAdmin, action) {
+		t.Error("Revoke removed another role")
+	}
+
+	p.Revoke(Action("never:granted"), RoleAdmin)
+	if roles := p.Roles(Action("never:granted")); roles != nil {
+		t.Errorf("Roles of an unknown action = %v, want nil", roles)
+	}
+}
\ No newline at end of file
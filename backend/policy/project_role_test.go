@@ -0,0 +1,65 @@
+package policy
+
+import "testing"
+
+func TestHasProjectRole(t *testing.T) {
+	roles := []string{ProjectRoleViewer, ProjectRoleMember, ProjectRoleMaintainer, ProjectRoleOwner}
+
+	for i, role := range roles {
+		if !IsValidProjectRole(role) {
+			t.Errorf("IsValidProjectRole(%q) = false", role)
+		}
+		for j, required := range roles {
+			if got, want := HasProjectRole(role, required), i >= j; got != want {
+				t.Errorf("HasProjectRole(%q, %q) = %v, want %v", role, required, got, want)
+			}
+		}
+	}
+
+	for _, role := range []string{"", "admin", "Owner"} {
+		if IsValidProjectRole(role) {
+			t.Errorf("IsValidProjectRole(%q) = true", role)
+		}
+		if HasProjectRole(role, ProjectRoleViewer) {
+			t.Errorf("HasProjectRole(%q, viewer) = true", role)
+		}
+	}
+}
+
+func TestCanManageMember(t *testing.T) {
+	tests := []struct {
+		actor, current, target string
+		want                   bool
+	}{
+		// Owners manage anyone
+		{ProjectRoleOwner, "", ProjectRoleOwner, true},
+		{ProjectRoleOwner, "", ProjectRoleMaintainer, true},
+		{ProjectRoleOwner, ProjectRoleOwner, ProjectRoleViewer, true},
+		{ProjectRoleOwner, ProjectRoleMaintainer, "", true},
+
+		// Maintainers manage members and viewers only
+		{ProjectRoleMaintainer, "", ProjectRoleMember, true},
+		{ProjectRoleMaintainer, "", ProjectRoleViewer, true},
+		{ProjectRoleMaintainer, ProjectRoleViewer, ProjectRoleMember, true},
+		{ProjectRoleMaintainer, ProjectRoleMember, ProjectRoleViewer, true},
+		{ProjectRoleMaintainer, ProjectRoleMember, "", true},
+		{ProjectRoleMaintainer, "", ProjectRoleMaintainer, false},
+		{ProjectRoleMaintainer, "", ProjectRoleOwner, false},
+		{ProjectRoleMaintainer, ProjectRoleMember, ProjectRoleMaintainer, false},
+		{ProjectRoleMaintainer, ProjectRoleMaintainer, ProjectRoleMember, false},
+		{ProjectRoleMaintainer, ProjectRoleMaintainer, "", false},
+		{ProjectRoleMaintainer, ProjectRoleOwner, ProjectRoleViewer, false},
+
+		// Everyone else manages no one
+		{ProjectRoleMember, "", ProjectRoleViewer, false},
+		{ProjectRoleMember, ProjectRoleViewer, "", false},
+		{ProjectRoleViewer, "", ProjectRoleViewer, false},
+		{"", "", ProjectRoleViewer, false},
+	}
+
+	for _, tt := range tests {
+		if got := CanManageMember(tt.actor, tt.current, tt.target); got != tt.want {
+			t.Errorf("CanManageMember(%q, %q, %q) = %v, want %v", tt.actor, tt.current, tt.target, got, tt.want)
+		}
+	}
+}
\ No newline at end of file
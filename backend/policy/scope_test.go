@@ -0,0 +1,80 @@
+package policy
+
+import "testing"
+
+func TestRequiredScope(t *testing.T) {
+	tests := []struct {
+		method, route string
+		want          Scope
+		ok            bool
+	}{
+		{"GET", "/api/v1/projects", ScopeProjectsRead, true},
+		{"POST", "/api/v1/projects", ScopeProjectsWrite, true},
+		{"GET", "/api/v1/projects/:id", ScopeProjectsRead, true},
+		{"HEAD", "/api/v1/projects/:id", ScopeProjectsRead, true},
+		{"PUT", "/api/v1/projects/:id", ScopeProjectsWrite, true},
+		{"DELETE", "/api/v1/projects/:id", ScopeProjectsWrite, true},
+		{"GET", "/api/v1/projects/:id/members", ScopeProjectsRead, true},
+		{"GET", "/api/v1/projects/:id/tasks", ScopeTasksRead, true},
+		{"POST", "/api/v1/projects/:id/tasks", ScopeTasksWrite, true},
+		{"POST", "/api/v1/projects/:id/tasks/bulk-import", ScopeTasksWrite, true},
+		{"GET", "/api/v1/projects/:id/tasks/:taskId", ScopeTasksRead, true},
+		{"PUT", "/api/v1/projects/:id/tasks/:taskId/status", ScopeTasksWrite, true},
+		{"PATCH", "/api/v1/projects/:id/tasks/:taskId", ScopeTasksWrite, true},
+		{"GET", "/api/v1/tasks/mine", ScopeTasksRead, true},
+		{"GET", "/api/projects", ScopeProjectsRead, true},
+		{"POST", "/api/tasks", ScopeTasksWrite, true},
+		{"GET", "/projects", ScopeProjectsRead, true},
+		{"GET", "/api/v1/users", "", false},
+		{"POST", "/api/v1/auth/login", "", false},
+		{"GET", "/api/v1/search", "", false},
+		{"GET", "/health", "", false},
+		{"GET", "/api/v1/me", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := RequiredScope(tt.method, tt.route)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("RequiredScope(%q, %q) = %q, %v, want %q, %v", tt.method, tt.route, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestHasScope(t *testing.T) {
+	tests := []struct {
+		granted  []string
+		required Scope
+		want     bool
+	}{
+		{[]string{"projects:read"}, ScopeProjectsRead, true},
+		{[]string{"projects:write"}, ScopeProjectsRead, true},
+		{[]string{"projects:write"}, ScopeProjectsWrite, true},
+		{[]string{"projects:read"}, ScopeProjectsWrite, false},
+		{[]string{"tasks:write"}, ScopeProjectsRead, false},
+		{[]string{"tasks:read", "projects:read"}, ScopeProjectsRead, true},
+		{[]string{"tasks:read", "tasks:write"}, ScopeTasksWrite, true},
+		{[]string{"tasks:read"}, ScopeTasksWrite, false},
+		{nil, ScopeTasksRead, false},
+		{[]string{}, ScopeTasksRead, false},
+		{[]string{"Tasks:read"}, ScopeTasksRead, false},
+	}
+
+	for _, tt := range tests {
+		if got := HasScope(tt.granted, tt.required); got != tt.want {
+			t.Errorf("HasScope(%v, %q) = %v, want %v", tt.granted, tt.required, got, tt.want)
+		}
+	}
+}
+
+func TestIsValidScope(t *testing.T) {
+	for _, scope := range []Scope{ScopeProjectsRead, ScopeProjectsWrite, ScopeTasksRead, ScopeTasksWrite} {
+		if !IsValidScope(string(scope)) {
+			t.Errorf("IsValidScope(%q) = false", scope)
+		}
+	}
+	for _, scope := range []string{"", "admin", "users:read", "projects:*"} {
+		if IsValidScope(scope) {
+			t.Errorf("IsValidScope(%q) = true", scope)
+		}
+	}
+}
\ No newline at end of file
@@ -0,0 +1,386 @@
+// Package schedule computes project schedules with the critical path method.
+// It works on plain task and dependency values, so it has no database
+// dependencies.
+//
+// Durations come from estimated hours and are measured in days of
+// HoursPerDay hours. Every calendar day is a working day. Offsets are days
+// from the schedule start, so a task with EarliestStart 0 and
+// EarliestFinish 1.5 runs from the start of the first day to the middle of
+// the second.
+package schedule
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+// Dependency types, matching task_dependencies.dependency_type
+const (
+	FinishToStart = "FS"
+	StartToStart  = "SS"
+)
+
+// DefaultHoursPerDay is the working day length used when Options leaves it unset
+const DefaultHoursPerDay = 8
+
+// epsilon absorbs floating point error when comparing offsets
+const epsilon = 1e-9
+
+// ErrCycle is returned when the dependencies form a cycle
+var ErrCycle = errors.New("dependencies form a cycle")
+
+// Task is a task to schedule. Tasks with subtasks in the same input are
+// summary tasks: they span their subtasks and their own hours are ignored.
+// Done tasks have no remaining work.
+type Task struct {
+	ID       int
+	ParentID *int
+	Title    string
+	Hours    float64
+	Done     bool
+	DueDate  *time.Time
+}
+
+// Dependency makes TaskID wait for DependsOnID. A dependency on a summary
+// task applies to each of its subtasks.
+type Dependency struct {
+	TaskID      int
+	DependsOnID int
+	Type        string
+}
+
+// Options configures a schedule computation
+type Options struct {
+	Start       time.Time
+	HoursPerDay float64
+}
+
+// TaskSchedule holds the computed schedule of one task. Day offsets are
+// counted from the schedule start; dates are the calendar days on which
+// the task starts and finishes. DueDateInfeasible is set when the task
+// cannot finish by its due date even if it starts as early as possible.
+type TaskSchedule struct {
+	TaskID            int        `json:"task_id"`
+	Title             string     `json:"title"`
+	Summary           bool       `json:"summary"`
+	Done              bool       `json:"done"`
+	DurationDays      float64    `json:"duration_days"`
+	EarliestStartDay  float64    `json:"earliest_start_day"`
+	EarliestFinishDay float64    `json:"earliest_finish_day"`
+	LatestStartDay    float64    `json:"latest_start_day"`
+	LatestFinishDay   float64    `json:"latest_finish_day"`
+	SlackDays         float64    `json:"slack_days"`
+	EarliestStart     time.Time  `json:"earliest_start"`
+	EarliestFinish    time.Time  `json:"earliest_finish"`
+	LatestStart       time.Time  `json:"latest_start"`
+	LatestFinish      time.Time  `json:"latest_finish"`
+	Critical          bool       `json:"critical"`
+	DueDate           *time.Time `json:"due_date"`
+	DueDateInfeasible bool       `json:"due_date_infeasible"`
+}
+
+// Schedule is the computed schedule of a set of tasks. Tasks are in input
+// order. CriticalPath lists the unfinished critical tasks in the order they
+// run; InfeasibleDueDates lists the tasks that cannot meet their due date.
+type Schedule struct {
+	Start              time.Time       `json:"start"`
+	Finish             time.Time       `json:"finish"`
+	DurationDays       float64         `json:"duration_days"`
+	HoursPerDay        float64         `json:"hours_per_day"`
+	Tasks              []*TaskSchedule `json:"tasks"`
+	CriticalPath       []int           `json:"critical_path"`
+	InfeasibleDueDates []int           `json:"infeasible_due_dates"`
+}
+
+// edge is a dependency between two leaf tasks
+type edge struct {
+	from, to int
+	kind     string
+}
+
+// Compute schedules tasks with the critical path method. Dependencies that
+// refer to tasks outside the input are ignored.
+func Compute(tasks []Task, dependencies []Dependency, opts Options) (*Schedule, error) {
+	hoursPerDay := opts.HoursPerDay
+	if hoursPerDay <= 0 {
+		hoursPerDay = DefaultHoursPerDay
+	}
+	start := time.Date(opts.Start.Year(), opts.Start.Month(), opts.Start.Day(), 0, 0, 0, 0, time.UTC)
+
+	byID := make(map[int]*Task, len(tasks))
+	for i := range tasks {
+		byID[tasks[i].ID] = &tasks[i]
+	}
+	children := make(map[int][]int)
+	for _, task := range tasks {
+		if task.ParentID != nil && byID[*task.ParentID] != nil && *task.ParentID != task.ID {
+			children[*task.ParentID] = append(children[*task.ParentID], task.ID)
+		}
+	}
+
+	// Leaf tasks form the network; summary tasks are rolled up afterwards
+	var leaves []int
+	for _, task := range tasks {
+		if len(children[task.ID]) == 0 {
+			leaves = append(leaves, task.ID)
+		}
+	}
+	sort.Ints(leaves)
+
+	duration := make(map[int]float64, len(leaves))
+	for _, id := range leaves {
+		if task := byID[id]; !task.Done && task.Hours > 0 {
+			duration[id] = task.Hours / hoursPerDay
+		}
+	}
+
+	edges := expandDependencies(dependencies, byID, children)
+	predecessors := make(map[int][]edge)
+	successors := make(map[int][]edge)
+	for _, e := range edges {
+		predecessors[e.to] = append(predecessors[e.to], e)
+		successors[e.from] = append(successors[e.from], e)
+	}
+
+	order, err := topologicalOrder(leaves, predecessors, successors)
+	if err != nil {
+		return nil, err
+	}
+
+	// Forward pass
+	es := make(map[int]float64, len(leaves))
+	ef := make(map[int]float64, len(leaves))
+	finish := 0.0
+	for _, id := range order {
+		for _, e := range predecessors[id] {
+			bound := ef[e.from]
+			if e.kind == StartToStart {
+				bound = es[e.from]
+			}
+			es[id] = math.Max(es[id], bound)
+		}
+		ef[id] = es[id] + duration[id]
+		finish = math.Max(finish, ef[id])
+	}
+
+	// Backward pass
+	ls := make(map[int]float64, len(leaves))
+	lf := make(map[int]float64, len(leaves))
+	for i := len(order) - 1; i >= 0; i-- {
+		id := order[i]
+		lf[id] = finish
+		for _, e := range successors[id] {
+			bound := ls[e.to]
+			if e.kind == StartToStart {
+				bound = ls[e.to] + duration[id]
+			}
+			lf[id] = math.Min(lf[id], bound)
+		}
+		ls[id] = lf[id] - duration[id]
+	}
+
+	results := make(map[int]*TaskSchedule, len(tasks))
+	for _, id := range leaves {
+		results[id] = &TaskSchedule{
+			DurationDays:      duration[id],
+			EarliestStartDay:  es[id],
+			EarliestFinishDay: ef[id],
+			LatestStartDay:    ls[id],
+			LatestFinishDay:   lf[id],
+			SlackDays:         ls[id] - es[id],
+			Critical:          ls[id]-es[id] < epsilon,
+		}
+	}
+	for _, task := range tasks {
+		rollUp(task.ID, children, results)
+	}
+
+	schedule := &Schedule{
+		Start:              start,
+		Finish:             finishDate(start, 0, finish),
+		DurationDays:       round(finish),
+		HoursPerDay:        hoursPerDay,
+		Tasks:              make([]*TaskSchedule, len(tasks)),
+		CriticalPath:       []int{},
+		InfeasibleDueDates: []int{},
+	}
+	for i, task := range tasks {
+		result := results[task.ID]
+		result.TaskID = task.ID
+		result.Title = task.Title
+		result.Summary = len(children[task.ID]) > 0
+		result.Done = task.Done
+		result.DueDate = task.DueDate
+		result.EarliestStart = startDate(start, result.EarliestStartDay)
+		result.EarliestFinish = finishDate(start, result.EarliestStartDay, result.EarliestFinishDay)
+		result.LatestStart = startDate(start, result.LatestStartDay)
+		result.LatestFinish = finishDate(start, result.LatestStartDay, result.LatestFinishDay)
+		if task.DueDate != nil && !task.Done {
+			due := time.Date(task.DueDate.Year(), task.DueDate.Month(), task.DueDate.Day(), 0, 0, 0, 0, time.UTC)
+			if result.EarliestFinish.After(due) {
+				result.DueDateInfeasible = true
+				schedule.InfeasibleDueDates = append(schedule.InfeasibleDueDates, task.ID)
+			}
+		}
+
+		result.DurationDays = round(result.DurationDays)
+		result.EarliestStartDay = round(result.EarliestStartDay)
+		result.EarliestFinishDay = round(result.EarliestFinishDay)
+		result.LatestStartDay = round(result.LatestStartDay)
+		result.LatestFinishDay = round(result.LatestFinishDay)
+		result.SlackDays = round(result.SlackDays)
+		schedule.Tasks[i] = result
+	}
+
+	for _, id := range order {
+		if results[id].Critical && !byID[id].Done {
+			schedule.CriticalPath = append(schedule.CriticalPath, id)
+		}
+	}
+	sort.SliceStable(schedule.CriticalPath, func(i, j int) bool {
+		a, b := schedule.CriticalPath[i], schedule.CriticalPath[j]
+		if es[a] != es[b] {
+			return es[a] < es[b]
+		}
+		return ef[a] < ef[b]
+	})
+
+	return schedule, nil
+}
+
+// leafTasks returns the leaf tasks under a task, or the task itself when it
+// has no subtasks
+func leafTasks(id int, children map[int][]int, seen map[int]bool) []int {
+	if seen[id] {
+		return nil
+	}
+	seen[id] = true
+	if len(children[id]) == 0 {
+		return []int{id}
+	}
+	var leaves []int
+	for _, child := range children[id] {
+		leaves = append(leaves, leafTasks(child, children, seen)...)
+	}
+	return leaves
+}
+
+// expandDependencies turns dependencies into edges between leaf tasks.
+// A finish-to-start edge implies a start-to-start one, so it wins when a
+// pair is linked both ways.
+func expandDependencies(dependencies []Dependency, byID map[int]*Task, children map[int][]int) []edge {
+	kinds := make(map[[2]int]string)
+	var pairs [][2]int
+	for _, dependency := range dependencies {
+		if byID[dependency.TaskID] == nil || byID[dependency.DependsOnID] == nil {
+			continue
+		}
+		kind := dependency.Type
+		if kind != StartToStart {
+			kind = FinishToStart
+		}
+		for _, to := range leafTasks(dependency.TaskID, children, map[int]bool{}) {
+			for _, from := range leafTasks(dependency.DependsOnID, children, map[int]bool{}) {
+				if from == to {
+					continue
+				}
+				pair := [2]int{from, to}
+				existing, ok := kinds[pair]
+				if !ok {
+					pairs = append(pairs, pair)
+				}
+				if !ok || existing == StartToStart {
+					kinds[pair] = kind
+				}
+			}
+		}
+	}
+
+	edges := make([]edge, len(pairs))
+	for i, pair := range pairs {
+		edges[i] = edge{from: pair[0], to: pair[1], kind: kinds[pair]}
+	}
+	return edges
+}
+
+// topologicalOrder orders the leaf tasks so every task follows the tasks it
+// waits for, preferring lower IDs. It returns ErrCycle when no order exists.
+func topologicalOrder(leaves []int, predecessors, successors map[int][]edge) ([]int, error) {
+	waiting := make(map[int]int, len(leaves))
+	var ready []int
+	for _, id := range leaves {
+		waiting[id] = len(predecessors[id])
+		if waiting[id] == 0 {
+			ready = append(ready, id)
+		}
+	}
+
+	order := make([]int, 0, len(leaves))
+	for len(ready) > 0 {
+		sort.Ints(ready)
+		id := ready[0]
+		ready = ready[1:]
+		order = append(order, id)
+		for _, e := range successors[id] {
+			waiting[e.to]--
+			if waiting[e.to] == 0 {
+				ready = append(ready, e.to)
+			}
+		}
+	}
+
+	if len(order) != len(leaves) {
+		return nil, ErrCycle
+	}
+	return order, nil
+}
+
+// rollUp computes a summary task from its subtasks: it spans them, has the
+// least slack among them and is critical when any of them is
+func rollUp(id int, children map[int][]int, results map[int]*TaskSchedule) *TaskSchedule {
+	if result, ok := results[id]; ok {
+		return result
+	}
+
+	var result *TaskSchedule
+	for _, child := range children[id] {
+		sub := rollUp(child, children, results)
+		if result == nil {
+			copied := *sub
+			result = &copied
+			continue
+		}
+		result.EarliestStartDay = math.Min(result.EarliestStartDay, sub.EarliestStartDay)
+		result.EarliestFinishDay = math.Max(result.EarliestFinishDay, sub.EarliestFinishDay)
+		result.LatestStartDay = math.Min(result.LatestStartDay, sub.LatestStartDay)
+		result.LatestFinishDay = math.Max(result.LatestFinishDay, sub.LatestFinishDay)
+		result.SlackDays = math.Min(result.SlackDays, sub.SlackDays)
+		result.Critical = result.Critical || sub.Critical
+	}
+	result.DurationDays = result.EarliestFinishDay - result.EarliestStartDay
+
+	results[id] = result
+	return result
+}
+
+// startDate returns the calendar day containing a start offset
+func startDate(start time.Time, offset float64) time.Time {
+	return start.AddDate(0, 0, int(math.Floor(offset+epsilon)))
+}
+
+// finishDate returns the last calendar day worked by a task running between
+// two offsets. A task without duration finishes on its start day.
+func finishDate(start time.Time, from, to float64) time.Time {
+	day := int(math.Ceil(to-epsilon)) - 1
+	if first := int(math.Floor(from + epsilon)); day < first {
+		day = first
+	}
+	return start.AddDate(0, 0, day)
+}
+
+// round rounds a day count to two decimals for output
+func round(days float64) float64 {
+	return math.Round(days*100) / 100
+}
\ No newline at end of file
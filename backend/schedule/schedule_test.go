@@ -0,0 +1,410 @@
+package schedule
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var testStart = time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func day(offset int) *time.Time {
+	t := testStart.AddDate(0, 0, offset)
+	return &t
+}
+
+// expected holds the day offsets and slack checked for one task
+type expected struct {
+	es, ef, ls, lf, slack float64
+	critical              bool
+}
+
+func TestCompute(t *testing.T) {
+	tests := []struct {
+		name         string
+		tasks        []Task
+		dependencies []Dependency
+		duration     float64
+		want         map[int]expected
+		criticalPath []int
+	}{
+		{
+			name:  "independent tasks",
+			tasks: []Task{{ID: 1, Hours: 8}, {ID: 2, Hours: 16}},
+			want: map[int]expected{
+				1: {es: 0, ef: 1, ls: 1, lf: 2, slack: 1},
+				2: {es: 0, ef: 2, ls: 0, lf: 2, critical: true},
+			},
+			duration:     2,
+			criticalPath: []int{2},
+		},
+		{
+			name:         "finish to start chain",
+			tasks:        []Task{{ID: 1, Hours: 8}, {ID: 2, Hours: 16}, {ID: 3, Hours: 8}},
+			dependencies: []Dependency{{TaskID: 2, DependsOnID: 1, Type: FinishToStart}},
+			want: map[int]expected{
+				1: {es: 0, ef: 1, ls: 0, lf: 1, critical: true},
+				2: {es: 1, ef: 3, ls: 1, lf: 3, critical: true},
+				3: {es: 0, ef: 1, ls: 2, lf: 3, slack: 2},
+			},
+			duration:     3,
+			criticalPath: []int{1, 2},
+		},
+		{
+			name:         "start to start",
+			tasks:        []Task{{ID: 1, Hours: 16}, {ID: 2, Hours: 8}},
+			dependencies: []Dependency{{TaskID: 2, DependsOnID: 1, Type: StartToStart}},
+			want: map[int]expected{
+				1: {es: 0, ef: 2, ls: 0, lf: 2, critical: true},
+				2: {es: 0, ef: 1, ls: 1, lf: 2, slack: 1},
+			},
+			duration:     2,
+			criticalPath: []int{1},
+		},
+		{
+			name:  "start to start chain",
+			tasks: []Task{{ID: 1, Hours: 8}, {ID: 2, Hours: 24}, {ID: 3, Hours: 8}},
+			dependencies: []Dependency{
+				{TaskID: 2, DependsOnID: 3, Type: FinishToStart},
+				{TaskID: 3, DependsOnID: 1, Type: StartToStart},
+			},
+			want: map[int]expected{
+				1: {es: 0, ef: 1, ls: 0, lf: 1, critical: true},
+				3: {es: 0, ef: 1, ls: 0, lf: 1, critical: true},
+				2: {es: 1, ef: 4, ls: 1, lf: 4, critical: true},
+			},
+			duration:     4,
+			criticalPath: []int{1, 3, 2},
+		},
+		{
+			name:  "finish to start wins over start to start for the same pair",
+			tasks: []Task{{ID: 1, Hours: 8}, {ID: 2, Hours: 8}},
+			dependencies: []Dependency{
+				{TaskID: 2, DependsOnID: 1, Type: StartToStart},
+				{TaskID: 2, DependsOnID: 1, Type: FinishToStart},
+			},
+			want: map[int]expected{
+				1: {es: 0, ef: 1, ls: 0, lf: 1, critical: true},
+				2: {es: 1, ef: 2, ls: 1, lf: 2, critical: true},
+			},
+			duration:     2,
+			criticalPath: []int{1, 2},
+		},
+		{
+			name: "done and unestimated tasks take no time",
+			tasks: []Task{
+				{ID: 1, Hours: 40, Done: true},
+				{ID: 2},
+				{ID: 3, Hours: 12},
+			},
+			dependencies: []Dependency{
+				{TaskID: 3, DependsOnID: 1, Type: FinishToStart},
+				{TaskID: 3, DependsOnID: 2, Type: FinishToStart},
+			},
+			want: map[int]expected{
+				1: {es: 0, ef: 0, ls: 0, lf: 0, critical: true},
+				2: {es: 0, ef: 0, ls: 0, lf: 0, critical: true},
+				3: {es: 0, ef: 1.5, ls: 0, lf: 1.5, critical: true},
+			},
+			duration:     1.5,
+			criticalPath: []int{2, 3},
+		},
+		{
+			name: "summary tasks span their subtasks and pass dependencies on",
+			tasks: []Task{
+				{ID: 1, Hours: 16},
+				{ID: 2, Hours: 100},
+				{ID: 3, ParentID: intPtr(2), Hours: 8},
+				{ID: 4, ParentID: intPtr(2), Hours: 4},
+				{ID: 5, Hours: 8},
+				{ID: 6, Hours: 8},
+			},
+			dependencies: []Dependency{
+				{TaskID: 2, DependsOnID: 1, Type: FinishToStart},
+				{TaskID: 5, DependsOnID: 2, Type: FinishToStart},
+			},
+			want: map[int]expected{
+				1: {es: 0, ef: 2, ls: 0, lf: 2, critical: true},
+				2: {es: 2, ef: 3, ls: 2, lf: 3, critical: true},
+				3: {es: 2, ef: 3, ls: 2, lf: 3, critical: true},
+				4: {es: 2, ef: 2.5, ls: 2.5, lf: 3, slack: 0.5},
+				5: {es: 3, ef: 4, ls: 3, lf: 4, critical: true},
+				6: {es: 0, ef: 1, ls: 3, lf: 4, slack: 3},
+			},
+			duration:     4,
+			criticalPath: []int{1, 3, 5},
+		},
+		{
+			name: "nested summary tasks roll up",
+			tasks: []Task{
+				{ID: 1},
+				{ID: 2, ParentID: intPtr(1)},
+				{ID: 3, ParentID: intPtr(2), Hours: 8},
+				{ID: 4, ParentID: intPtr(1), Hours: 24},
+				{ID: 5, Hours: 8},
+			},
+			dependencies: []Dependency{{TaskID: 5, DependsOnID: 2, Type: FinishToStart}},
+			want: map[int]expected{
+				1: {es: 0, ef: 3, ls: 0, lf: 3, critical: true},
+				2: {es: 0, ef: 1, ls: 1, lf: 2, slack: 1},
+				3: {es: 0, ef: 1, ls: 1, lf: 2, slack: 1},
+				4: {es: 0, ef: 3, ls: 0, lf: 3, critical: true},
+				5: {es: 1, ef: 2, ls: 2, lf: 3, slack: 1},
+			},
+			duration:     3,
+			criticalPath: []int{4},
+		},
+		{
+			name:         "dependencies on unknown tasks are ignored",
+			tasks:        []Task{{ID: 1, Hours: 8}},
+			dependencies: []Dependency{{TaskID: 1, DependsOnID: 99, Type: FinishToStart}},
+			want: map[int]expected{
+				1: {es: 0, ef: 1, ls: 0, lf: 1, critical: true},
+			},
+			duration:     1,
+			criticalPath: []int{1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := Compute(tt.tasks, tt.dependencies, Options{Start: testStart})
+			if err != nil {
+				t.Fatalf("Compute returned error: %v", err)
+			}
+			if schedule.DurationDays != tt.duration {
+				t.Errorf("DurationDays = %v, want %v", schedule.DurationDays, tt.duration)
+			}
+			if !reflect.DeepEqual(schedule.CriticalPath, tt.criticalPath) {
+				t.Errorf("CriticalPath = %v, want %v", schedule.CriticalPath, tt.criticalPath)
+			}
+			if len(schedule.Tasks) != len(tt.tasks) {
+				t.Fatalf("got %d tasks, want %d", len(schedule.Tasks), len(tt.tasks))
+			}
+			for i, result := range schedule.Tasks {
+				if result.TaskID != tt.tasks[i].ID {
+					t.Fatalf("Tasks[%d].TaskID = %d, want %d", i, result.TaskID, tt.tasks[i].ID)
+				}
+				want := tt.want[result.TaskID]
+				got := expected{
+					es:       result.EarliestStartDay,
+					ef:       result.EarliestFinishDay,
+					ls:       result.LatestStartDay,
+					lf:       result.LatestFinishDay,
+					slack:    result.SlackDays,
+					critical: result.Critical,
+				}
+				if got != want {
+					t.Errorf("task %d = %+v, want %+v", result.TaskID, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestComputeSummaryFlag(t *testing.T) {
+	tasks := []Task{{ID: 1}, {ID: 2, ParentID: intPtr(1), Hours: 8}, {ID: 3, ParentID: intPtr(3), Hours: 8}}
+	schedule, err := Compute(tasks, nil, Options{Start: testStart})
+	if err != nil {
+		t.Fatalf("Compute returned error: %v", err)
+	}
+	want := []bool{true, false, false}
+	for i, result := range schedule.Tasks {
+		if result.Summary != want[i] {
+			t.Errorf("task %d Summary = %v, want %v", result.TaskID, result.Summary, want[i])
+		}
+	}
+}
+
+func TestComputeCycle(t *testing.T) {
+	tests := []struct {
+		name         string
+		tasks        []Task
+		dependencies []Dependency
+	}{
+		{
+			name:  "direct cycle",
+			tasks: []Task{{ID: 1, Hours: 8}, {ID: 2, Hours: 8}},
+			dependencies: []Dependency{
+				{TaskID: 1, DependsOnID: 2, Type: FinishToStart},
+				{TaskID: 2, DependsOnID: 1, Type: FinishToStart},
+			},
+		},
+		{
+			name:  "start to start cycle",
+			tasks: []Task{{ID: 1, Hours: 8}, {ID: 2, Hours: 8}, {ID: 3, Hours: 8}},
+			dependencies: []Dependency{
+				{TaskID: 2, DependsOnID: 1, Type: StartToStart},
+				{TaskID: 3, DependsOnID: 2, Type: StartToStart},
+				{TaskID: 1, DependsOnID: 3, Type: FinishToStart},
+			},
+		},
+		{
+			name:  "cycle through a summary task",
+			tasks: []Task{{ID: 1}, {ID: 2, ParentID: intPtr(1), Hours: 8}, {ID: 3, Hours: 8}},
+			dependencies: []Dependency{
+				{TaskID: 3, DependsOnID: 1, Type: FinishToStart},
+				{TaskID: 2, DependsOnID: 3, Type: FinishToStart},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compute(tt.tasks, tt.dependencies, Options{Start: testStart})
+			if !errors.Is(err, ErrCycle) {
+				t.Errorf("Compute error = %v, want ErrCycle", err)
+			}
+		})
+	}
+}
+
+func TestComputeDueDates(t *testing.T) {
+	tests := []struct {
+		name       string
+		task       Task
+		infeasible bool
+	}{
+		{name: "finishes before the due date", task: Task{ID: 2, Hours: 8, DueDate: day(3)}},
+		{name: "finishes on the due date", task: Task{ID: 2, Hours: 8, DueDate: day(2)}},
+		{name: "finishes after the due date", task: Task{ID: 2, Hours: 8, DueDate: day(1)}, infeasible: true},
+		{name: "fraction of a day past the due date", task: Task{ID: 2, Hours: 12, DueDate: day(2)}, infeasible: true},
+		{name: "due date with a time of day", task: Task{ID: 2, Hours: 8, DueDate: func() *time.Time {
+			t := day(2).Add(9 * time.Hour)
+			return &t
+		}()}},
+		{name: "done task is never infeasible", task: Task{ID: 2, Hours: 8, Done: true, DueDate: day(-10)}},
+		{name: "no due date", task: Task{ID: 2, Hours: 80}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Task 1 takes the first two days and task 2 waits for it
+			tasks := []Task{{ID: 1, Hours: 16}, tt.task}
+			dependencies := []Dependency{{TaskID: 2, DependsOnID: 1, Type: FinishToStart}}
+			schedule, err := Compute(tasks, dependencies, Options{Start: testStart})
+			if err != nil {
+				t.Fatalf("Compute returned error: %v", err)
+			}
+			if got := schedule.Tasks[1].DueDateInfeasible; got != tt.infeasible {
+				t.Errorf("DueDateInfeasible = %v, want %v", got, tt.infeasible)
+			}
+			want := []int{}
+			if tt.infeasible {
+				want = []int{2}
+			}
+			if !reflect.DeepEqual(schedule.InfeasibleDueDates, want) {
+				t.Errorf("InfeasibleDueDates = %v, want %v", schedule.InfeasibleDueDates, want)
+			}
+		})
+	}
+}
+
+func TestComputeOptions(t *testing.T) {
+	tasks := []Task{{ID: 1, Hours: 12}}
+	start := time.Date(2026, 1, 5, 15, 30, 0, 0, time.FixedZone("UTC+8", 8*3600))
+
+	schedule, err := Compute(tasks, nil, Options{Start: start, HoursPerDay: 6})
+	if err != nil {
+		t.Fatalf("Compute returned error: %v", err)
+	}
+	if !schedule.Start.Equal(testStart) {
+		t.Errorf("Start = %v, want %v", schedule.Start, testStart)
+	}
+	if schedule.HoursPerDay != 6 || schedule.DurationDays != 2 {
+		t.Errorf("HoursPerDay, DurationDays = %v, %v, want 6, 2", schedule.HoursPerDay, schedule.DurationDays)
+	}
+	if !schedule.Finish.Equal(*day(1)) {
+		t.Errorf("Finish = %v, want %v", schedule.Finish, *day(1))
+	}
+
+	schedule, err = Compute(tasks, nil, Options{Start: testStart})
+	if err != nil {
+		t.Fatalf("Compute returned error: %v", err)
+	}
+	if schedule.HoursPerDay != DefaultHoursPerDay || schedule.DurationDays != 1.5 {
+		t.Errorf("HoursPerDay, DurationDays = %v, %v, want %v, 1.5", schedule.HoursPerDay, schedule.DurationDays, DefaultHoursPerDay)
+	}
+}
+
+func TestComputeDates(t *testing.T) {
+	tasks := []Task{{ID: 1, Hours: 12}, {ID: 2, Hours: 4}, {ID: 3}}
+	dependencies := []Dependency{
+		{TaskID: 2, DependsOnID: 1, Type: FinishToStart},
+		{TaskID: 3, DependsOnID: 2, Type: FinishToStart},
+	}
+	schedule, err := Compute(tasks, dependencies, Options{Start: testStart})
+	if err != nil {
+		t.Fatalf("Compute returned error: %v", err)
+	}
+
+	// Task 1 runs for a day and a half, task 2 for the rest of the second day
+	// and task 3 takes no time at the start of the third day
+	want := []struct{ start, finish *time.Time }{
+		{day(0), day(1)},
+		{day(1), day(1)},
+		{day(2), day(2)},
+	}
+	for i, result := range schedule.Tasks {
+		if !result.EarliestStart.Equal(*want[i].start) || !result.EarliestFinish.Equal(*want[i].finish) {
+			t.Errorf("task %d runs %v to %v, want %v to %v", result.TaskID,
+				result.EarliestStart, result.EarliestFinish, *want[i].start, *want[i].finish)
+		}
+	}
+	if !schedule.Finish.Equal(*day(1)) {
+		t.Errorf("Finish = %v, want %v", schedule.Finish, *day(1))
+	}
+}
+
+func TestFinishDate(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to float64
+		want     int
+	}{
+		{name: "empty schedule", from: 0, to: 0, want: 0},
+		{name: "whole first day", from: 0, to: 1, want: 0},
+		{name: "into the second day", from: 0, to: 1.5, want: 1},
+		{name: "whole second day", from: 1, to: 2, want: 1},
+		{name: "part of one day", from: 0.25, to: 0.75, want: 0},
+		{name: "from mid day to the day boundary", from: 0.5, to: 1, want: 0},
+		{name: "zero duration on a day boundary", from: 2, to: 2, want: 2},
+		{name: "zero duration mid day", from: 1.5, to: 1.5, want: 1},
+		{name: "rounding error past a boundary", from: 0, to: 2 + 1e-12, want: 1},
+		{name: "rounding error before a boundary", from: 1 - 1e-12, to: 1 - 1e-12, want: 1},
+		{name: "summed thirds", from: 0, to: 1.0/3 + 1.0/3 + 1.0/3, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := finishDate(testStart, tt.from, tt.to)
+			if want := *day(tt.want); !got.Equal(want) {
+				t.Errorf("finishDate(%v, %v) = %v, want %v", tt.from, tt.to, got, want)
+			}
+		})
+	}
+}
+
+func TestStartDate(t *testing.T) {
+	tests := []struct {
+		offset float64
+		want   int
+	}{
+		{offset: 0, want: 0},
+		{offset: 0.5, want: 0},
+		{offset: 1, want: 1},
+		{offset: 1 - 1e-12, want: 1},
+		{offset: 2.99, want: 2},
+	}
+
+	for _, tt := range tests {
+		if got, want := startDate(testStart, tt.offset), *day(tt.want); !got.Equal(want) {
+			t.Errorf("startDate(%v) = %v, want %v", tt.offset, got, want)
+		}
+	}
+}
\ No newline at end of file
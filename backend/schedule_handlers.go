@@ -0,0 +1,147 @@
+package main
+
+import (
+	"ai-project-backend/models"
+	"ai-project-backend/policy"
+	"ai-project-backend/schedule"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// scheduleInput converts the tasks and dependencies of a project into
+// schedule input. Tasks in a done status have no remaining work.
+func scheduleInput(workflow *models.Workflow, tasks []*models.Task, dependencies []*models.TaskDependency) ([]schedule.Task, []schedule.Dependency) {
+	scheduleTasks := make([]schedule.Task, len(tasks))
+	for i, task := range tasks {
+		scheduleTasks[i] = schedule.Task{
+			ID:       task.ID,
+			ParentID: task.ParentID,
+			Title:    task.Title,
+			Done:     workflow.Category(task.Status) == models.StatusCategoryDone,
+			DueDate:  task.DueDate,
+		}
+		if task.EstimatedHours != nil {
+			scheduleTasks[i].Hours = *task.EstimatedHours
+		}
+	}
+
+	scheduleDependencies := make([]schedule.Dependency, len(dependencies))
+	for i, dependency := range dependencies {
+		scheduleDependencies[i] = schedule.Dependency{
+			TaskID:      dependency.TaskID,
+			DependsOnID: dependency.DependsOnID,
+			Type:        dependency.Type,
+		}
+	}
+
+	return scheduleTasks, scheduleDependencies
+}
+
+// projectPlan holds the tasks and dependencies of a project along with
+// their schedule
+type projectPlan struct {
+	workflow     *models.Workflow
+	tasks        []*models.Task
+	dependencies []*models.TaskDependency
+	schedule     *schedule.Schedule
+}
+
+// loadProjectPlan loads a project's tasks and dependencies and schedules
+// them, writing the error response and returning false on failure
+func (app *Application) loadProjectPlan(c *gin.Context, projectID int, opts schedule.Options, action string) (*projectPlan, bool) {
+	ctx := c.Request.Context()
+	workflow, ok := app.projectWorkflow(c, projectID, action)
+	if !ok {
+		return nil, false
+	}
+
+	tasks, err := app.db.Tasks().GetAllByProjectID(ctx, projectID)
+	if err != nil {
+		app.logger.Printf("Error getting tasks: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to "+action, nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return nil, false
+	}
+	dependencies, err := app.db.Dependencies().ListByProject(ctx, projectID)
+	if err != nil {
+		app.logger.Printf("Error getting task dependencies: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to "+action, nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return nil, false
+	}
+
+	scheduleTasks, scheduleDependencies := scheduleInput(workflow, tasks, dependencies)
+	result, err := schedule.Compute(scheduleTasks, scheduleDependencies, opts)
+	if err != nil {
+		if errors.Is(err, schedule.ErrCycle) {
+			response := models.NewErrorResponse(models.ErrCodeConflict, "Task dependencies form a cycle", nil)
+			c.JSON(http.StatusConflict, response)
+			return nil, false
+		}
+		app.logger.Printf("Error computing schedule: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to "+action, nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return nil, false
+	}
+
+	return &projectPlan{workflow: workflow, tasks: tasks, dependencies: dependencies, schedule: result}, true
+}
+
+// bindScheduleQuery parses the schedule query parameters into options,
+// writing the error response and returning false on failure
+func bindScheduleQuery(c *gin.Context) (schedule.Options, bool) {
+	var query models.ScheduleQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid schedule parameters", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return schedule.Options{}, false
+	}
+
+	opts := schedule.Options{Start: time.Now(), HoursPerDay: query.HoursPerDay}
+	if query.Start != "" {
+		start, err := time.Parse("2006-01-02", query.Start)
+		if err != nil {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, "Start must be a date (YYYY-MM-DD)", nil)
+			c.JSON(http.StatusBadRequest, response)
+			return opts, false
+		}
+		opts.Start = start
+	}
+	if query.HoursPerDay < 0 || query.HoursPerDay > 24 {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Hours per day must be between 0 and 24", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return opts, false
+	}
+
+	return opts, true
+}
+
+func (app *Application) getProjectScheduleHandler(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid project ID", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	opts, ok := bindScheduleQuery(c)
+	if !ok {
+		return
+	}
+
+	if _, ok := app.authorizeProject(c, projectID, policy.ProjectRoleViewer); !ok {
+		return
+	}
+
+	plan, ok := app.loadProjectPlan(c, projectID, opts, "compute schedule")
+	if !ok {
+		return
+	}
+
+	response := models.NewSuccessResponse(plan.schedule, "Schedule retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
\ No newline at end of file
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"ai-project-backend/models"
+	"html"
+	"net/http"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Search limits
+const (
+	maxSearchQueryLength = 200
+	maxSearchTerms       = 10
+	searchSnippetLength  = 160
+)
+
+// validSearchTypes lists the values accepted by the type filter
+var validSearchTypes = map[string]bool{
+	models.SearchTypeProject: true,
+	models.SearchTypeTask:    true,
+}
+
+// searchTerms splits a query into distinct lowercase terms
+func searchTerms(query string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+	for _, term := range strings.Fields(strings.ToLower(query)) {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+		terms = append(terms, term)
+	}
+	return terms
+}
+
+// lowerRunes lowercases text rune by rune, so indexes match the original runes
+func lowerRunes(runes []rune) []rune {
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+	return lower
+}
+
+// matchRanges returns the merged [start, end) rune ranges where any term occurs
+func matchRanges(runes []rune, terms []string) [][2]int {
+	lower := lowerRunes(runes)
+	marked := make([]bool, len(runes))
+	for _, term := range terms {
+		needle := []rune(term)
+		if len(needle) == 0 {
+			continue
+		}
+		for i := 0; i+len(needle) <= len(lower); i++ {
+			if string(lower[i:i+len(needle)]) == term {
+				for j := i; j < i+len(needle); j++ {
+					marked[j] = true
+				}
+			}
+		}
+	}
+
+	var ranges [][2]int
+	for i := 0; i < len(marked); i++ {
+		if !marked[i] {
+			continue
+		}
+		start := i
+		for i < len(marked) && marked[i] {
+			i++
+		}
+		ranges = append(ranges, [2]int{start, i})
+	}
+	return ranges
+}
+
+// highlightText HTML-escapes text and wraps every occurrence of the terms in
+// <mark> tags. With maxLength set, only a window of about that many runes
+// around the first match is kept, and cut ends are marked with an ellipsis.
+func highlightText(text string, terms []string, maxLength int) string {
+	runes := []rune(text)
+	ranges := matchRanges(runes, terms)
+
+	from, to := 0, len(runes)
+	if maxLength > 0 && len(runes) > maxLength {
+		if len(ranges) > 0 {
+			from = ranges[0][0] - maxLength/4
+			if from < 0 {
+				from = 0
+			}
+		}
+		to = from + maxLength
+		if to > len(runes) {
+			to = len(runes)
+			from = to - maxLength
+		}
+	}
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("…")
+	}
+	pos := from
+	for _, r := range ranges {
+		start, end := r[0], r[1]
+		if end <= from || start >= to {
+			continue
+		}
+		if start < from {
+			start = from
+		}
+		if end > to {
+			end = to
+		}
+		b.WriteString(html.EscapeString(string(runes[pos:start])))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(string(runes[start:end])))
+		b.WriteString("</mark>")
+		pos = end
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:to])))
+	if to < len(runes) {
+		b.WriteString("…")
+	}
+
+	return b.String()
+}
+
+func (app *Application) searchHandler(c *gin.Context) {
+	var query models.SearchQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid search parameters", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	query.Q = strings.TrimSpace(query.Q)
+	if query.Q == "" {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Search query is required", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	if utf8.RuneCountInString(query.Q) > maxSearchQueryLength {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Search query is too long", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	terms := searchTerms(query.Q)
+	if len(terms) > maxSearchTerms {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Search query has too many terms", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Default pagination values
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.PageSize == 0 {
+		query.PageSize = 20
+	}
+
+	filter := models.SearchFilter{
+		Query:      query.Q,
+		Terms:      terms,
+		ProjectIDs: query.ProjectID,
+		Limit:      query.PageSize,
+		Offset:     (query.Page - 1) * query.PageSize,
+	}
+
+	filter.Types = splitValues(query.Type)
+	for _, hitType := range filter.Types {
+		if !validSearchTypes[hitType] {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid type: "+hitType, nil)
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+	}
+
+	filter.Statuses = splitValues(query.Status)
+	for _, status := range filter.Statuses {
+		if !validStatusKey(status) {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid status: "+status, nil)
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+	}
+
+	// Admins search every project, everyone else only projects they belong to
+	admin, ok := app.adminAccess(c, "search")
+	if !ok {
+		return
+	}
+	if !admin {
+		filter.MemberID = &currentUser(c).UserID
+	}
+
+	result, err := app.db.Search().Search(c.Request.Context(), filter)
+	if err != nil {
+		app.logger.Printf("Error searching: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to search", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	hits := result.Hits
+	if hits == nil {
+		hits = []*models.SearchHit{}
+	}
+	for _, hit := range hits {
+		hit.Highlight = &models.SearchHighlight{
+			Title:       highlightText(hit.Title, terms, 0),
+			Description: highlightText(hit.Description, terms, searchSnippetLength),
+		}
+	}
+
+	// Create pagination metadata
+	totalPages := int((int64(result.Total) + int64(query.PageSize) - 1) / int64(query.PageSize))
+	paginationMeta := models.Pagination{
+		Page:       query.Page,
+		PageSize:   query.PageSize,
+		Total:      int64(result.Total),
+		TotalPages: totalPages,
+		HasNext:    query.Page < totalPages,
+		HasPrev:    query.Page > 1,
+	}
+
+	searchResponse := models.SearchResponse{
+		Query:      query.Q,
+		Results:    hits,
+		Facets:     result.Facets,
+		Pagination: paginationMeta,
+	}
+
+	response := models.NewSuccessResponse(searchResponse, "Search completed successfully")
+	c.JSON(http.StatusOK, response)
+}
\ No newline at end of file
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"ai-project-backend/database"
+	"ai-project-backend/models"
+	"ai-project-backend/utils"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// issueSession stores a new refresh token in the given session family (a new
+// family when familyID is empty) and signs an access token bound to it.
+// mfa records whether the login passed a second factor.
+func (app *Application) issueSession(c *gin.Context, sessions database.SessionRepository, user *models.User, familyID string, mfa bool) (*models.LoginResponse, *models.AuthSession, error) {
+	refreshToken, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	if familyID == "" {
+		familyID, err = utils.GenerateRandomString(16)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to generate session ID: %w", err)
+		}
+	}
+
+	session := &models.AuthSession{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: utils.HashToken(refreshToken),
+		ExpiresAt: time.Now().Add(app.config.JWT.RefreshExpiration),
+		MFA:       mfa,
+	}
+	if ip := c.ClientIP(); ip != "" {
+		session.IPAddress = &ip
+	}
+	if userAgent := c.Request.UserAgent(); userAgent != "" {
+		session.UserAgent = &userAgent
+	}
+
+	session, err = sessions.Create(c.Request.Context(), session)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	token, err := app.jwtManager.GenerateSessionToken(user.ID, user.Username, user.Role, familyID, mfa)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return &models.LoginResponse{
+		Token:            token,
+		ExpiresAt:        time.Now().Add(app.jwtManager.Expiration()),
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: session.ExpiresAt,
+		User:             *user,
+	}, session, nil
+}
+
+func (app *Application) refreshHandler(c *gin.Context) {
+	var req models.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.RefreshToken == "" {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Refresh token is required", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	current, err := app.db.Sessions().GetByTokenHash(c.Request.Context(), utils.HashToken(req.RefreshToken))
+	if err != nil {
+		if err.Error() == "session not found" {
+			response := models.NewErrorResponse(models.ErrCodeAuthentication, "Invalid refresh token", nil)
+			c.JSON(http.StatusUnauthorized, response)
+			return
+		}
+		app.logger.Printf("Error getting session: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to refresh token", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	// A token that was already rotated is being replayed: assume it was stolen
+	if current.RevokedAt != nil {
+		if current.ReplacedBy != nil {
+			app.revokeReusedFamily(c, current)
+		}
+		response := models.NewErrorResponse(models.ErrCodeAuthentication, "Refresh token has been revoked", nil)
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	if time.Now().After(current.ExpiresAt) {
+		response := models.NewErrorResponse(models.ErrCodeAuthentication, "Refresh token has expired", nil)
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	// Reload the user so role changes take effect on refresh
+	user, err := app.db.Users().GetByID(c.Request.Context(), current.UserID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			response := models.NewErrorResponse(models.ErrCodeAuthentication, "Invalid refresh token", nil)
+			c.JSON(http.StatusUnauthorized, response)
+			return
+		}
+		app.logger.Printf("Error getting user: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to refresh token", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	if !user.IsActive {
+		response := models.NewErrorResponse(models.ErrCodeAuthentication, "Account is deactivated", nil)
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	tx, err := app.db.BeginTx(c.Request.Context())
+	if err != nil {
+		app.logger.Printf("Error starting transaction: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to refresh token", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	defer tx.Rollback()
+
+	loginResponse, next, err := app.issueSession(c, tx.Sessions(), user, current.FamilyID, current.MFA)
+	if err != nil {
+		app.logger.Printf("Error issuing session: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to refresh token", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	if err := tx.Sessions().MarkReplaced(c.Request.Context(), current.ID, next.ID); err != nil {
+		if err.Error() == "session already revoked" {
+			// Lost a race with another rotation of the same token
+			tx.Rollback()
+			app.revokeReusedFamily(c, current)
+			response := models.NewErrorResponse(models.ErrCodeAuthentication, "Refresh token has been revoked", nil)
+			c.JSON(http.StatusUnauthorized, response)
+			return
+		}
+		app.logger.Printf("Error rotating session: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to refresh token", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		app.logger.Printf("Error committing session: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to refresh token", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := models.NewSuccessResponse(loginResponse, "Token refreshed successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// revokeReusedFamily revokes a whole session family after refresh token reuse
+func (app *Application) revokeReusedFamily(c *gin.Context, session *models.AuthSession) {
+	app.logger.Printf("Refresh token reuse detected for user %d, revoking session %s", session.UserID, session.FamilyID)
+
+	if err := app.db.Sessions().RevokeFamily(c.Request.Context(), session.FamilyID); err != nil {
+		app.logger.Printf("Error revoking session: %v", err)
+	}
+
+	entityData := map[string]interface{}{
+		"reason":     "refresh_token_reuse",
+		"session_id": session.FamilyID,
+	}
+	if err := app.db.System().LogAction(c.Request.Context(), &session.UserID, "LOGOUT", "user", session.UserID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+}
+
+func (app *Application) getSessionsHandler(c *gin.Context) {
+	claims := currentUser(c)
+
+	sessions, err := app.db.Sessions().ListActiveByUser(c.Request.Context(), claims.UserID)
+	if err != nil {
+		app.logger.Printf("Error getting sessions: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to retrieve sessions", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	for _, session := range sessions {
+		session.Current = session.ID == claims.SessionID
+	}
+
+	response := models.NewSuccessResponse(sessions, "Sessions retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) deleteSessionHandler(c *gin.Context) {
+	claims := currentUser(c)
+	sessionID := c.Param("id")
+
+	err := app.db.Sessions().RevokeUserFamily(c.Request.Context(), claims.UserID, sessionID)
+	if err != nil {
+		if err.Error() == "session not found" {
+			response := models.NewErrorResponse(models.ErrCodeNotFound, "Session not found", nil)
+			c.JSON(http.StatusNotFound, response)
+			return
+		}
+		app.logger.Printf("Error revoking session: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to revoke session", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	entityData := map[string]interface{}{"session_id": sessionID}
+	if err := app.db.System().LogAction(c.Request.Context(), &claims.UserID, "LOGOUT", "user", claims.UserID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
+	response := models.NewSuccessResponse(nil, "Session revoked successfully")
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,712 @@
+package main
+
+import (
+	"ai-project-backend/database"
+	"ai-project-backend/models"
+	"ai-project-backend/policy"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+// checkTaskSprint resolves the sprint a task is moved to. 0 moves the task
+// to the backlog and yields nil. It returns a client error message when the
+// sprint is missing, belongs to another project or is closed.
+func checkTaskSprint(ctx context.Context, sprints database.SprintRepository, projectID, sprintID int) (*int, string, error) {
+	if sprintID == 0 {
+		return nil, "", nil
+	}
+
+	sprint, err := sprints.GetByID(ctx, sprintID)
+	if err != nil {
+		if err.Error() == "sprint not found" {
+			return nil, "Sprint not found", nil
+		}
+		return nil, "", err
+	}
+	if sprint.ProjectID != projectID {
+		return nil, "Sprint not found", nil
+	}
+	if sprint.State == models.SprintClosed {
+		return nil, "Sprint " + sprint.Name + " is closed", nil
+	}
+
+	return &sprint.ID, "", nil
+}
+
+// applySprintRequest copies the fields given in a request onto a sprint and
+// validates the result, returning an error message or "" when it is valid
+func applySprintRequest(sprint *models.Sprint, req *models.SprintRequest) string {
+	if req.Name != "" {
+		sprint.Name = strings.TrimSpace(req.Name)
+	}
+	if req.Goal != nil {
+		sprint.Goal = *req.Goal
+	}
+	if req.StartDate != "" {
+		startDate, err := time.Parse("2006-01-02", req.StartDate)
+		if err != nil {
+			return "Start date must be a date (YYYY-MM-DD)"
+		}
+		sprint.StartDate = startDate
+	}
+	if req.EndDate != "" {
+		endDate, err := time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return "End date must be a date (YYYY-MM-DD)"
+		}
+		sprint.EndDate = endDate
+	}
+
+	if sprint.Name == "" || utf8.RuneCountInString(sprint.Name) > 255 {
+		return "Name must be 1-255 characters"
+	}
+	if sprint.StartDate.IsZero() || sprint.EndDate.IsZero() {
+		return "Start and end dates are required"
+	}
+	if sprint.EndDate.Before(sprint.StartDate) {
+		return "End date must not be before the start date"
+	}
+
+	return ""
+}
+
+// sprintTaskPageSize is the number of tasks sprintTasks loads per query
+const sprintTaskPageSize = 500
+
+// sprintTasks returns the non-deleted tasks that are in a sprint
+func sprintTasks(ctx context.Context, tasks database.TaskRepository, sprint *models.Sprint) ([]*models.Task, error) {
+	filter := models.TaskFilter{SprintIDs: []int{sprint.ID}}
+
+	var result []*models.Task
+	for {
+		page, total, err := tasks.List(ctx, sprint.ProjectID, filter, sprintTaskPageSize, len(result))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, page...)
+		if len(page) == 0 || len(result) >= total {
+			return result, nil
+		}
+	}
+}
+
+// moveTasksToSprint moves tasks of a project into a sprint, or to the
+// backlog when sprintID is nil, recording the change in their history.
+// Tasks already there are skipped. It returns the IDs of the moved tasks,
+// or a client error message when a task is missing.
+func moveTasksToSprint(ctx context.Context, tx database.Tx, projectID int, taskIDs []int, sprintID *int, userID int, note string) ([]int, string, error) {
+	moved := []int{}
+	seen := make(map[int]bool, len(taskIDs))
+	for _, taskID := range taskIDs {
+		if seen[taskID] {
+			continue
+		}
+		seen[taskID] = true
+
+		task, err := tx.Tasks().GetByID(ctx, taskID)
+		if err == nil && task.ProjectID != projectID {
+			err = fmt.Errorf("task not found")
+		}
+		if err != nil {
+			if err.Error() == "task not found" {
+				return nil, fmt.Sprintf("Task %d not found", taskID), nil
+			}
+			return nil, "", err
+		}
+		if sameValue(intValue(task.SprintID), intValue(sprintID)) {
+			continue
+		}
+
+		before := cloneTask(task)
+		task.SprintID = sprintID
+		if _, err := tx.Tasks().Update(ctx, task); err != nil {
+			return nil, "", err
+		}
+		if err := recordTaskChanges(ctx, tx.TaskUpdates(), before, task, &userID, note); err != nil {
+			return nil, "", err
+		}
+		moved = append(moved, task.ID)
+	}
+
+	return moved, "", nil
+}
+
+// loadSprintParam loads the sprint named by the route after checking the
+// caller's project role. It writes the error response and returns false on
+// failure.
+func (app *Application) loadSprintParam(c *gin.Context, required string) (*models.Sprint, bool) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid project ID", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return nil, false
+	}
+
+	sprintID, err := strconv.Atoi(c.Param("sprintId"))
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid sprint ID", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return nil, false
+	}
+
+	if _, ok := app.authorizeProject(c, projectID, required); !ok {
+		return nil, false
+	}
+
+	sprint, err := app.db.Sprints().GetByID(c.Request.Context(), sprintID)
+	if err == nil && sprint.ProjectID != projectID {
+		err = fmt.Errorf("sprint not found")
+	}
+	if err != nil {
+		if err.Error() == "sprint not found" {
+			response := models.NewErrorResponse(models.ErrCodeNotFound, "Sprint not found", nil)
+			c.JSON(http.StatusNotFound, response)
+			return nil, false
+		}
+		app.logger.Printf("Error getting sprint: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to retrieve sprint", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return nil, false
+	}
+
+	return sprint, true
+}
+
+// reloadSprint fetches a sprint again to refresh its progress, falling back
+// to the given sprint on failure
+func (app *Application) reloadSprint(ctx context.Context, sprint *models.Sprint) *models.Sprint {
+	reloaded, err := app.db.Sprints().GetByID(ctx, sprint.ID)
+	if err != nil {
+		app.logger.Printf("Error getting sprint: %v", err)
+		return sprint
+	}
+	return reloaded
+}
+
+// logSprintAction writes an audit log entry for a sprint
+func (app *Application) logSprintAction(c *gin.Context, action string, sprint *models.Sprint, extra map[string]interface{}) {
+	claims := currentUser(c)
+	entityData := map[string]interface{}{"project_id": sprint.ProjectID, "name": sprint.Name, "state": sprint.State}
+	for key, value := range extra {
+		entityData[key] = value
+	}
+	if err := app.db.System().LogAction(c.Request.Context(), &claims.UserID, action, "sprint", sprint.ID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+}
+
+func (app *Application) getSprintsHandler(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid project ID", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if _, ok := app.authorizeProject(c, projectID, policy.ProjectRoleViewer); !ok {
+		return
+	}
+
+	sprints, err := app.db.Sprints().List(c.Request.Context(), projectID)
+	if err != nil {
+		app.logger.Printf("Error getting sprints: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to retrieve sprints", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := models.NewSuccessResponse(sprints, "Sprints retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) getSprintHandler(c *gin.Context) {
+	sprint, ok := app.loadSprintParam(c, policy.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+
+	response := models.NewSuccessResponse(sprint, "Sprint retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) createSprintHandler(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid project ID", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	var req models.SprintRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid request body", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	claims := currentUser(c)
+	sprint := &models.Sprint{
+		ProjectID: projectID,
+		State:     models.SprintPlanned,
+		CreatedBy: &claims.UserID,
+	}
+	if msg := applySprintRequest(sprint, &req); msg != "" {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, msg, nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if _, ok := app.authorizeProject(c, projectID, policy.ProjectRoleMaintainer); !ok {
+		return
+	}
+
+	created, err := app.db.Sprints().Create(c.Request.Context(), sprint)
+	if err != nil {
+		app.logger.Printf("Error creating sprint: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to create sprint", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	app.logSprintAction(c, "CREATE", created, nil)
+
+	response := models.NewSuccessResponse(app.reloadSprint(c.Request.Context(), created), "Sprint created successfully")
+	c.JSON(http.StatusCreated, response)
+}
+
+func (app *Application) updateSprintHandler(c *gin.Context) {
+	var req models.SprintRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid request body", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	sprint, ok := app.loadSprintParam(c, policy.ProjectRoleMaintainer)
+	if !ok {
+		return
+	}
+	if sprint.State == models.SprintClosed {
+		response := models.NewErrorResponse(models.ErrCodeConflict, "Closed sprints cannot be changed", nil)
+		c.JSON(http.StatusConflict, response)
+		return
+	}
+
+	if msg := applySprintRequest(sprint, &req); msg != "" {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, msg, nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	updated, err := app.db.Sprints().Update(c.Request.Context(), sprint)
+	if err != nil {
+		if err.Error() == "sprint not found" {
+			response := models.NewErrorResponse(models.ErrCodeNotFound, "Sprint not found", nil)
+			c.JSON(http.StatusNotFound, response)
+			return
+		}
+		app.logger.Printf("Error updating sprint: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to update sprint", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	app.logSprintAction(c, "UPDATE", updated, nil)
+
+	response := models.NewSuccessResponse(app.reloadSprint(c.Request.Context(), updated), "Sprint updated successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) deleteSprintHandler(c *gin.Context) {
+	sprint, ok := app.loadSprintParam(c, policy.ProjectRoleMaintainer)
+	if !ok {
+		return
+	}
+
+	// Started sprints are kept for the velocity history
+	if sprint.State != models.SprintPlanned {
+		response := models.NewErrorResponse(models.ErrCodeConflict, "Only planned sprints can be deleted", nil)
+		c.JSON(http.StatusConflict, response)
+		return
+	}
+
+	ctx := c.Request.Context()
+	tx, err := app.db.BeginTx(ctx)
+	if err != nil {
+		app.logger.Printf("Error starting transaction: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to delete sprint", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	defer tx.Rollback()
+
+	tasks, err := sprintTasks(ctx, tx.Tasks(), sprint)
+	if err != nil {
+		app.logger.Printf("Error getting sprint tasks: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to delete sprint", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	taskIDs := make([]int, len(tasks))
+	for i, task := range tasks {
+		taskIDs[i] = task.ID
+	}
+
+	claims := currentUser(c)
+	moved, _, err := moveTasksToSprint(ctx, tx, sprint.ProjectID, taskIDs, nil, claims.UserID, "Sprint "+sprint.Name+" deleted")
+	if err == nil {
+		err = tx.Sprints().Delete(ctx, sprint.ID)
+	}
+	if err != nil {
+		app.logger.Printf("Error deleting sprint: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to delete sprint", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		app.logger.Printf("Error committing transaction: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to delete sprint", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	app.logSprintAction(c, "DELETE", sprint, map[string]interface{}{"backlog_tasks": len(moved)})
+
+	response := models.NewSuccessResponse(nil, "Sprint deleted successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) startSprintHandler(c *gin.Context) {
+	sprint, ok := app.loadSprintParam(c, policy.ProjectRoleMaintainer)
+	if !ok {
+		return
+	}
+	if sprint.State != models.SprintPlanned {
+		response := models.NewErrorResponse(models.ErrCodeConflict, "Only planned sprints can be started", nil)
+		c.JSON(http.StatusConflict, response)
+		return
+	}
+
+	// The hours planned at the start are what the sprint committed to
+	committed := 0.0
+	if sprint.Progress != nil {
+		committed = sprint.Progress.TotalHours
+	}
+	now := time.Now()
+	sprint.State = models.SprintActive
+	sprint.CommittedHours = &committed
+	sprint.StartedAt = &now
+
+	updated, err := app.db.Sprints().Update(c.Request.Context(), sprint)
+	if err != nil {
+		if err.Error() == "active sprint already exists" {
+			response := models.NewErrorResponse(models.ErrCodeConflict, "Another sprint of this project is already active", nil)
+			c.JSON(http.StatusConflict, response)
+			return
+		}
+		app.logger.Printf("Error starting sprint: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to start sprint", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	app.logSprintAction(c, "UPDATE", updated, map[string]interface{}{"committed_hours": committed})
+
+	response := models.NewSuccessResponse(app.reloadSprint(c.Request.Context(), updated), "Sprint started successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) closeSprintHandler(c *gin.Context) {
+	// The body is optional; without it incomplete tasks go to the backlog
+	var req models.SprintCloseRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid request body", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	sprint, ok := app.loadSprintParam(c, policy.ProjectRoleMaintainer)
+	if !ok {
+		return
+	}
+	if sprint.State != models.SprintActive {
+		response := models.NewErrorResponse(models.ErrCodeConflict, "Only active sprints can be closed", nil)
+		c.JSON(http.StatusConflict, response)
+		return
+	}
+
+	ctx := c.Request.Context()
+	var carryOverTo *int
+	if req.CarryOverTo != nil {
+		if *req.CarryOverTo == sprint.ID {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, "Tasks cannot be carried over to the sprint being closed", nil)
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+		var msg string
+		var err error
+		carryOverTo, msg, err = checkTaskSprint(ctx, app.db.Sprints(), sprint.ProjectID, *req.CarryOverTo)
+		if err != nil {
+			app.logger.Printf("Error getting sprint: %v", err)
+			response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to close sprint", nil)
+			c.JSON(http.StatusInternalServerError, response)
+			return
+		}
+		if msg != "" {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, msg, nil)
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+	}
+
+	workflow, ok := app.projectWorkflow(c, sprint.ProjectID, "close sprint")
+	if !ok {
+		return
+	}
+
+	tx, err := app.db.BeginTx(ctx)
+	if err != nil {
+		app.logger.Printf("Error starting transaction: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to close sprint", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	defer tx.Rollback()
+
+	// Lock the sprint so a concurrent close waits and then sees it closed
+	state, err := tx.Sprints().LockState(ctx, sprint.ID)
+	if err != nil {
+		app.logger.Printf("Error locking sprint: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to close sprint", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	if state != models.SprintActive {
+		response := models.NewErrorResponse(models.ErrCodeConflict, "Only active sprints can be closed", nil)
+		c.JSON(http.StatusConflict, response)
+		return
+	}
+
+	tasks, err := sprintTasks(ctx, tx.Tasks(), sprint)
+	if err != nil {
+		app.logger.Printf("Error getting sprint tasks: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to close sprint", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	// Completed tasks stay with the sprint and count towards its velocity;
+	// the rest carry over
+	completedHours := 0.0
+	completedTasks := 0
+	var incomplete []int
+	for _, task := range tasks {
+		if !workflow.Completed(task.Status) {
+			incomplete = append(incomplete, task.ID)
+			continue
+		}
+		completedTasks++
+		if task.EstimatedHours != nil {
+			completedHours += *task.EstimatedHours
+		}
+	}
+	completedHours = math.Round(completedHours*100) / 100
+
+	claims := currentUser(c)
+	note := "Carried over from sprint " + sprint.Name
+	carried, _, err := moveTasksToSprint(ctx, tx, sprint.ProjectID, incomplete, carryOverTo, claims.UserID, note)
+	if err != nil {
+		app.logger.Printf("Error carrying over sprint tasks: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to close sprint", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	now := time.Now()
+	carriedOver := len(carried)
+	sprint.State = models.SprintClosed
+	sprint.CompletedHours = &completedHours
+	sprint.CompletedTasks = &completedTasks
+	sprint.CarriedOverTasks = &carriedOver
+	sprint.ClosedAt = &now
+	if _, err := tx.Sprints().Update(ctx, sprint); err != nil {
+		app.logger.Printf("Error closing sprint: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to close sprint", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		app.logger.Printf("Error committing transaction: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to close sprint", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	app.logSprintAction(c, "UPDATE", sprint, map[string]interface{}{
+		"completed_hours":    completedHours,
+		"completed_tasks":    completedTasks,
+		"carried_over_tasks": carriedOver,
+		"carry_over_to":      intValue(carryOverTo),
+	})
+
+	closeResponse := models.SprintCloseResponse{
+		Sprint:      app.reloadSprint(ctx, sprint),
+		CarriedOver: carried,
+	}
+	response := models.NewSuccessResponse(closeResponse, "Sprint closed successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// moveSprintTasks moves the tasks listed in the request body into a sprint,
+// or to the backlog when sprintID is nil, and writes the response
+func (app *Application) moveSprintTasks(c *gin.Context, projectID int, sprintID *int, note string) {
+	var req models.SprintTasksRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid request body", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	if len(req.TaskIDs) == 0 {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "No tasks provided", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	if len(req.TaskIDs) > maxImportTasks {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, fmt.Sprintf("Too many tasks (max %d)", maxImportTasks), nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	ctx := c.Request.Context()
+	tx, err := app.db.BeginTx(ctx)
+	if err != nil {
+		app.logger.Printf("Error starting transaction: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to move tasks", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	defer tx.Rollback()
+
+	claims := currentUser(c)
+	moved, msg, err := moveTasksToSprint(ctx, tx, projectID, req.TaskIDs, sprintID, claims.UserID, note)
+	if err != nil {
+		app.logger.Printf("Error moving tasks: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to move tasks", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	if msg != "" {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, msg, nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		app.logger.Printf("Error committing transaction: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to move tasks", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := models.NewSuccessResponse(models.SprintTasksResponse{SprintID: sprintID, MovedIDs: moved}, "Tasks moved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) addSprintTasksHandler(c *gin.Context) {
+	sprint, ok := app.loadSprintParam(c, policy.ProjectRoleMember)
+	if !ok {
+		return
+	}
+	if sprint.State == models.SprintClosed {
+		response := models.NewErrorResponse(models.ErrCodeConflict, "Sprint "+sprint.Name+" is closed", nil)
+		c.JSON(http.StatusConflict, response)
+		return
+	}
+
+	app.moveSprintTasks(c, sprint.ProjectID, &sprint.ID, "")
+}
+
+func (app *Application) moveToBacklogHandler(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid project ID", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if _, ok := app.authorizeProject(c, projectID, policy.ProjectRoleMember); !ok {
+		return
+	}
+
+	app.moveSprintTasks(c, projectID, nil, "")
+}
+
+func (app *Application) getVelocityHandler(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid project ID", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	var query models.VelocityQuery
+	if err := c.ShouldBindQuery(&query); err != nil || query.Limit < 1 || query.Limit > 100 {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "limit must be between 1 and 100", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if _, ok := app.authorizeProject(c, projectID, policy.ProjectRoleViewer); !ok {
+		return
+	}
+
+	sprints, err := app.db.Sprints().ListClosed(c.Request.Context(), projectID, query.Limit)
+	if err != nil {
+		app.logger.Printf("Error getting closed sprints: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to retrieve velocity", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	report := models.VelocityReport{
+		ProjectID: projectID,
+		Sprints:   make([]*models.SprintVelocity, len(sprints)),
+	}
+	total := 0.0
+	for i, sprint := range sprints {
+		entry := &models.SprintVelocity{
+			SprintID:  sprint.ID,
+			Name:      sprint.Name,
+			StartDate: sprint.StartDate,
+			EndDate:   sprint.EndDate,
+		}
+		if sprint.CommittedHours != nil {
+			entry.CommittedHours = *sprint.CommittedHours
+		}
+		if sprint.CompletedHours != nil {
+			entry.CompletedHours = *sprint.CompletedHours
+		}
+		if sprint.CompletedTasks != nil {
+			entry.CompletedTasks = *sprint.CompletedTasks
+		}
+		if sprint.CarriedOverTasks != nil {
+			entry.CarriedOverTasks = *sprint.CarriedOverTasks
+		}
+		total += entry.CompletedHours
+
+		// Sprints are listed newest first; the report runs oldest first
+		report.Sprints[len(sprints)-1-i] = entry
+	}
+	if len(sprints) > 0 {
+		report.AverageHours = math.Round(total/float64(len(sprints))*100) / 100
+	}
+
+	response := models.NewSuccessResponse(report, "Velocity retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
\ No newline at end of file
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"ai-project-backend/models"
+	"strings"
+)
+
+// validTaskPriorities lists the priorities allowed by chk_tasks_priority
+var validTaskPriorities = map[string]bool{
+	"low":    true,
+	"medium": true,
+	"high":   true,
+}
+
+// promoteTaskFields moves planning fields that older clients still send in
+// custom_fields into their own request fields. A value is only taken when
+// the request field is unset and it has the right type; the custom field is
+// then removed so the column is the only copy.
+func promoteTaskFields(req *models.TaskRequest) {
+	if req.CustomFields == nil {
+		return
+	}
+
+	if v, ok := req.CustomFields["priority"].(string); ok {
+		if req.Priority == "" {
+			req.Priority = v
+		}
+		delete(req.CustomFields, "priority")
+	}
+	if v, ok := req.CustomFields["estimated_hours"].(float64); ok {
+		if req.EstimatedHours == nil {
+			req.EstimatedHours = &v
+		}
+		delete(req.CustomFields, "estimated_hours")
+	}
+	if v, ok := req.CustomFields["actual_hours"].(float64); ok {
+		if req.ActualHours == nil {
+			req.ActualHours = &v
+		}
+		delete(req.CustomFields, "actual_hours")
+	}
+	if v, ok := req.CustomFields["progress"].(float64); ok {
+		if req.Progress == nil {
+			progress := int(v)
+			req.Progress = &progress
+		}
+		delete(req.CustomFields, "progress")
+	}
+	if v, ok := req.CustomFields["tags"].([]interface{}); ok {
+		if req.Tags == nil {
+			req.Tags = []string{}
+			for _, tag := range v {
+				if s, ok := tag.(string); ok {
+					req.Tags = append(req.Tags, s)
+				}
+			}
+		}
+		delete(req.CustomFields, "tags")
+	}
+}
+
+// validateTaskFields checks the planning fields of a request and normalizes
+// its tags. It returns an error message, or "" when the fields are valid.
+func validateTaskFields(req *models.TaskRequest) string {
+	promoteTaskFields(req)
+
+	if req.Priority != "" && !validTaskPriorities[req.Priority] {
+		return "Priority must be one of low, medium, high"
+	}
+	if req.EstimatedHours != nil && *req.EstimatedHours < 0 {
+		return "Estimated hours must not be negative"
+	}
+	if req.ActualHours != nil && *req.ActualHours < 0 {
+		return "Actual hours must not be negative"
+	}
+	if req.Progress != nil && (*req.Progress < 0 || *req.Progress > 100) {
+		return "Progress must be between 0 and 100"
+	}
+
+	if req.Tags != nil {
+		tags := []string{}
+		seen := make(map[string]bool)
+		for _, tag := range req.Tags {
+			tag = strings.TrimSpace(tag)
+			if tag == "" || seen[tag] {
+				continue
+			}
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+		req.Tags = tags
+	}
+
+	return ""
+}
+
+// applyTaskFields copies the planning fields given in a request onto a task.
+// Fields missing from the request are left unchanged.
+func applyTaskFields(task *models.Task, req *models.TaskRequest) {
+	if req.Priority != "" {
+		task.Priority = req.Priority
+	}
+	if req.EstimatedHours != nil {
+		task.EstimatedHours = req.EstimatedHours
+	}
+	if req.ActualHours != nil {
+		task.ActualHours = req.ActualHours
+	}
+	if req.Progress != nil {
+		task.Progress = *req.Progress
+	}
+	if req.Tags != nil {
+		task.Tags = req.Tags
+	}
+	if req.Metadata != nil {
+		task.Metadata = req.Metadata
+	}
+}
\ No newline at end of file
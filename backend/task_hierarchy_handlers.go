@@ -0,0 +1,352 @@
+package main
+
+import (
+	"ai-project-backend/database"
+	"ai-project-backend/models"
+	"ai-project-backend/policy"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxTaskLevel is the deepest allowed task level. Root tasks are level 0,
+// so a tree has at most three levels.
+const maxTaskLevel = 2
+
+// loadTaskParam parses the :id and :taskId parameters, checks the caller has at
+// least the required project role and loads the task, writing the error
+// response and returning false on failure
+func (app *Application) loadTaskParam(c *gin.Context, required string) (*models.Task, bool) {
+	projectIDStr := c.Param("id")
+	projectID, err := strconv.Atoi(projectIDStr)
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid project ID", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return nil, false
+	}
+
+	taskIDStr := c.Param("taskId")
+	taskID, err := strconv.Atoi(taskIDStr)
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid task ID", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return nil, false
+	}
+
+	if _, ok := app.authorizeProject(c, projectID, required); !ok {
+		return nil, false
+	}
+
+	task, err := app.db.Tasks().GetByID(c.Request.Context(), taskID)
+	if err == nil && task.ProjectID != projectID {
+		err = fmt.Errorf("task not found")
+	}
+	if err != nil {
+		if err.Error() == "task not found" {
+			response := models.NewErrorResponse(models.ErrCodeNotFound, "Task not found", nil)
+			c.JSON(http.StatusNotFound, response)
+			return nil, false
+		}
+		app.logger.Printf("Error getting task: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to retrieve task", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return nil, false
+	}
+
+	return task, true
+}
+
+// checkParentTask loads the prospective parent of a new subtask. It returns a
+// client error message when the parent is missing, belongs to another project
+// or is already at the deepest level.
+func checkParentTask(ctx context.Context, tasks database.TaskRepository, projectID, parentID int) (*models.Task, string, error) {
+	parent, err := tasks.GetByID(ctx, parentID)
+	if err != nil {
+		if err.Error() == "task not found" {
+			return nil, "Parent task not found", nil
+		}
+		return nil, "", err
+	}
+	if parent.ProjectID != projectID {
+		return nil, "Parent task not found", nil
+	}
+	if parent.Level >= maxTaskLevel {
+		return nil, fmt.Sprintf("Tasks can be nested at most %d levels deep", maxTaskLevel+1), nil
+	}
+
+	return parent, "", nil
+}
+
+// rollUpTask recomputes a parent task from its subtasks and continues with
+// its ancestors while anything changes. Progress is the share of completed
+// subtasks, as defined by Workflow.Completed. A parent moves to the first
+// done status when all of its subtasks are completed, starts when any
+// subtask has started and is reopened from that status when a subtask is
+// reopened. Parents in another done status, such as cancelled, keep it.
+// Roll-up is not bound by the workflow's transitions. Changes are recorded
+// in the task history as system updates.
+func (app *Application) rollUpTask(ctx context.Context, tasks database.TaskRepository, updates database.TaskUpdateRepository, parentID *int) error {
+	var workflow *models.Workflow
+	for parentID != nil {
+		parent, err := tasks.GetByID(ctx, *parentID)
+		if err != nil {
+			if err.Error() == "task not found" {
+				return nil
+			}
+			return err
+		}
+
+		if workflow == nil {
+			workflow, err = app.db.Workflows().Get(ctx, parent.ProjectID)
+			if err != nil {
+				return err
+			}
+		}
+		completedStatus := workflow.FirstStatus(models.StatusCategoryDone)
+		startedStatus := workflow.FirstStatus(models.StatusCategoryDoing)
+		if startedStatus == "" {
+			startedStatus = workflow.FirstStatus(models.StatusCategoryTodo)
+		}
+
+		children, err := tasks.GetChildren(ctx, parent.ID)
+		if err != nil {
+			return err
+		}
+
+		if len(children) == 0 {
+			return nil
+		}
+		var completed, started int
+		for _, child := range children {
+			switch {
+			case workflow.Completed(child.Status):
+				completed++
+			case workflow.Category(child.Status) == models.StatusCategoryDoing:
+				started++
+			}
+		}
+
+		progress := completed * 100 / len(children)
+		status := parent.Status
+		category := workflow.Category(parent.Status)
+		switch {
+		case workflow.Completed(parent.Status) && parent.Status != completedStatus:
+		case completed == len(children):
+			status = completedStatus
+		case parent.Status == completedStatus || (category == models.StatusCategoryTodo && completed+started > 0):
+			status = startedStatus
+		}
+
+		if status == parent.Status && parent.Progress == progress {
+			return nil
+		}
+
+		before := cloneTask(parent)
+		parent.Progress = progress
+		parent.Status = status
+		if _, err := tasks.Update(ctx, parent); err != nil {
+			return err
+		}
+		if err := recordTaskChanges(ctx, updates, before, parent, nil, ""); err != nil {
+			return err
+		}
+
+		parentID = parent.ParentID
+	}
+
+	return nil
+}
+
+// buildTaskTree nests a subtree returned by GetSubtree under its first task
+func buildTaskTree(tasks []*models.Task) *models.TaskTreeNode {
+	nodes := make(map[int]*models.TaskTreeNode, len(tasks))
+	var root *models.TaskTreeNode
+	for _, task := range tasks {
+		node := &models.TaskTreeNode{TaskResponse: task.ToResponse(), Children: []*models.TaskTreeNode{}}
+		nodes[task.ID] = node
+		if root == nil {
+			root = node
+			continue
+		}
+		if parent, ok := nodes[*task.ParentID]; ok {
+			parent.Children = append(parent.Children, node)
+		}
+	}
+	return root
+}
+
+// moveTask makes parentID the parent of task, or makes it a root task when
+// parentID is nil, and rolls up the old and new parents. It writes the error
+// response and returns false on failure.
+func (app *Application) moveTask(c *gin.Context, task *models.Task, parentID *int) bool {
+	tx, err := app.db.BeginTx(c.Request.Context())
+	if err != nil {
+		app.logger.Printf("Error starting transaction: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to move task", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return false
+	}
+	defer tx.Rollback()
+
+	if !app.moveTaskTx(c, tx, task, parentID) {
+		return false
+	}
+
+	if err := tx.Commit(); err != nil {
+		app.logger.Printf("Error committing transaction: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to move task", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return false
+	}
+
+	return true
+}
+
+// moveTaskTx moves a task like moveTask within the caller's transaction
+func (app *Application) moveTaskTx(c *gin.Context, tx database.Tx, task *models.Task, parentID *int) bool {
+	ctx := c.Request.Context()
+
+	subtree, err := tx.Tasks().GetSubtree(ctx, task.ID)
+	if err != nil {
+		app.logger.Printf("Error getting task subtree: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to move task", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return false
+	}
+
+	level := 0
+	if parentID != nil {
+		parent, msg, err := checkParentTask(ctx, tx.Tasks(), task.ProjectID, *parentID)
+		if err != nil {
+			app.logger.Printf("Error getting parent task: %v", err)
+			response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to move task", nil)
+			c.JSON(http.StatusInternalServerError, response)
+			return false
+		}
+		if msg != "" {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, msg, nil)
+			c.JSON(http.StatusBadRequest, response)
+			return false
+		}
+
+		// Prevent cycles
+		for _, descendant := range subtree {
+			if descendant.ID == parent.ID {
+				response := models.NewErrorResponse(models.ErrCodeBadRequest, "A task cannot be moved under itself or one of its subtasks", nil)
+				c.JSON(http.StatusBadRequest, response)
+				return false
+			}
+		}
+		level = parent.Level + 1
+	}
+
+	height := 0
+	for _, descendant := range subtree {
+		if descendant.Level-task.Level > height {
+			height = descendant.Level - task.Level
+		}
+	}
+	if level+height > maxTaskLevel {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, fmt.Sprintf("Tasks can be nested at most %d levels deep", maxTaskLevel+1), nil)
+		c.JSON(http.StatusBadRequest, response)
+		return false
+	}
+
+	oldParentID := task.ParentID
+	if err := tx.Tasks().Move(ctx, task.ID, parentID, level); err != nil {
+		app.logger.Printf("Error moving task: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to move task", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return false
+	}
+
+	for _, id := range []*int{oldParentID, parentID} {
+		if err := app.rollUpTask(ctx, tx.Tasks(), tx.TaskUpdates(), id); err != nil {
+			app.logger.Printf("Error rolling up parent task: %v", err)
+			response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to move task", nil)
+			c.JSON(http.StatusInternalServerError, response)
+			return false
+		}
+	}
+
+	task.ParentID = parentID
+	task.Level = level
+	return true
+}
+
+func (app *Application) getTaskChildrenHandler(c *gin.Context) {
+	task, ok := app.loadTaskParam(c, policy.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+
+	children, err := app.db.Tasks().GetChildren(c.Request.Context(), task.ID)
+	if err != nil {
+		app.logger.Printf("Error getting subtasks: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to retrieve subtasks", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	// Convert to response format
+	taskResponses := make([]models.TaskResponse, len(children))
+	for i, child := range children {
+		taskResponses[i] = child.ToResponse()
+	}
+
+	response := models.NewSuccessResponse(taskResponses, "Subtasks retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) getTaskTreeHandler(c *gin.Context) {
+	task, ok := app.loadTaskParam(c, policy.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+
+	subtree, err := app.db.Tasks().GetSubtree(c.Request.Context(), task.ID)
+	if err != nil {
+		if err.Error() == "task not found" {
+			response := models.NewErrorResponse(models.ErrCodeNotFound, "Task not found", nil)
+			c.JSON(http.StatusNotFound, response)
+			return
+		}
+		app.logger.Printf("Error getting task subtree: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to retrieve task tree", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := models.NewSuccessResponse(buildTaskTree(subtree), "Task tree retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) moveTaskHandler(c *gin.Context) {
+	task, ok := app.loadTaskParam(c, policy.ProjectRoleMember)
+	if !ok {
+		return
+	}
+
+	var req models.TaskParentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid request body", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	before := cloneTask(task)
+	if !app.moveTask(c, task, req.ParentID) {
+		return
+	}
+
+	claims := currentUser(c)
+	if err := recordTaskChanges(c.Request.Context(), app.db.TaskUpdates(), before, task, &claims.UserID, ""); err != nil {
+		app.logger.Printf("Error recording task update: %v", err)
+	}
+
+	response := models.NewSuccessResponse(task.ToResponse(), "Task moved successfully")
+	c.JSON(http.StatusOK, response)
+}
\ No newline at end of file
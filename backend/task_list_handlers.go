@@ -0,0 +1,288 @@
+package main
+
+import (
+	"ai-project-backend/models"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// splitValues flattens repeated and comma separated query values, dropping empty ones
+func splitValues(values []string) []string {
+	var result []string
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			part = strings.TrimSpace(part)
+			if part != "" {
+				result = append(result, part)
+			}
+		}
+	}
+	return result
+}
+
+// parseCustomFieldConditions reads the cf.<path><operator><value> conditions
+// of a raw query string. Each one is read from its whole key=value pair, so
+// that an operator such as ">=" may be split across the key and the value.
+// Pairs are split on raw commas before unescaping, so an escaped comma
+// (%2C) is part of a value. Task fields that used to be kept in custom
+// fields, such as estimated_hours, are rejected; they have their own
+// filters.
+func parseCustomFieldConditions(rawQuery string) ([]models.CustomFieldCondition, string) {
+	var conditions []models.CustomFieldCondition
+	for _, pair := range strings.Split(rawQuery, "&") {
+		parts := strings.Split(pair, ",")
+		valid := true
+		for i, part := range parts {
+			unescaped, err := url.QueryUnescape(part)
+			if err != nil {
+				valid = false
+				break
+			}
+			parts[i] = unescaped
+		}
+		expr := parts[0]
+		if !valid || !strings.HasPrefix(expr, "cf.") {
+			continue
+		}
+
+		end := strings.IndexAny(expr, "<>=!")
+		if end < 0 {
+			return nil, "Invalid custom field filter: " + strings.Join(parts, ",")
+		}
+		path, ok := models.ParseCustomFieldPath(expr[3:end])
+		if !ok {
+			return nil, "Invalid custom field name: " + expr[3:end]
+		}
+		if reservedCustomFields[path[0]] {
+			return nil, "Name is reserved for a task field: " + path[0]
+		}
+		var operator string
+		for _, op := range models.CustomFieldOperators {
+			if strings.HasPrefix(expr[end:], op) {
+				operator = op
+				break
+			}
+		}
+		if operator == "" {
+			return nil, "Invalid custom field filter: " + strings.Join(parts, ",")
+		}
+
+		condition := models.CustomFieldCondition{Path: path, Operator: operator}
+		values := append([]string{expr[end+len(operator):]}, parts[1:]...)
+		if operator == "=" || operator == "!=" {
+			condition.Values = values
+		} else {
+			value := strings.Join(values, ",")
+			if value == "" {
+				return nil, "Invalid custom field filter: " + strings.Join(parts, ",")
+			}
+			condition.Values = []string{value}
+		}
+		conditions = append(conditions, condition)
+	}
+	return conditions, ""
+}
+
+// bindTaskFilter parses and validates the task filter query parameters,
+// writing the error response and returning false on failure
+func bindTaskFilter(c *gin.Context) (models.TaskFilter, bool) {
+	var filter models.TaskFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid filter parameters", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return filter, false
+	}
+
+	filter.Status = splitValues(filter.Status)
+	for _, status := range filter.Status {
+		if !validStatusKey(status) {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid status: "+status, nil)
+			c.JSON(http.StatusBadRequest, response)
+			return filter, false
+		}
+	}
+
+	filter.Priority = splitValues(filter.Priority)
+	for _, priority := range filter.Priority {
+		if !validTaskPriorities[priority] {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid priority: "+priority, nil)
+			c.JSON(http.StatusBadRequest, response)
+			return filter, false
+		}
+	}
+
+	filter.Tag = splitValues(filter.Tag)
+
+	// assignee_id accepts user IDs, "me" and "none" for unassigned tasks
+	filter.AssigneeID = splitValues(filter.AssigneeID)
+	for _, value := range filter.AssigneeID {
+		switch value {
+		case "me":
+			filter.AssigneeIDs = append(filter.AssigneeIDs, currentUser(c).UserID)
+		case "none":
+			filter.Unassigned = true
+		default:
+			id, err := strconv.Atoi(value)
+			if err != nil {
+				response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid assignee ID: "+value, nil)
+				c.JSON(http.StatusBadRequest, response)
+				return filter, false
+			}
+			filter.AssigneeIDs = append(filter.AssigneeIDs, id)
+		}
+	}
+
+	// milestone_id accepts milestone IDs and "none" for tasks without a milestone
+	filter.MilestoneID = splitValues(filter.MilestoneID)
+	for _, value := range filter.MilestoneID {
+		if value == "none" {
+			filter.NoMilestone = true
+			continue
+		}
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid milestone ID: "+value, nil)
+			c.JSON(http.StatusBadRequest, response)
+			return filter, false
+		}
+		filter.MilestoneIDs = append(filter.MilestoneIDs, id)
+	}
+
+	// sprint_id accepts sprint IDs and "none" for backlog tasks
+	filter.SprintID = splitValues(filter.SprintID)
+	for _, value := range filter.SprintID {
+		if value == "none" {
+			filter.Backlog = true
+			continue
+		}
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid sprint ID: "+value, nil)
+			c.JSON(http.StatusBadRequest, response)
+			return filter, false
+		}
+		filter.SprintIDs = append(filter.SprintIDs, id)
+	}
+
+	if filter.DueAfter != "" {
+		dueFrom, err := time.Parse("2006-01-02", filter.DueAfter)
+		if err != nil {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid due_after date", nil)
+			c.JSON(http.StatusBadRequest, response)
+			return filter, false
+		}
+		filter.DueFrom = &dueFrom
+	}
+	if filter.DueBefore != "" {
+		dueTo, err := time.Parse("2006-01-02", filter.DueBefore)
+		if err != nil {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid due_before date", nil)
+			c.JSON(http.StatusBadRequest, response)
+			return filter, false
+		}
+		filter.DueTo = &dueTo
+	}
+
+	filter.Search = strings.TrimSpace(filter.Search)
+
+	customFields, msg := parseCustomFieldConditions(c.Request.URL.RawQuery)
+	if msg != "" {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, msg, nil)
+		c.JSON(http.StatusBadRequest, response)
+		return filter, false
+	}
+	filter.CustomFields = customFields
+
+	// Task fields are sorted by their own keys, not as custom fields
+	for _, key := range strings.Split(filter.Sort, ",") {
+		key = strings.TrimPrefix(strings.TrimSpace(key), "-")
+		if strings.HasPrefix(key, "cf.") && reservedCustomFields[strings.SplitN(key[3:], ".", 2)[0]] {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid sort key", nil)
+			c.JSON(http.StatusBadRequest, response)
+			return filter, false
+		}
+	}
+
+	return filter, true
+}
+
+func (app *Application) getMyTasksHandler(c *gin.Context) {
+	// Parse pagination parameters
+	var pagination models.PaginationParams
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid pagination parameters", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Default pagination values
+	if pagination.Page == 0 {
+		pagination.Page = 1
+	}
+	if pagination.PageSize == 0 {
+		pagination.PageSize = 20
+	}
+
+	offset := (pagination.Page - 1) * pagination.PageSize
+
+	filter, ok := bindTaskFilter(c)
+	if !ok {
+		return
+	}
+	if len(filter.AssigneeIDs) > 0 || filter.Unassigned {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "assignee_id cannot be used here", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Admins see tasks in every project, everyone else only in projects they belong to
+	admin, ok := app.adminAccess(c, "retrieve tasks")
+	if !ok {
+		return
+	}
+	claims := currentUser(c)
+	memberOnly := !admin
+
+	tasks, total, err := app.db.Tasks().ListAssigned(c.Request.Context(), claims.UserID, memberOnly, filter, pagination.PageSize, offset)
+	if err != nil {
+		if err.Error() == "invalid sort key" {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid sort key", nil)
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+		app.logger.Printf("Error getting assigned tasks: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to retrieve tasks", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	// Convert to response format
+	taskResponses := make([]models.TaskResponse, len(tasks))
+	for i, task := range tasks {
+		taskResponses[i] = task.ToResponse()
+	}
+
+	// Create pagination metadata
+	totalPages := int((int64(total) + int64(pagination.PageSize) - 1) / int64(pagination.PageSize))
+	paginationMeta := models.Pagination{
+		Page:       pagination.Page,
+		PageSize:   pagination.PageSize,
+		Total:      int64(total),
+		TotalPages: totalPages,
+		HasNext:    pagination.Page < totalPages,
+		HasPrev:    pagination.Page > 1,
+	}
+
+	paginatedResponse := models.PaginatedResponse{
+		Data:       taskResponses,
+		Pagination: paginationMeta,
+	}
+
+	response := models.NewSuccessResponse(paginatedResponse, "Tasks retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
\ No newline at end of file
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"ai-project-backend/models"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCustomFieldConditions(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []models.CustomFieldCondition
+		err   string
+	}{
+		{
+			name:  "equality",
+			query: "cf.category=backend&status=todo",
+			want:  []models.CustomFieldCondition{{Path: []string{"category"}, Operator: "=", Values: []string{"backend"}}},
+		},
+		{
+			name:  "any of several values",
+			query: "cf.category=backend,frontend",
+			want:  []models.CustomFieldCondition{{Path: []string{"category"}, Operator: "=", Values: []string{"backend", "frontend"}}},
+		},
+		{
+			name:  "escaped comma in a value",
+			query: "cf.client!=Acme%2C%20Inc.,Globex",
+			want:  []models.CustomFieldCondition{{Path: []string{"client"}, Operator: "!=", Values: []string{"Acme, Inc.", "Globex"}}},
+		},
+		{
+			name:  "operator split across key and value",
+			query: "cf.story_points>=3",
+			want:  []models.CustomFieldCondition{{Path: []string{"story_points"}, Operator: ">=", Values: []string{"3"}}},
+		},
+		{
+			name:  "escaped operator and nested path",
+			query: "cf.review.score%3C5",
+			want:  []models.CustomFieldCondition{{Path: []string{"review", "score"}, Operator: "<", Values: []string{"5"}}},
+		},
+		{
+			name:  "range value with a comma",
+			query: "cf.version>1,5",
+			want:  []models.CustomFieldCondition{{Path: []string{"version"}, Operator: ">", Values: []string{"1,5"}}},
+		},
+		{
+			name:  "task field",
+			query: "cf.estimated_hours>=16",
+			err:   "reserved for a task field",
+		},
+		{
+			name:  "nested path under a task field",
+			query: "cf.tags.first=backend",
+			err:   "reserved for a task field",
+		},
+		{
+			name:  "missing operator",
+			query: "cf.category",
+			err:   "Invalid custom field filter",
+		},
+		{
+			name:  "invalid name",
+			query: "cf.a..b=1",
+			err:   "Invalid custom field name",
+		},
+		{
+			name:  "empty range value",
+			query: "cf.story_points%3E",
+			err:   "Invalid custom field filter",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, msg := parseCustomFieldConditions(tt.query)
+			if tt.err != "" {
+				if !strings.Contains(msg, tt.err) {
+					t.Errorf("message = %q, want %q", msg, tt.err)
+				}
+				return
+			}
+			if msg != "" {
+				t.Fatalf("unexpected message %q", msg)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("conditions = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
\ No newline at end of file
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"ai-project-backend/database"
+	"ai-project-backend/models"
+	"ai-project-backend/policy"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Task update types that are not a field name
+const (
+	taskUpdateCreated = "created"
+	taskUpdateNote    = "note"
+)
+
+// cloneTask copies a task, including its custom fields, metadata and tags,
+// so it can be diffed after changes
+func cloneTask(task *models.Task) *models.Task {
+	clone := *task
+	clone.CustomFields = cloneFields(task.CustomFields)
+	clone.Metadata = cloneFields(task.Metadata)
+	if task.Tags != nil {
+		clone.Tags = append([]string{}, task.Tags...)
+	}
+	return &clone
+}
+
+// cloneFields copies a field map
+func cloneFields(fields models.CustomFields) models.CustomFields {
+	if fields == nil {
+		return nil
+	}
+	clone := make(models.CustomFields, len(fields))
+	for key, value := range fields {
+		clone[key] = value
+	}
+	return clone
+}
+
+// sameValue compares two JSON-compatible values by their encoding, so that
+// 50 and 50.0 or maps with the same entries are equal
+func sameValue(a, b interface{}) bool {
+	aJSON, errA := json.Marshal(a)
+	bJSON, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(aJSON, bJSON)
+}
+
+// intValue returns the value of an optional int, or nil
+func intValue(v *int) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+// dateValue formats an optional due date, or returns nil
+func dateValue(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.Format("2006-01-02")
+}
+
+// diffTask lists the fields that differ between two versions of a task.
+// Custom fields and metadata are compared key by key.
+func diffTask(before, after *models.Task) []*models.TaskUpdate {
+	var updates []*models.TaskUpdate
+	add := func(updateType string, oldValue, newValue interface{}) {
+		if sameValue(oldValue, newValue) {
+			return
+		}
+		updates = append(updates, &models.TaskUpdate{
+			TaskID:     after.ID,
+			UpdateType: updateType,
+			OldValue:   oldValue,
+			NewValue:   newValue,
+		})
+	}
+
+	add("title", before.Title, after.Title)
+	add("description", before.Description, after.Description)
+	add("status", before.Status, after.Status)
+	add("assignee_id", intValue(before.AssigneeID), intValue(after.AssigneeID))
+	add("due_date", dateValue(before.DueDate), dateValue(after.DueDate))
+	add("parent_id", intValue(before.ParentID), intValue(after.ParentID))
+	add("milestone_id", intValue(before.MilestoneID), intValue(after.MilestoneID))
+	add("sprint_id", intValue(before.SprintID), intValue(after.SprintID))
+	add("priority", before.Priority, after.Priority)
+	add("estimated_hours", before.EstimatedHours, after.EstimatedHours)
+	add("actual_hours", before.ActualHours, after.ActualHours)
+	add("progress", before.Progress, after.Progress)
+	add("tags", before.Tags, after.Tags)
+
+	for _, key := range fieldKeys(before.CustomFields, after.CustomFields) {
+		add("custom_fields."+key, before.CustomFields[key], after.CustomFields[key])
+	}
+	for _, key := range fieldKeys(before.Metadata, after.Metadata) {
+		add("metadata."+key, before.Metadata[key], after.Metadata[key])
+	}
+
+	return updates
+}
+
+// fieldKeys returns the sorted union of the keys of two field maps
+func fieldKeys(before, after models.CustomFields) []string {
+	keys := make(map[string]bool)
+	for key := range before {
+		keys[key] = true
+	}
+	for key := range after {
+		keys[key] = true
+	}
+	sortedKeys := make([]string, 0, len(keys))
+	for key := range keys {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+	return sortedKeys
+}
+
+// recordTaskChanges stores the differences between two versions of a task,
+// followed by the caller's note if one was given. userID is nil for system changes.
+func recordTaskChanges(ctx context.Context, updates database.TaskUpdateRepository, before, after *models.Task, userID *int, note string) error {
+	changes := diffTask(before, after)
+	if note != "" {
+		changes = append(changes, &models.TaskUpdate{
+			TaskID:     after.ID,
+			UpdateType: taskUpdateNote,
+			Notes:      &note,
+		})
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	for _, change := range changes {
+		change.UpdatedBy = userID
+	}
+	return updates.Record(ctx, changes)
+}
+
+// recordTasksCreated stores a creation entry for each new task
+func recordTasksCreated(ctx context.Context, updates database.TaskUpdateRepository, tasks []*models.Task, userID *int, note string) error {
+	changes := make([]*models.TaskUpdate, len(tasks))
+	for i, task := range tasks {
+		changes[i] = &models.TaskUpdate{
+			TaskID:     task.ID,
+			UpdateType: taskUpdateCreated,
+			NewValue: map[string]interface{}{
+				"title":     task.Title,
+				"status":    task.Status,
+				"parent_id": intValue(task.ParentID),
+			},
+			UpdatedBy: userID,
+		}
+		if note != "" {
+			changes[i].Notes = &note
+		}
+	}
+	return updates.Record(ctx, changes)
+}
+
+func (app *Application) getTaskUpdatesHandler(c *gin.Context) {
+	task, ok := app.loadTaskParam(c, policy.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+
+	// Parse pagination parameters
+	var pagination models.PaginationParams
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid pagination parameters", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Default pagination values
+	if pagination.Page == 0 {
+		pagination.Page = 1
+	}
+	if pagination.PageSize == 0 {
+		pagination.PageSize = 20
+	}
+
+	offset := (pagination.Page - 1) * pagination.PageSize
+
+	updates, total, err := app.db.TaskUpdates().ListByTask(c.Request.Context(), task.ID, pagination.PageSize, offset)
+	if err != nil {
+		app.logger.Printf("Error getting task updates: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to retrieve task updates", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	if updates == nil {
+		updates = []*models.TaskUpdate{}
+	}
+
+	// Create pagination metadata
+	totalPages := int((int64(total) + int64(pagination.PageSize) - 1) / int64(pagination.PageSize))
+	paginationMeta := models.Pagination{
+		Page:       pagination.Page,
+		PageSize:   pagination.PageSize,
+		Total:      int64(total),
+		TotalPages: totalPages,
+		HasNext:    pagination.Page < totalPages,
+		HasPrev:    pagination.Page > 1,
+	}
+
+	paginatedResponse := models.PaginatedResponse{
+		Data:       updates,
+		Pagination: paginationMeta,
+	}
+
+	response := models.NewSuccessResponse(paginatedResponse, "Task updates retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) updateTaskStatusHandler(c *gin.Context) {
+	task, ok := app.loadTaskParam(c, policy.ProjectRoleMember)
+	if !ok {
+		return
+	}
+
+	var req models.TaskStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid request body", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	workflow, ok := app.projectWorkflow(c, task.ProjectID, "update task status")
+	if !ok {
+		return
+	}
+	if !checkStatusChange(c, workflow, task.Status, req.Status) {
+		return
+	}
+	warnings, ok := app.checkBlockers(c, workflow, task, req.Status)
+	if !ok {
+		return
+	}
+
+	before := cloneTask(task)
+	if err := app.db.Tasks().UpdateStatus(c.Request.Context(), task.ID, req.Status); err != nil {
+		if err.Error() == "task not found" {
+			response := models.NewErrorResponse(models.ErrCodeNotFound, "Task not found", nil)
+			c.JSON(http.StatusNotFound, response)
+			return
+		}
+		app.logger.Printf("Error updating task status: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to update task status", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	task.Status = req.Status
+
+	claims := currentUser(c)
+	if err := recordTaskChanges(c.Request.Context(), app.db.TaskUpdates(), before, task, &claims.UserID, req.Note); err != nil {
+		app.logger.Printf("Error recording task update: %v", err)
+	}
+	if err := app.rollUpTask(c.Request.Context(), app.db.Tasks(), app.db.TaskUpdates(), task.ParentID); err != nil {
+		app.logger.Printf("Error rolling up parent task: %v", err)
+	}
+
+	response := models.NewSuccessResponse(task.ToResponse(), "Task status updated successfully")
+	response.Warnings = warnings
+	c.JSON(http.StatusOK, response)
+}
\ No newline at end of file
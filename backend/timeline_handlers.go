@@ -0,0 +1,281 @@
+package main
+
+import (
+	"ai-project-backend/models"
+	"ai-project-backend/policy"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxTimelineLimit is the largest timeline page size
+const maxTimelineLimit = 200
+
+// validTimelineEvents lists the event types accepted by the event_type filter
+var validTimelineEvents = map[string]bool{
+	models.TimelineCreated:   true,
+	models.TimelineUpdated:   true,
+	models.TimelineCompleted: true,
+	models.TimelineDeleted:   true,
+	models.TimelineRestored:  true,
+}
+
+// encodeTimelineCursor returns an opaque cursor pointing at an event
+func encodeTimelineCursor(event *models.TimelineEvent) string {
+	raw := fmt.Sprintf("%s|%s|%d", event.OccurredAt.UTC().Format(time.RFC3339Nano), event.Source, event.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeTimelineCursor parses a cursor created by encodeTimelineCursor
+func decodeTimelineCursor(cursor string) (*models.TimelineCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(string(raw), "|")
+	if len(parts) != 3 || (parts[1] != "a" && parts[1] != "u") {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+	occurredAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, err
+	}
+	id, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TimelineCursor{OccurredAt: occurredAt, Source: parts[1], ID: id}, nil
+}
+
+// parseTimelineTime accepts an RFC 3339 timestamp or a YYYY-MM-DD date in loc.
+// A date used as the end of a range covers the whole day.
+func parseTimelineTime(value string, loc *time.Location, endOfRange bool) (*time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		return nil, err
+	}
+	if endOfRange {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+// bindTimelineQuery parses the timeline query parameters into a filter,
+// writing the error response and returning false on failure
+func bindTimelineQuery(c *gin.Context) (models.TimelineFilter, *models.TimelineQuery, *time.Location, bool) {
+	var filter models.TimelineFilter
+
+	var query models.TimelineQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid timeline parameters", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return filter, nil, nil, false
+	}
+
+	if query.Limit < 1 || query.Limit > maxTimelineLimit {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, fmt.Sprintf("Limit must be between 1 and %d", maxTimelineLimit), nil)
+		c.JSON(http.StatusBadRequest, response)
+		return filter, nil, nil, false
+	}
+	filter.Limit = query.Limit
+
+	if query.GroupBy != "" && query.GroupBy != "day" {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "group_by must be day", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return filter, nil, nil, false
+	}
+
+	loc := time.UTC
+	if query.TZ != "" {
+		var err error
+		loc, err = time.LoadLocation(query.TZ)
+		if err != nil {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid time zone", nil)
+			c.JSON(http.StatusBadRequest, response)
+			return filter, nil, nil, false
+		}
+	}
+
+	// event_type may be repeated or comma separated
+	for _, value := range query.EventType {
+		for _, eventType := range strings.Split(value, ",") {
+			eventType = strings.TrimSpace(eventType)
+			if eventType == "" {
+				continue
+			}
+			if !validTimelineEvents[eventType] {
+				response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid event type: "+eventType, nil)
+				c.JSON(http.StatusBadRequest, response)
+				return filter, nil, nil, false
+			}
+			filter.EventTypes = append(filter.EventTypes, eventType)
+		}
+	}
+
+	if query.From != "" {
+		from, err := parseTimelineTime(query.From, loc, false)
+		if err != nil {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid from date", nil)
+			c.JSON(http.StatusBadRequest, response)
+			return filter, nil, nil, false
+		}
+		filter.From = from
+	}
+	if query.To != "" {
+		to, err := parseTimelineTime(query.To, loc, true)
+		if err != nil {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid to date", nil)
+			c.JSON(http.StatusBadRequest, response)
+			return filter, nil, nil, false
+		}
+		filter.To = to
+	}
+
+	if query.Cursor != "" {
+		cursor, err := decodeTimelineCursor(query.Cursor)
+		if err != nil {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid cursor", nil)
+			c.JSON(http.StatusBadRequest, response)
+			return filter, nil, nil, false
+		}
+		filter.After = cursor
+	}
+
+	return filter, &query, loc, true
+}
+
+// writeTimeline loads a page of timeline events and writes it, grouped by
+// day in loc when requested
+func (app *Application) writeTimeline(c *gin.Context, filter models.TimelineFilter, query *models.TimelineQuery, loc *time.Location) {
+	// Fetch one extra event to know whether another page exists
+	limit := filter.Limit
+	filter.Limit++
+
+	events, err := app.db.Timeline().List(c.Request.Context(), filter)
+	if err != nil {
+		app.logger.Printf("Error getting timeline: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to retrieve timeline", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	result := models.TimelineResponse{}
+	if len(events) > limit {
+		events = events[:limit]
+		result.HasMore = true
+		cursor := encodeTimelineCursor(events[len(events)-1])
+		result.NextCursor = &cursor
+	}
+
+	if query.GroupBy == "day" {
+		result.Days = []*models.TimelineDay{}
+		for _, event := range events {
+			date := event.OccurredAt.In(loc).Format("2006-01-02")
+			if n := len(result.Days); n == 0 || result.Days[n-1].Date != date {
+				result.Days = append(result.Days, &models.TimelineDay{Date: date})
+			}
+			day := result.Days[len(result.Days)-1]
+			day.Events = append(day.Events, event)
+		}
+	} else {
+		result.Events = events
+		if result.Events == nil {
+			result.Events = []*models.TimelineEvent{}
+		}
+	}
+
+	response := models.NewSuccessResponse(result, "Timeline retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) getProjectTimelineHandler(c *gin.Context) {
+	projectIDStr := c.Param("id")
+	projectID, err := strconv.Atoi(projectIDStr)
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid project ID", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	filter, query, loc, ok := bindTimelineQuery(c)
+	if !ok {
+		return
+	}
+
+	if _, ok := app.authorizeProject(c, projectID, policy.ProjectRoleViewer); !ok {
+		return
+	}
+
+	filter.ProjectID = &projectID
+	app.writeTimeline(c, filter, query, loc)
+}
+
+func (app *Application) getTaskTimelineHandler(c *gin.Context) {
+	filter, query, loc, ok := bindTimelineQuery(c)
+	if !ok {
+		return
+	}
+
+	task, ok := app.loadTaskParam(c, policy.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+
+	filter.TaskID = &task.ID
+	app.writeTimeline(c, filter, query, loc)
+}
+
+func (app *Application) getMyTimelineHandler(c *gin.Context) {
+	filter, query, loc, ok := bindTimelineQuery(c)
+	if !ok {
+		return
+	}
+
+	claims := currentUser(c)
+	filter.UserID = &claims.UserID
+
+	// Only admins see events from projects they no longer belong to
+	admin, ok := app.adminAccess(c, "retrieve timeline")
+	if !ok {
+		return
+	}
+	if !admin {
+		filter.MemberID = &claims.UserID
+	}
+	app.writeTimeline(c, filter, query, loc)
+}
+
+func (app *Application) getUserTimelineHandler(c *gin.Context) {
+	user, ok := app.loadUserParam(c)
+	if !ok {
+		return
+	}
+
+	filter, query, loc, ok := bindTimelineQuery(c)
+	if !ok {
+		return
+	}
+
+	filter.UserID = &user.ID
+
+	// Callers without global access only see events from their own projects
+	admin, ok := app.adminAccess(c, "retrieve timeline")
+	if !ok {
+		return
+	}
+	if !admin {
+		filter.MemberID = &currentUser(c).UserID
+	}
+	app.writeTimeline(c, filter, query, loc)
+}
\ No newline at end of file
@@ -0,0 +1,495 @@
+package main
+
+import (
+	"ai-project-backend/models"
+	"ai-project-backend/policy"
+	"ai-project-backend/utils"
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// mfaTokenTTL is how long a user has to enter the second factor after the password
+	mfaTokenTTL = 5 * time.Minute
+
+	// recoveryCodeCount is how many recovery codes are issued at a time
+	recoveryCodeCount = 10
+
+	// settingSecurity is the system_settings key holding models.SecuritySettings
+	settingSecurity = "security"
+)
+
+// beginLogin is called once a user's primary credentials have been verified. It
+// issues a session, or an MFA challenge when the user has two-factor enabled.
+// Exactly one of the returned responses is non-nil on success.
+func (app *Application) beginLogin(c *gin.Context, user *models.User) (*models.LoginResponse, *models.MFAChallengeResponse, error) {
+	if !user.TOTPEnabled {
+		loginResponse, _, err := app.issueSession(c, app.db.Sessions(), user, "", false)
+		return loginResponse, nil, err
+	}
+
+	token, err := app.jwtManager.GenerateMFAToken(user.ID, user.Username, user.Role, mfaTokenTTL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return nil, &models.MFAChallengeResponse{
+		MFARequired: true,
+		MFAToken:    token,
+		ExpiresAt:   time.Now().Add(mfaTokenTTL),
+	}, nil
+}
+
+// verifyTOTPCode checks a TOTP code for a user and records its time step so the
+// same code cannot be accepted twice
+func (app *Application) verifyTOTPCode(ctx context.Context, user *models.User, code string) (bool, error) {
+	if user.TOTPSecret == nil {
+		return false, nil
+	}
+
+	step, ok := utils.ValidateTOTP(*user.TOTPSecret, code, time.Now())
+	if !ok || step <= user.TOTPLastStep {
+		return false, nil
+	}
+
+	return app.db.Users().RecordTOTPStep(ctx, user.ID, step)
+}
+
+// securitySettings loads the admin-configurable security policy
+func (app *Application) securitySettings(ctx context.Context) (*models.SecuritySettings, error) {
+	settings := &models.SecuritySettings{}
+	err := app.db.System().GetSetting(ctx, settingSecurity, settings)
+	if err != nil && err.Error() != "setting not found" {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// secondFactorMissing reports whether the caller is an admin whose session did not
+// pass a second factor while the security policy requires one for admins
+func (app *Application) secondFactorMissing(ctx context.Context, claims *utils.JWTClaims) (bool, error) {
+	if claims.Role != policy.RoleAdmin || claims.MFA {
+		return false, nil
+	}
+
+	settings, err := app.securitySettings(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return settings.RequireTwoFactorForAdmins, nil
+}
+
+func (app *Application) verifyMFAHandler(c *gin.Context) {
+	var req models.MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid request body", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validate required fields
+	if req.MFAToken == "" || (req.Code == "") == (req.RecoveryCode == "") {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "mfa_token and either code or recovery_code are required", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	claims, err := app.jwtManager.ValidateToken(req.MFAToken)
+	if err != nil || claims.Purpose != utils.PurposeMFA {
+		response := models.NewErrorResponse(models.ErrCodeAuthentication, "Invalid or expired MFA token", nil)
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	revoked, err := app.db.Tokens().IsTokenRevoked(c.Request.Context(), claims.ID)
+	if err != nil {
+		app.logger.Printf("Error checking token revocation: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to verify token", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	if revoked {
+		response := models.NewErrorResponse(models.ErrCodeAuthentication, "Invalid or expired MFA token", nil)
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	user, err := app.db.Users().GetByID(c.Request.Context(), claims.UserID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			response := models.NewErrorResponse(models.ErrCodeAuthentication, "Invalid or expired MFA token", nil)
+			c.JSON(http.StatusUnauthorized, response)
+			return
+		}
+		app.logger.Printf("Error getting user: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to verify code", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	if !user.TOTPEnabled || !user.IsActive {
+		response := models.NewErrorResponse(models.ErrCodeAuthentication, "Invalid or expired MFA token", nil)
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	if !app.checkLoginAllowed(c, user.Username, user) {
+		return
+	}
+
+	method := "totp"
+	var valid bool
+	if req.Code != "" {
+		valid, err = app.verifyTOTPCode(c.Request.Context(), user, req.Code)
+	} else {
+		method = "recovery_code"
+		recoveryHash := utils.HashToken(utils.NormalizeRecoveryCode(req.RecoveryCode))
+		valid, err = app.db.Users().ConsumeRecoveryCode(c.Request.Context(), user.ID, recoveryHash)
+	}
+	if err != nil {
+		app.logger.Printf("Error verifying second factor: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to verify code", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	if !valid {
+		app.recordLoginFailure(c, user.Username, user, "invalid_"+method)
+
+		response := models.NewErrorResponse(models.ErrCodeAuthentication, "Invalid two-factor code", nil)
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	app.resetLoginFailures(c, user.Username)
+
+	// The MFA token is single use
+	if err := app.db.Tokens().RevokeToken(c.Request.Context(), claims.ID, user.ID, claims.ExpiresAt.Time); err != nil {
+		app.logger.Printf("Error revoking token: %v", err)
+	}
+
+	loginResponse, _, err := app.issueSession(c, app.db.Sessions(), user, "", true)
+	if err != nil {
+		app.logger.Printf("Error issuing session: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to generate token", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	entityData := map[string]interface{}{"mfa": method}
+	if err := app.db.System().LogAction(c.Request.Context(), &user.ID, "LOGIN", "user", user.ID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
+	response := models.NewSuccessResponse(loginResponse, "Login successful")
+	c.JSON(http.StatusOK, response)
+}
+
+// loadCurrentUser loads the caller's user record, writing the error response on failure
+func (app *Application) loadCurrentUser(c *gin.Context) (*models.User, bool) {
+	claims := currentUser(c)
+
+	user, err := app.db.Users().GetByID(c.Request.Context(), claims.UserID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			response := models.NewErrorResponse(models.ErrCodeNotFound, "User not found", nil)
+			c.JSON(http.StatusNotFound, response)
+			return nil, false
+		}
+		app.logger.Printf("Error getting user: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to retrieve user", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return nil, false
+	}
+
+	return user, true
+}
+
+// bindTOTPCode binds a models.TOTPCodeRequest and checks the code against the
+// user's enabled TOTP secret, writing the error response on failure
+func (app *Application) bindTOTPCode(c *gin.Context, user *models.User) bool {
+	var req models.TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Code == "" {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Code is required", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return false
+	}
+
+	valid, err := app.verifyTOTPCode(c.Request.Context(), user, req.Code)
+	if err != nil {
+		app.logger.Printf("Error verifying TOTP code: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to verify code", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return false
+	}
+	if !valid {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid two-factor code", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return false
+	}
+
+	return true
+}
+
+// issueRecoveryCodes generates a fresh set of recovery codes and returns them with their hashes
+func issueRecoveryCodes() ([]string, []string, error) {
+	codes, err := utils.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = utils.HashToken(code)
+	}
+
+	return codes, hashes, nil
+}
+
+func (app *Application) getTwoFactorHandler(c *gin.Context) {
+	user, ok := app.loadCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	remaining := 0
+	if user.TOTPEnabled {
+		var err error
+		remaining, err = app.db.Users().CountRecoveryCodes(c.Request.Context(), user.ID)
+		if err != nil {
+			app.logger.Printf("Error counting recovery codes: %v", err)
+			response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to retrieve two-factor status", nil)
+			c.JSON(http.StatusInternalServerError, response)
+			return
+		}
+	}
+
+	data := map[string]interface{}{
+		"enabled":                  user.TOTPEnabled,
+		"recovery_codes_remaining": remaining,
+	}
+
+	response := models.NewSuccessResponse(data, "Two-factor status retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) enrollTwoFactorHandler(c *gin.Context) {
+	user, ok := app.loadCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	if user.TOTPEnabled {
+		response := models.NewErrorResponse(models.ErrCodeConflict, "Two-factor authentication is already enabled", nil)
+		c.JSON(http.StatusConflict, response)
+		return
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		app.logger.Printf("Error generating TOTP secret: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to start enrollment", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	if err := app.db.Users().SetTOTPSecret(c.Request.Context(), user.ID, secret); err != nil {
+		app.logger.Printf("Error storing TOTP secret: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to start enrollment", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	result := models.TOTPEnrollResponse{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(app.config.App.Name, user.Username, secret),
+	}
+
+	response := models.NewSuccessResponse(result, "Scan the provisioning URI and confirm with a code to enable two-factor authentication")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) activateTwoFactorHandler(c *gin.Context) {
+	user, ok := app.loadCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	if user.TOTPEnabled {
+		response := models.NewErrorResponse(models.ErrCodeConflict, "Two-factor authentication is already enabled", nil)
+		c.JSON(http.StatusConflict, response)
+		return
+	}
+	if user.TOTPSecret == nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Start enrollment before activating two-factor authentication", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	var req models.TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Code == "" {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Code is required", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	step, valid := utils.ValidateTOTP(*user.TOTPSecret, req.Code, time.Now())
+	if !valid {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid two-factor code", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	codes, hashes, err := issueRecoveryCodes()
+	if err != nil {
+		app.logger.Printf("Error generating recovery codes: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to enable two-factor authentication", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	if err := app.db.Users().EnableTwoFactor(c.Request.Context(), user.ID, hashes, step); err != nil {
+		app.logger.Printf("Error enabling two-factor authentication: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to enable two-factor authentication", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	entityData := map[string]interface{}{"two_factor": "enabled"}
+	if err := app.db.System().LogAction(c.Request.Context(), &user.ID, "UPDATE", "user", user.ID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
+	result := models.RecoveryCodesResponse{RecoveryCodes: codes}
+	response := models.NewSuccessResponse(result, "Two-factor authentication enabled; store the recovery codes now, they will not be shown again")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) disableTwoFactorHandler(c *gin.Context) {
+	user, ok := app.loadCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	if !user.TOTPEnabled {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Two-factor authentication is not enabled", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if user.Role == policy.RoleAdmin {
+		settings, err := app.securitySettings(c.Request.Context())
+		if err != nil {
+			app.logger.Printf("Error getting security settings: %v", err)
+			response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to disable two-factor authentication", nil)
+			c.JSON(http.StatusInternalServerError, response)
+			return
+		}
+		if settings.RequireTwoFactorForAdmins {
+			response := models.NewErrorResponse(models.ErrCodeAuthorization, "Two-factor authentication is required for administrators", nil)
+			c.JSON(http.StatusForbidden, response)
+			return
+		}
+	}
+
+	if !app.bindTOTPCode(c, user) {
+		return
+	}
+
+	if err := app.db.Users().DisableTwoFactor(c.Request.Context(), user.ID); err != nil {
+		app.logger.Printf("Error disabling two-factor authentication: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to disable two-factor authentication", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	entityData := map[string]interface{}{"two_factor": "disabled"}
+	if err := app.db.System().LogAction(c.Request.Context(), &user.ID, "UPDATE", "user", user.ID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
+	response := models.NewSuccessResponse(nil, "Two-factor authentication disabled")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) regenerateRecoveryCodesHandler(c *gin.Context) {
+	user, ok := app.loadCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	if !user.TOTPEnabled {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Two-factor authentication is not enabled", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if !app.bindTOTPCode(c, user) {
+		return
+	}
+
+	codes, hashes, err := issueRecoveryCodes()
+	if err != nil {
+		app.logger.Printf("Error generating recovery codes: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to regenerate recovery codes", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	if err := app.db.Users().SetRecoveryCodes(c.Request.Context(), user.ID, hashes); err != nil {
+		app.logger.Printf("Error storing recovery codes: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to regenerate recovery codes", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	entityData := map[string]interface{}{"two_factor": "recovery_codes_regenerated"}
+	if err := app.db.System().LogAction(c.Request.Context(), &user.ID, "UPDATE", "user", user.ID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
+	result := models.RecoveryCodesResponse{RecoveryCodes: codes}
+	response := models.NewSuccessResponse(result, "Recovery codes regenerated; store them now, they will not be shown again")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) getSecuritySettingsHandler(c *gin.Context) {
+	settings, err := app.securitySettings(c.Request.Context())
+	if err != nil {
+		app.logger.Printf("Error getting security settings: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to retrieve security settings", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := models.NewSuccessResponse(settings, "Security settings retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) updateSecuritySettingsHandler(c *gin.Context) {
+	var settings models.SecuritySettings
+	if err := c.ShouldBindJSON(&settings); err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid request body", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	claims := currentUser(c)
+	if err := app.db.System().SetSetting(c.Request.Context(), settingSecurity, settings, &claims.UserID); err != nil {
+		app.logger.Printf("Error updating security settings: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to update security settings", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	entityData := map[string]interface{}{"setting": settingSecurity, "require_2fa_for_admins": settings.RequireTwoFactorForAdmins}
+	if err := app.db.System().LogAction(c.Request.Context(), &claims.UserID, "UPDATE", "system", 0, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
+	response := models.NewSuccessResponse(settings, "Security settings updated successfully")
+	c.JSON(http.StatusOK, response)
+}
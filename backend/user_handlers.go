@@ -0,0 +1,529 @@
+package main
+
+import (
+	"ai-project-backend/mail"
+	"ai-project-backend/models"
+	"ai-project-backend/policy"
+	"ai-project-backend/utils"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// minPasswordLength matches the validate tags on the password request models
+const minPasswordLength = 6
+
+// validateUsername returns a message describing why a username is invalid, or ""
+func validateUsername(username string) string {
+	if len(username) < 3 || len(username) > 50 {
+		return "Username must be between 3 and 50 characters"
+	}
+	if invalidUsernameChars.MatchString(username) {
+		return "Username may only contain letters, digits, '_', '.' and '-'"
+	}
+	return ""
+}
+
+// validatePassword returns a message describing why a password is invalid, or ""
+func validatePassword(password string) string {
+	if len(password) < minPasswordLength {
+		return "Password must be at least 6 characters"
+	}
+	if len(password) > 72 {
+		return "Password must be at most 72 characters"
+	}
+	return ""
+}
+
+// loadUserParam parses the :id parameter and loads that user, writing the error
+// response and returning false on failure
+func (app *Application) loadUserParam(c *gin.Context) (*models.User, bool) {
+	userIDStr := c.Param("id")
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid user ID", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return nil, false
+	}
+
+	user, err := app.db.Users().GetByID(c.Request.Context(), userID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			response := models.NewErrorResponse(models.ErrCodeNotFound, "User not found", nil)
+			c.JSON(http.StatusNotFound, response)
+			return nil, false
+		}
+		app.logger.Printf("Error getting user: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to retrieve user", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return nil, false
+	}
+
+	return user, true
+}
+
+// endUserSessions signs a user out everywhere by revoking their sessions and API tokens
+func (app *Application) endUserSessions(c *gin.Context, userID int) error {
+	if err := app.db.Sessions().RevokeAllForUser(c.Request.Context(), userID); err != nil {
+		return err
+	}
+	return app.db.APITokens().RevokeAllForUser(c.Request.Context(), userID)
+}
+
+// writeUserSaveError writes the response for a failed user create or update
+func (app *Application) writeUserSaveError(c *gin.Context, err error, message string) {
+	if err.Error() == "username already exists" {
+		response := models.NewErrorResponse(models.ErrCodeConflict, "Username already exists", nil)
+		c.JSON(http.StatusConflict, response)
+		return
+	}
+	if err.Error() == "email already exists" {
+		response := models.NewErrorResponse(models.ErrCodeConflict, "Email address is already in use", nil)
+		c.JSON(http.StatusConflict, response)
+		return
+	}
+	if err.Error() == "user not found" {
+		response := models.NewErrorResponse(models.ErrCodeNotFound, "User not found", nil)
+		c.JSON(http.StatusNotFound, response)
+		return
+	}
+	app.logger.Printf("Error saving user: %v", err)
+	response := models.NewErrorResponse(models.ErrCodeInternal, message, nil)
+	c.JSON(http.StatusInternalServerError, response)
+}
+
+func (app *Application) getUsersHandler(c *gin.Context) {
+	// Parse pagination parameters
+	var pagination models.PaginationParams
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid pagination parameters", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Default pagination values
+	if pagination.Page == 0 {
+		pagination.Page = 1
+	}
+	if pagination.PageSize == 0 {
+		pagination.PageSize = 20
+	}
+
+	offset := (pagination.Page - 1) * pagination.PageSize
+
+	users, total, err := app.db.Users().List(c.Request.Context(), pagination.PageSize, offset)
+	if err != nil {
+		app.logger.Printf("Error getting users: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to retrieve users", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	// Convert to response format
+	userResponses := make([]models.UserResponse, len(users))
+	for i, user := range users {
+		userResponses[i] = user.ToResponse()
+	}
+
+	// Create pagination metadata
+	totalPages := int((int64(total) + int64(pagination.PageSize) - 1) / int64(pagination.PageSize))
+	paginationMeta := models.Pagination{
+		Page:       pagination.Page,
+		PageSize:   pagination.PageSize,
+		Total:      int64(total),
+		TotalPages: totalPages,
+		HasNext:    pagination.Page < totalPages,
+		HasPrev:    pagination.Page > 1,
+	}
+
+	paginatedResponse := models.PaginatedResponse{
+		Data:       userResponses,
+		Pagination: paginationMeta,
+	}
+
+	response := models.NewSuccessResponse(paginatedResponse, "Users retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) createUserHandler(c *gin.Context) {
+	var req models.UserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid request body", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validate required fields
+	req.Username = strings.TrimSpace(req.Username)
+	if msg := validateUsername(req.Username); msg != "" {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, msg, nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	if msg := validatePassword(req.Password); msg != "" {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, msg, nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	req.Email = strings.TrimSpace(req.Email)
+	if req.Email != "" && !mail.ValidAddress(req.Email) {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid email address", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	if req.Role == "" {
+		req.Role = policy.RoleUser
+	}
+	if req.Role != policy.RoleAdmin && req.Role != policy.RoleUser {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Role must be admin or user", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	passwordHash, err := utils.HashPassword(req.Password)
+	if err != nil {
+		app.logger.Printf("Error hashing password: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to create user", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	user := &models.User{
+		Username:     req.Username,
+		PasswordHash: passwordHash,
+		Role:         req.Role,
+	}
+	if req.Email != "" {
+		user.Email = &req.Email
+	}
+
+	createdUser, err := app.db.Users().Create(c.Request.Context(), user)
+	if err != nil {
+		app.writeUserSaveError(c, err, "Failed to create user")
+		return
+	}
+
+	if createdUser.Email != nil {
+		if err := app.sendVerificationEmail(c, createdUser, *createdUser.Email); err != nil {
+			app.logger.Printf("Error sending verification email: %v", err)
+		}
+	}
+
+	claims := currentUser(c)
+	entityData := map[string]interface{}{"username": createdUser.Username, "role": createdUser.Role}
+	if err := app.db.System().LogAction(c.Request.Context(), &claims.UserID, "CREATE", "user", createdUser.ID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
+	response := models.NewSuccessResponse(createdUser.ToResponse(), "User created successfully")
+	c.JSON(http.StatusCreated, response)
+}
+
+func (app *Application) getUserHandler(c *gin.Context) {
+	user, ok := app.loadUserParam(c)
+	if !ok {
+		return
+	}
+
+	response := models.NewSuccessResponse(user.ToResponse(), "User retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) updateUserHandler(c *gin.Context) {
+	user, ok := app.loadUserParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.UserUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid request body", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validate required fields
+	req.Username = strings.TrimSpace(req.Username)
+	if msg := validateUsername(req.Username); msg != "" {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, msg, nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if req.Email != nil {
+		*req.Email = strings.TrimSpace(*req.Email)
+		if *req.Email != "" && !mail.ValidAddress(*req.Email) {
+			response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid email address", nil)
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+	}
+
+	previous := user.Username
+	user.Username = req.Username
+
+	updatedUser, err := app.db.Users().Update(c.Request.Context(), user)
+	if err != nil {
+		app.writeUserSaveError(c, err, "Failed to update user")
+		return
+	}
+
+	claims := currentUser(c)
+	entityData := map[string]interface{}{"username": updatedUser.Username, "previous_username": previous}
+
+	// A changed address must be verified again; an empty string removes it
+	if req.Email != nil && (updatedUser.Email == nil || !strings.EqualFold(*updatedUser.Email, *req.Email)) {
+		if !app.changeUserEmail(c, updatedUser, *req.Email) {
+			return
+		}
+		entityData["email"] = *req.Email
+	}
+	if err := app.db.System().LogAction(c.Request.Context(), &claims.UserID, "UPDATE", "user", updatedUser.ID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
+	response := models.NewSuccessResponse(updatedUser.ToResponse(), "User updated successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) updateUserRoleHandler(c *gin.Context) {
+	user, ok := app.loadUserParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.UserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid request body", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validate required fields
+	if req.Role != policy.RoleAdmin && req.Role != policy.RoleUser {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Role must be admin or user", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Admins cannot demote themselves, so there is always someone left to undo it
+	claims := currentUser(c)
+	if user.ID == claims.UserID && req.Role != user.Role {
+		response := models.NewErrorResponse(models.ErrCodeConflict, "You cannot change your own role", nil)
+		c.JSON(http.StatusConflict, response)
+		return
+	}
+
+	if user.Role == req.Role {
+		response := models.NewSuccessResponse(user.ToResponse(), "User role unchanged")
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	previous := user.Role
+	user.Role = req.Role
+
+	updatedUser, err := app.db.Users().Update(c.Request.Context(), user)
+	if err != nil {
+		app.writeUserSaveError(c, err, "Failed to update user role")
+		return
+	}
+
+	// The role is carried in access tokens and applies to API tokens created
+	// under the old role; end sessions and revoke API tokens so it applies now
+	if err := app.endUserSessions(c, updatedUser.ID); err != nil {
+		app.logger.Printf("Error revoking sessions: %v", err)
+	}
+
+	entityData := map[string]interface{}{"role": updatedUser.Role, "previous_role": previous}
+	if err := app.db.System().LogAction(c.Request.Context(), &claims.UserID, "UPDATE", "user", updatedUser.ID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
+	response := models.NewSuccessResponse(updatedUser.ToResponse(), "User role updated successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) resetUserPasswordHandler(c *gin.Context) {
+	user, ok := app.loadUserParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.PasswordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid request body", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validate required fields
+	if msg := validatePassword(req.Password); msg != "" {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, msg, nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	if user.AuthProvider != "local" {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Single sign-on users do not have a password", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	passwordHash, err := utils.HashPassword(req.Password)
+	if err != nil {
+		app.logger.Printf("Error hashing password: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to reset password", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	user.PasswordHash = passwordHash
+
+	if _, err := app.db.Users().Update(c.Request.Context(), user); err != nil {
+		app.writeUserSaveError(c, err, "Failed to reset password")
+		return
+	}
+
+	// A reset usually means the old password is compromised: sign the user out everywhere
+	if err := app.endUserSessions(c, user.ID); err != nil {
+		app.logger.Printf("Error revoking sessions: %v", err)
+	}
+	app.resetLoginFailures(c, user.Username)
+
+	claims := currentUser(c)
+	entityData := map[string]interface{}{"password_reset": true}
+	if err := app.db.System().LogAction(c.Request.Context(), &claims.UserID, "UPDATE", "user", user.ID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
+	response := models.NewSuccessResponse(nil, "Password reset successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) deactivateUserHandler(c *gin.Context) {
+	user, ok := app.loadUserParam(c)
+	if !ok {
+		return
+	}
+
+	claims := currentUser(c)
+	if user.ID == claims.UserID {
+		response := models.NewErrorResponse(models.ErrCodeConflict, "You cannot deactivate your own account", nil)
+		c.JSON(http.StatusConflict, response)
+		return
+	}
+
+	if err := app.db.Users().SetActive(c.Request.Context(), user.ID, false); err != nil {
+		app.writeUserSaveError(c, err, "Failed to deactivate user")
+		return
+	}
+
+	if err := app.endUserSessions(c, user.ID); err != nil {
+		app.logger.Printf("Error revoking sessions: %v", err)
+	}
+
+	entityData := map[string]interface{}{"username": user.Username, "deactivated": true}
+	if err := app.db.System().LogAction(c.Request.Context(), &claims.UserID, "DELETE", "user", user.ID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
+	response := models.NewSuccessResponse(nil, "User deactivated successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) activateUserHandler(c *gin.Context) {
+	user, ok := app.loadUserParam(c)
+	if !ok {
+		return
+	}
+
+	if err := app.db.Users().SetActive(c.Request.Context(), user.ID, true); err != nil {
+		app.writeUserSaveError(c, err, "Failed to activate user")
+		return
+	}
+
+	claims := currentUser(c)
+	entityData := map[string]interface{}{"username": user.Username, "deactivated": false}
+	if err := app.db.System().LogAction(c.Request.Context(), &claims.UserID, "RESTORE", "user", user.ID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
+	response := models.NewSuccessResponse(nil, "User activated successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) getMeHandler(c *gin.Context) {
+	user, ok := app.loadCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	response := models.NewSuccessResponse(user.ToResponse(), "Profile retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) changePasswordHandler(c *gin.Context) {
+	user, ok := app.loadCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid request body", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validate required fields
+	if user.AuthProvider != "local" {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Single sign-on users do not have a password", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	if req.CurrentPassword == "" {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Current password is required", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	if msg := validatePassword(req.NewPassword); msg != "" {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, msg, nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if !utils.CheckPassword(req.CurrentPassword, user.PasswordHash) {
+		response := models.NewErrorResponse(models.ErrCodeAuthentication, "Current password is incorrect", nil)
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	passwordHash, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		app.logger.Printf("Error hashing password: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to change password", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	user.PasswordHash = passwordHash
+
+	if _, err := app.db.Users().Update(c.Request.Context(), user); err != nil {
+		app.writeUserSaveError(c, err, "Failed to change password")
+		return
+	}
+
+	// Keep the caller signed in, but end every other session
+	claims := currentUser(c)
+	if err := app.db.Sessions().RevokeOtherFamilies(c.Request.Context(), user.ID, claims.SessionID); err != nil {
+		app.logger.Printf("Error revoking sessions: %v", err)
+	}
+
+	entityData := map[string]interface{}{"password_changed": true}
+	if err := app.db.System().LogAction(c.Request.Context(), &user.ID, "UPDATE", "user", user.ID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
+	response := models.NewSuccessResponse(nil, "Password changed successfully")
+	c.JSON(http.StatusOK, response)
+}
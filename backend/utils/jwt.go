@@ -9,12 +9,19 @@ import (
 
 // JWTClaims represents the JWT claims
 type JWTClaims struct {
-	UserID   int    `json:"user_id"`
-	Username string `json:"username"`
-	Role     string `json:"role"`
+	UserID    int    `json:"user_id"`
+	Username  string `json:"username"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid,omitempty"`
+	MFA       bool   `json:"mfa,omitempty"`
+	Purpose   string `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// PurposeMFA marks a short-lived token that only proves the password step of a
+// two-step login. It is accepted by the second-factor endpoint and nowhere else.
+const PurposeMFA = "mfa"
+
 // JWTManager manages JWT tokens
 type JWTManager struct {
 	secretKey  string
@@ -29,18 +36,46 @@ func NewJWTManager(secretKey string, expiration time.Duration) *JWTManager {
 	}
 }
 
-// GenerateToken generates a new JWT token
-func (m *JWTManager) GenerateToken(userID int, username, role string) (string, error) {
-	claims := &JWTClaims{
+// Expiration returns the lifetime of tokens issued by this manager
+func (m *JWTManager) Expiration() time.Duration {
+	return m.expiration
+}
+
+// GenerateSessionToken generates a new JWT token bound to a refresh-token session.
+// mfa records whether the login that created the session passed a second factor.
+func (m *JWTManager) GenerateSessionToken(userID int, username, role, sessionID string, mfa bool) (string, error) {
+	return m.generate(&JWTClaims{
+		UserID:    userID,
+		Username:  username,
+		Role:      role,
+		SessionID: sessionID,
+		MFA:       mfa,
+	}, m.expiration)
+}
+
+// GenerateMFAToken generates a token for completing a two-step login within ttl
+func (m *JWTManager) GenerateMFAToken(userID int, username, role string, ttl time.Duration) (string, error) {
+	return m.generate(&JWTClaims{
 		UserID:   userID,
 		Username: username,
 		Role:     role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.expiration)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-			Subject:   username,
-		},
+		Purpose:  PurposeMFA,
+	}, ttl)
+}
+
+// generate fills in the registered claims and signs the token
+func (m *JWTManager) generate(claims *JWTClaims, ttl time.Duration) (string, error) {
+	tokenID, err := GenerateRandomString(16)
+	if err != nil {
+		return "", err
+	}
+
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		NotBefore: jwt.NewNumericDate(time.Now()),
+		Subject:   claims.Username,
+		ID:        tokenID,
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -66,15 +101,4 @@ func (m *JWTManager) ValidateToken(tokenString string) (*JWTClaims, error) {
 	}
 
 	return claims, nil
-}
-
-// RefreshToken generates a new token with extended expiration
-func (m *JWTManager) RefreshToken(tokenString string) (string, error) {
-	claims, err := m.ValidateToken(tokenString)
-	if err != nil {
-		return "", err
-	}
-
-	// Generate new token with the same user info
-	return m.GenerateToken(claims.UserID, claims.Username, claims.Role)
 }
\ No newline at end of file
@@ -0,0 +1,351 @@
+package main
+
+import (
+	"ai-project-backend/models"
+	"ai-project-backend/policy"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// validStatusCategories lists the categories allowed by chk_workflow_statuses_category
+var validStatusCategories = map[string]bool{
+	models.StatusCategoryTodo:  true,
+	models.StatusCategoryDoing: true,
+	models.StatusCategoryDone:  true,
+}
+
+// maxStatusKeyLength is the length of the tasks.status column
+const maxStatusKeyLength = 20
+
+// validStatusKey reports whether a status key uses only lowercase letters,
+// digits and underscores and fits the status column
+func validStatusKey(key string) bool {
+	if key == "" || len(key) > maxStatusKeyLength {
+		return false
+	}
+	for _, r := range key {
+		if !(r >= 'a' && r <= 'z') && !(r >= '0' && r <= '9') && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// statusKeys lists the keys of a workflow's statuses in order
+func statusKeys(workflow *models.Workflow) []string {
+	keys := make([]string, len(workflow.Statuses))
+	for i, status := range workflow.Statuses {
+		keys[i] = status.Key
+	}
+	return keys
+}
+
+// projectWorkflow loads the workflow of a project, writing the error
+// response and returning false on failure
+func (app *Application) projectWorkflow(c *gin.Context, projectID int, action string) (*models.Workflow, bool) {
+	workflow, err := app.db.Workflows().Get(c.Request.Context(), projectID)
+	if err != nil {
+		app.logger.Printf("Error getting workflow: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to "+action, nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return nil, false
+	}
+	return workflow, true
+}
+
+// checkStatusChange checks that a task may move from one status to another
+// under a workflow. from is "" for new tasks, which may start in any status.
+// It writes the error response and returns false when the change is not
+// allowed.
+func checkStatusChange(c *gin.Context, workflow *models.Workflow, from, to string) bool {
+	if workflow.Status(to) == nil {
+		message := "Status must be one of " + strings.Join(statusKeys(workflow), ", ")
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, message, nil)
+		c.JSON(http.StatusBadRequest, response)
+		return false
+	}
+	if from != "" && !workflow.CanTransition(from, to) {
+		message := fmt.Sprintf("Cannot move task from %s to %s", from, to)
+		response := models.NewErrorResponse(models.ErrCodeConflict, message, nil)
+		c.JSON(http.StatusConflict, response)
+		return false
+	}
+	return true
+}
+
+// taskStats computes project task statistics from the task count of each
+// status, grouping statuses by their workflow category. Statuses missing
+// from the workflow count as todo.
+func taskStats(workflow *models.Workflow, counts map[string]int) *models.TaskStats {
+	stats := &models.TaskStats{StatusCounts: counts}
+	for status, count := range counts {
+		stats.TotalTasks += count
+		switch workflow.Category(status) {
+		case models.StatusCategoryDone:
+			stats.CompletedTasks += count
+		case models.StatusCategoryDoing:
+			stats.InProgressTasks += count
+		default:
+			stats.TodoTasks += count
+		}
+	}
+	if stats.TotalTasks > 0 {
+		stats.CompletionRate = math.Round(float64(stats.CompletedTasks)*10000/float64(stats.TotalTasks)) / 100
+	}
+	return stats
+}
+
+// buildWorkflow validates a workflow request and returns the workflow it
+// describes. An empty status list restores the default workflow.
+func buildWorkflow(projectID int, req *models.WorkflowRequest) (*models.Workflow, []models.ValidationError) {
+	if len(req.Statuses) == 0 {
+		return models.DefaultWorkflow(projectID), nil
+	}
+
+	var errs []models.ValidationError
+	workflow := &models.Workflow{ProjectID: projectID, Custom: true}
+	for i, status := range req.Statuses {
+		field := fmt.Sprintf("statuses[%d]", i)
+		if status == nil {
+			errs = append(errs, models.ValidationError{Field: field, Message: "Status is required"})
+			continue
+		}
+		key := strings.TrimSpace(status.Key)
+		name := strings.TrimSpace(status.Name)
+		if name == "" {
+			name = key
+		}
+
+		switch {
+		case !validStatusKey(key):
+			errs = append(errs, models.ValidationError{
+				Field:   field + ".key",
+				Message: fmt.Sprintf("Key must be 1-%d lowercase letters, digits or underscores", maxStatusKeyLength),
+				Value:   key,
+			})
+		case workflow.Status(key) != nil:
+			errs = append(errs, models.ValidationError{Field: field + ".key", Message: "Key is used by another status", Value: key})
+		}
+		if len(name) > 100 {
+			errs = append(errs, models.ValidationError{Field: field + ".name", Message: "Name must be at most 100 characters"})
+		}
+		if !validStatusCategories[status.Category] {
+			errs = append(errs, models.ValidationError{
+				Field:   field + ".category",
+				Message: "Category must be one of todo, doing, done",
+				Value:   status.Category,
+			})
+		}
+
+		workflow.Statuses = append(workflow.Statuses, &models.WorkflowStatus{
+			Key:      key,
+			Name:     name,
+			Category: status.Category,
+			Position: i,
+		})
+	}
+	if workflow.FirstStatus(models.StatusCategoryTodo) == "" {
+		errs = append(errs, models.ValidationError{Field: "statuses", Message: "Workflow needs a todo status for new tasks"})
+	}
+	if workflow.FirstStatus(models.StatusCategoryDone) == "" {
+		errs = append(errs, models.ValidationError{Field: "statuses", Message: "Workflow needs a done status"})
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	if req.Transitions == nil {
+		workflow.Transitions = models.AllTransitions(workflow.Statuses)
+		return workflow, nil
+	}
+
+	workflow.Transitions = []*models.WorkflowTransition{}
+	for i, transition := range req.Transitions {
+		field := fmt.Sprintf("transitions[%d]", i)
+		switch {
+		case transition == nil:
+			errs = append(errs, models.ValidationError{Field: field, Message: "Transition is required"})
+		case workflow.Status(transition.From) == nil:
+			errs = append(errs, models.ValidationError{Field: field + ".from", Message: "Unknown status", Value: transition.From})
+		case workflow.Status(transition.To) == nil:
+			errs = append(errs, models.ValidationError{Field: field + ".to", Message: "Unknown status", Value: transition.To})
+		case transition.From == transition.To || workflow.CanTransition(transition.From, transition.To):
+			// Keeping a status is always allowed, and duplicates are dropped
+		default:
+			workflow.Transitions = append(workflow.Transitions, &models.WorkflowTransition{From: transition.From, To: transition.To})
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return workflow, nil
+}
+
+// checkStatusMap checks that every status used by the project's tasks is
+// part of the new workflow or mapped to one of its statuses. It returns the
+// mappings to apply.
+func checkStatusMap(workflow *models.Workflow, used []string, statusMap map[string]string) (map[string]string, []models.ValidationError) {
+	var errs []models.ValidationError
+	remap := make(map[string]string)
+	for _, status := range used {
+		to, ok := statusMap[status]
+		if !ok || to == status {
+			if workflow.Status(status) == nil {
+				errs = append(errs, models.ValidationError{
+					Field:   "status_map." + status,
+					Message: "Tasks use this status; map it to a status of the new workflow",
+					Value:   status,
+				})
+			}
+			continue
+		}
+		if workflow.Status(to) == nil {
+			errs = append(errs, models.ValidationError{Field: "status_map." + status, Message: "Unknown status", Value: to})
+			continue
+		}
+		remap[status] = to
+	}
+
+	// Mappings are applied one after another, so a target must not move again
+	for status, to := range remap {
+		if _, ok := remap[to]; ok {
+			errs = append(errs, models.ValidationError{
+				Field:   "status_map." + status,
+				Message: "Status is mapped to a status that is itself mapped",
+				Value:   to,
+			})
+		}
+	}
+
+	return remap, errs
+}
+
+func (app *Application) getWorkflowHandler(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid project ID", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if _, ok := app.authorizeProject(c, projectID, policy.ProjectRoleViewer); !ok {
+		return
+	}
+
+	workflow, ok := app.projectWorkflow(c, projectID, "retrieve workflow")
+	if !ok {
+		return
+	}
+
+	response := models.NewSuccessResponse(workflow, "Workflow retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+func (app *Application) updateWorkflowHandler(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid project ID", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	var req models.WorkflowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := models.NewErrorResponse(models.ErrCodeBadRequest, "Invalid request body", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	workflow, errs := buildWorkflow(projectID, &req)
+	if len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, models.NewValidationErrorResponse(errs))
+		return
+	}
+
+	if _, ok := app.authorizeProject(c, projectID, policy.ProjectRoleMaintainer); !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	tx, err := app.db.BeginTx(ctx)
+	if err != nil {
+		app.logger.Printf("Error starting transaction: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to update workflow", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	defer tx.Rollback()
+
+	used, err := tx.Tasks().GetStatuses(ctx, projectID)
+	if err != nil {
+		app.logger.Printf("Error getting task statuses: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to update workflow", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	remap, errs := checkStatusMap(workflow, used, req.StatusMap)
+	if len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, models.NewValidationErrorResponse(errs))
+		return
+	}
+
+	if err := tx.Workflows().Replace(ctx, workflow); err != nil {
+		app.logger.Printf("Error replacing workflow: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to update workflow", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	// Move tasks off removed statuses and record the change in their history
+	claims := currentUser(c)
+	note := "Workflow changed"
+	var changes []*models.TaskUpdate
+	for from, to := range remap {
+		ids, err := tx.Tasks().RemapStatus(ctx, projectID, from, to)
+		if err != nil {
+			app.logger.Printf("Error remapping task status: %v", err)
+			response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to update workflow", nil)
+			c.JSON(http.StatusInternalServerError, response)
+			return
+		}
+		for _, id := range ids {
+			changes = append(changes, &models.TaskUpdate{
+				TaskID:     id,
+				UpdateType: "status",
+				OldValue:   from,
+				NewValue:   to,
+				Notes:      &note,
+				UpdatedBy:  &claims.UserID,
+			})
+		}
+	}
+	if len(changes) > 0 {
+		if err := tx.TaskUpdates().Record(ctx, changes); err != nil {
+			app.logger.Printf("Error recording task update: %v", err)
+			response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to update workflow", nil)
+			c.JSON(http.StatusInternalServerError, response)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		app.logger.Printf("Error committing transaction: %v", err)
+		response := models.NewErrorResponse(models.ErrCodeInternal, "Failed to update workflow", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	entityData := map[string]interface{}{"project_id": projectID, "statuses": statusKeys(workflow), "migrated_tasks": len(changes)}
+	if err := app.db.System().LogAction(ctx, &claims.UserID, "UPDATE", "workflow", projectID, entityData, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		app.logger.Printf("Error writing audit log: %v", err)
+	}
+
+	response := models.NewSuccessResponse(models.WorkflowUpdateResponse{Workflow: workflow, MigratedTasks: len(changes)}, "Workflow updated successfully")
+	c.JSON(http.StatusOK, response)
+}
\ No newline at end of file
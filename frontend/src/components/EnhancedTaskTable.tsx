@@ -69,7 +69,7 @@ const EnhancedTaskTable: React.FC<EnhancedTaskTableProps> = ({
     // 优先级过滤
     if (filters.priority.length > 0) {
       filtered = filtered.filter(task => {
-        const priority = task.custom_fields?.priority as string;
+        const priority = task.priority as string;
         return priority && filters.priority.includes(priority);
       });
     }
@@ -200,7 +200,7 @@ const EnhancedTaskTable: React.FC<EnhancedTaskTableProps> = ({
       key: 'priority',
       width: 100,
       render: (_, record: Task) => {
-        const priority = record.custom_fields?.priority as string;
+        const priority = record.priority as string;
         return priority ? (
           <Tag color={getPriorityColor(priority)}>
             {getPriorityText(priority)}
@@ -212,11 +212,11 @@ const EnhancedTaskTable: React.FC<EnhancedTaskTableProps> = ({
         { text: '中', value: 'medium' },
         { text: '低', value: 'low' },
       ],
-      onFilter: (value, record) => record.custom_fields?.priority === value,
+      onFilter: (value, record) => record.priority === value,
       sorter: (a, b) => {
         const priorityOrder = { high: 3, medium: 2, low: 1 };
-        const aPriority = a.custom_fields?.priority as keyof typeof priorityOrder || 'low';
-        const bPriority = b.custom_fields?.priority as keyof typeof priorityOrder || 'low';
+        const aPriority = a.priority as keyof typeof priorityOrder || 'low';
+        const bPriority = b.priority as keyof typeof priorityOrder || 'low';
         return (priorityOrder[bPriority] || 0) - (priorityOrder[aPriority] || 0);
       },
     },
@@ -255,12 +255,12 @@ const EnhancedTaskTable: React.FC<EnhancedTaskTableProps> = ({
       key: 'estimated_hours',
       width: 100,
       render: (_, record: Task) => {
-        const hours = record.custom_fields?.estimated_hours as number;
+        const hours = record.estimated_hours as number;
         return hours ? `${hours}h` : '-';
       },
       sorter: (a, b) => {
-        const aHours = a.custom_fields?.estimated_hours as number || 0;
-        const bHours = b.custom_fields?.estimated_hours as number || 0;
+        const aHours = a.estimated_hours as number || 0;
+        const bHours = b.estimated_hours as number || 0;
         return aHours - bHours;
       },
     },
@@ -269,7 +269,7 @@ const EnhancedTaskTable: React.FC<EnhancedTaskTableProps> = ({
       key: 'tags',
       width: 150,
       render: (_, record: Task) => {
-        const tags = record.custom_fields?.tags as string[];
+        const tags = record.tags as string[];
         return tags && Array.isArray(tags) ? (
           <Space wrap>
             {tags.slice(0, 2).map((tag: string, index: number) => (
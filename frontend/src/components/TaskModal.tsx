@@ -44,9 +44,9 @@ const TaskModal: React.FC<TaskModalProps> = ({
           status: task.status,
           assignee_id: task.assignee_id,
           due_date: task.due_date ? dayjs(task.due_date) : null,
-          priority: task.custom_fields?.priority || 'medium',
-          tags: task.custom_fields?.tags?.join(', ') || '',
-          estimated_hours: task.custom_fields?.estimated_hours,
+          priority: task.priority || 'medium',
+          tags: task.tags?.join(', ') || '',
+          estimated_hours: task.estimated_hours,
         });
       } else {
         // Create mode - reset form
@@ -70,11 +70,9 @@ const TaskModal: React.FC<TaskModalProps> = ({
         status: values.status,
         assignee_id: values.assignee_id || undefined,
         due_date: values.due_date ? values.due_date.format('YYYY-MM-DD') + 'T00:00:00Z' : undefined,
-        custom_fields: {
-          priority: values.priority,
-          tags: values.tags ? values.tags.split(',').map((tag: string) => tag.trim()).filter(Boolean) : [],
-          estimated_hours: values.estimated_hours || undefined,
-        },
+        priority: values.priority,
+        tags: values.tags ? values.tags.split(',').map((tag: string) => tag.trim()).filter(Boolean) : [],
+        estimated_hours: values.estimated_hours || undefined,
       };
 
       await onOk(taskRequest);
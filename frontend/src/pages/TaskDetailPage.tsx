@@ -171,9 +171,9 @@ const TaskDetailPage: React.FC = () => {
           </Descriptions.Item>
           
           <Descriptions.Item label="优先级">
-            {task.custom_fields?.priority && (
-              <Tag color={getPriorityColor(task.custom_fields.priority as string)}>
-                {getPriorityText(task.custom_fields.priority as string)}
+            {task.priority && (
+              <Tag color={getPriorityColor(task.priority as string)}>
+                {getPriorityText(task.priority as string)}
               </Tag>
             )}
           </Descriptions.Item>
@@ -187,13 +187,13 @@ const TaskDetailPage: React.FC = () => {
           </Descriptions.Item>
 
           <Descriptions.Item label="预估工时" span={2}>
-            {task.custom_fields?.estimated_hours ? `${task.custom_fields.estimated_hours} 小时` : '未设置'}
+            {task.estimated_hours ? `${task.estimated_hours} 小时` : '未设置'}
           </Descriptions.Item>
 
           <Descriptions.Item label="标签" span={2}>
-            {task.custom_fields?.tags && Array.isArray(task.custom_fields.tags) ? (
+            {task.tags && Array.isArray(task.tags) ? (
               <Space wrap>
-                {task.custom_fields.tags.map((tag: string, index: number) => (
+                {task.tags.map((tag: string, index: number) => (
                   <Tag key={index} color="blue">{tag}</Tag>
                 ))}
               </Space>
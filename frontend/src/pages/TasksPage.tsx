@@ -219,7 +219,7 @@ const TasksPage: React.FC = () => {
       title: '优先级',
       key: 'priority',
       render: (_: any, record: Task) => {
-        const priority = record.custom_fields?.priority || 'medium';
+        const priority = record.priority || 'medium';
         return (
           <Tag color={getPriorityColor(priority)}>
             {getPriorityText(priority)}
@@ -231,7 +231,7 @@ const TasksPage: React.FC = () => {
       title: '标签',
       key: 'tags',
       render: (_: any, record: Task) => {
-        const tags = record.custom_fields?.tags || [];
+        const tags = record.tags || [];
         return (
           <div>
             {tags.slice(0, 2).map((tag: string) => (
@@ -3,6 +3,7 @@ export interface Project {
   name: string;
   description?: string;
   owner_id: number;
+  strict_dependencies?: boolean;
   created_at: string;
   updated_at: string;
 }
@@ -10,6 +11,7 @@ export interface Project {
 export interface ProjectRequest {
   name: string;
   description?: string;
+  strict_dependencies?: boolean;
 }
 
 export interface ProjectResponse extends Project {
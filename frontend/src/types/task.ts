@@ -8,12 +8,24 @@ export interface Task {
   assignee_name?: string;
   due_date?: string;
   custom_fields?: Record<string, any>;
+  parent_id?: number;
+  level: number;
+  milestone_id?: number;
+  sprint_id?: number;
+  priority: TaskPriority;
+  estimated_hours?: number;
+  actual_hours?: number;
+  progress: number;
+  tags: string[];
+  metadata?: Record<string, any>;
   created_at: string;
   updated_at: string;
 }
 
 export type TaskStatus = 'todo' | 'in_progress' | 'completed' | 'cancelled';
 
+export type TaskPriority = 'low' | 'medium' | 'high';
+
 export interface TaskRequest {
   title: string;
   description?: string;
@@ -21,14 +33,32 @@ export interface TaskRequest {
   assignee_id?: number;
   due_date?: string;
   custom_fields?: Record<string, any>;
+  parent_id?: number;
+  milestone_id?: number;
+  sprint_id?: number;
+  priority?: TaskPriority;
+  estimated_hours?: number;
+  actual_hours?: number;
+  progress?: number;
+  tags?: string[];
+  metadata?: Record<string, any>;
+  note?: string;
 }
 
+// Multi-valued filters take a comma separated list
 export interface TaskFilter {
-  status?: TaskStatus;
-  assignee_id?: number;
+  status?: string;
+  assignee_id?: number | string;
+  milestone_id?: number | string;
+  sprint_id?: number | string;
   due_after?: string;
   due_before?: string;
   search?: string;
+  priority?: string;
+  tag?: string;
+  min_progress?: number;
+  max_progress?: number;
+  sort?: string;
 }
 
 export interface PaginationParams {
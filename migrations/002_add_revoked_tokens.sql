@@ -0,0 +1,13 @@
+-- Migration: Add server-side token revocation
+-- Tokens listed here are rejected until their natural expiry, which is
+-- how logout invalidates an otherwise still-valid JWT
+
+CREATE TABLE revoked_tokens (
+    jti VARCHAR(64) PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Index used when purging entries for tokens that have already expired
+CREATE INDEX idx_revoked_tokens_expires_at ON revoked_tokens(expires_at);
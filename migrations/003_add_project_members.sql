@@ -0,0 +1,26 @@
+-- Migration: Add per-project membership
+-- Members get one of four roles, ordered by privilege:
+-- owner > maintainer > member > viewer
+
+CREATE TABLE project_members (
+    project_id INTEGER NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL DEFAULT 'member',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (project_id, user_id)
+);
+
+ALTER TABLE project_members ADD CONSTRAINT chk_project_members_role
+    CHECK (role IN ('owner', 'maintainer', 'member', 'viewer'));
+
+-- Index for "projects I am a member of" lookups
+CREATE INDEX idx_project_members_user_id ON project_members(user_id);
+
+CREATE TRIGGER update_project_members_updated_at BEFORE UPDATE ON project_members
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Backfill: every existing project owner becomes an owner member
+INSERT INTO project_members (project_id, user_id, role)
+SELECT id, owner_id, 'owner' FROM projects
+ON CONFLICT (project_id, user_id) DO NOTHING;
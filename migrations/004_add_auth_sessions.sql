@@ -0,0 +1,24 @@
+-- Migration: Add refresh-token sessions
+-- Each row is one opaque refresh token, stored as a SHA-256 hash.
+-- Tokens issued by rotating one another share a family_id, which is
+-- what the API exposes as a "session". Presenting a token that was
+-- already rotated revokes the whole family.
+
+CREATE TABLE auth_sessions (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    family_id VARCHAR(64) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    replaced_by INTEGER REFERENCES auth_sessions(id) ON DELETE SET NULL,
+    ip_address INET,
+    user_agent TEXT,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_auth_sessions_user_id ON auth_sessions(user_id);
+CREATE INDEX idx_auth_sessions_family_id ON auth_sessions(family_id);
+
+-- Partial index for listing a user's live sessions
+CREATE INDEX idx_auth_sessions_active ON auth_sessions(user_id, expires_at) WHERE revoked_at IS NULL;
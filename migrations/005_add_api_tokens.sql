@@ -0,0 +1,26 @@
+-- Migration: Add personal API tokens
+-- Tokens are shown to the user once at creation; only their SHA-256
+-- hash is stored. token_prefix keeps a short, non-secret part of the
+-- token so users can tell their tokens apart.
+
+CREATE TABLE api_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    token_prefix VARCHAR(16) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    expires_at TIMESTAMPTZ NOT NULL,
+    last_used_at TIMESTAMPTZ NULL,
+    revoked_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE api_tokens ADD CONSTRAINT chk_api_tokens_name_length CHECK (LENGTH(name) >= 1);
+
+CREATE INDEX idx_api_tokens_user_id ON api_tokens(user_id);
+
+-- Token use is recorded in the audit log apart from logins
+ALTER TABLE system_audit_log DROP CONSTRAINT chk_audit_action;
+ALTER TABLE system_audit_log ADD CONSTRAINT chk_audit_action
+    CHECK (action IN ('CREATE', 'UPDATE', 'DELETE', 'RESTORE', 'LOGIN', 'LOGOUT', 'TOKEN_USE'));
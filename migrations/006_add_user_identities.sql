@@ -0,0 +1,10 @@
+-- Migration: Link users to external identity providers
+-- Users created just-in-time by single sign-on carry the provider name and
+-- the provider's stable subject identifier. They have no local password.
+
+ALTER TABLE users ADD COLUMN auth_provider VARCHAR(20) NOT NULL DEFAULT 'local';
+ALTER TABLE users ADD COLUMN external_id VARCHAR(255) NULL;
+
+ALTER TABLE users ADD CONSTRAINT chk_users_auth_provider CHECK (auth_provider IN ('local', 'oidc'));
+
+CREATE UNIQUE INDEX idx_users_external_identity ON users(auth_provider, external_id) WHERE external_id IS NOT NULL;
@@ -0,0 +1,28 @@
+-- Migration: Add TOTP two-factor authentication
+-- totp_secret is set at enrollment and only takes effect once
+-- totp_enabled is true. Recovery codes are stored as SHA-256 hashes and
+-- removed from the array when used. totp_last_step blocks replaying a
+-- code within its validity window.
+
+ALTER TABLE users ADD COLUMN totp_secret VARCHAR(64) NULL;
+ALTER TABLE users ADD COLUMN totp_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN totp_recovery_codes TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE users ADD COLUMN totp_last_step BIGINT NOT NULL DEFAULT 0;
+
+-- Sessions remember whether the login passed a second factor so that
+-- refreshed access tokens keep the same assurance level
+ALTER TABLE auth_sessions ADD COLUMN mfa_verified BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Admin-configurable system settings, one JSON document per key
+CREATE TABLE system_settings (
+    key VARCHAR(50) PRIMARY KEY,
+    value JSONB NOT NULL,
+    updated_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER update_system_settings_updated_at BEFORE UPDATE ON system_settings
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO system_settings (key, value) VALUES
+('security', '{"require_2fa_for_admins": false}');
@@ -0,0 +1,23 @@
+-- Migration: Add login brute-force protection
+-- Failed password logins are counted per username and per client IP.
+-- Counters reset after a quiet period or a successful login; locked_until
+-- is set once a counter reaches the configured threshold.
+
+CREATE TABLE login_failures (
+    scope VARCHAR(10) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    failure_count INTEGER NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    locked_until TIMESTAMPTZ NULL,
+    PRIMARY KEY (scope, subject)
+);
+
+ALTER TABLE login_failures ADD CONSTRAINT chk_login_failures_scope CHECK (scope IN ('username', 'ip'));
+
+-- Index for purging stale counters
+CREATE INDEX idx_login_failures_last_failed_at ON login_failures(last_failed_at);
+
+-- Failed logins are recorded in the audit log
+ALTER TABLE system_audit_log DROP CONSTRAINT chk_audit_action;
+ALTER TABLE system_audit_log ADD CONSTRAINT chk_audit_action
+    CHECK (action IN ('CREATE', 'UPDATE', 'DELETE', 'RESTORE', 'LOGIN', 'LOGOUT', 'TOKEN_USE', 'LOGIN_FAILED'));
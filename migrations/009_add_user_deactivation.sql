@@ -0,0 +1,9 @@
+-- Migration: Add user deactivation
+-- Users are deactivated instead of deleted so tasks keep their assignee
+-- history. Deactivated users cannot log in or use API tokens.
+
+ALTER TABLE users ADD COLUMN is_active BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE users ADD COLUMN deactivated_at TIMESTAMPTZ NULL;
+
+ALTER TABLE users ADD CONSTRAINT chk_users_deactivated_at
+    CHECK (is_active = (deactivated_at IS NULL));
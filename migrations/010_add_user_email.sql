@@ -0,0 +1,25 @@
+-- Migration: Add user email addresses and single-use user tokens
+-- Emails are unique ignoring case. user_tokens holds email verification
+-- and password reset tokens; only their SHA-256 hash is stored, and each
+-- token can be used once before it expires.
+
+ALTER TABLE users ADD COLUMN email VARCHAR(255) NULL;
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMPTZ NULL;
+
+CREATE UNIQUE INDEX idx_users_email_unique ON users(LOWER(email)) WHERE email IS NOT NULL;
+
+CREATE TABLE user_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose VARCHAR(30) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    email VARCHAR(255) NULL, -- Address being verified, for email_verification tokens
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE user_tokens ADD CONSTRAINT chk_user_tokens_purpose
+    CHECK (purpose IN ('email_verification', 'password_reset'));
+
+CREATE INDEX idx_user_tokens_user_purpose ON user_tokens(user_id, purpose);
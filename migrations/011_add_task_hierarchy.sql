@@ -0,0 +1,38 @@
+-- Migration: Add hierarchical tasks
+-- A task may have a parent task in the same project. level is the depth in
+-- the tree (0 = root task) and is maintained by the API when tasks move.
+-- Deleting a parent through the recycle bin deletes its subtree; permanently
+-- deleting it removes the subtree as well.
+
+ALTER TABLE tasks ADD COLUMN parent_id INTEGER NULL REFERENCES tasks(id) ON DELETE CASCADE;
+ALTER TABLE tasks ADD COLUMN level INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE tasks ADD CONSTRAINT chk_tasks_parent_not_self
+    CHECK (parent_id IS NULL OR parent_id <> id);
+ALTER TABLE tasks ADD CONSTRAINT chk_tasks_level
+    CHECK (level >= 0 AND (level = 0) = (parent_id IS NULL));
+
+-- Index for child lookups and subtree walks
+CREATE INDEX idx_tasks_parent_id ON tasks(parent_id);
+
+-- Expose the parent in the recycle bin
+CREATE OR REPLACE VIEW recycled_tasks AS
+SELECT
+    t.id,
+    t.project_id,
+    t.title,
+    t.description,
+    t.status,
+    t.assignee_id,
+    t.due_date,
+    t.custom_fields,
+    t.created_at,
+    t.deleted_at,
+    p.name as project_name,
+    u.username as assignee_username,
+    t.parent_id
+FROM tasks t
+LEFT JOIN projects p ON t.project_id = p.id
+LEFT JOIN users u ON t.assignee_id = u.id
+WHERE t.deleted_at IS NOT NULL
+ORDER BY t.deleted_at DESC;
@@ -0,0 +1,21 @@
+-- Migration: Add task update history
+-- Every change to a task is recorded as one row per changed field.
+-- update_type names the field: title, description, status, assignee_id,
+-- due_date, parent_id, progress or custom_fields.<key>. Two further types
+-- exist: created (task creation) and note (a free-text note sent with an
+-- update). updated_by is NULL for changes made by the system, such as a
+-- parent task rolled up from its subtasks.
+
+CREATE TABLE task_updates (
+    id SERIAL PRIMARY KEY,
+    task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    update_type VARCHAR(100) NOT NULL,
+    old_value JSONB,
+    new_value JSONB,
+    notes TEXT,
+    updated_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Index for a task's history, newest first
+CREATE INDEX idx_task_updates_task_id ON task_updates(task_id, created_at DESC);
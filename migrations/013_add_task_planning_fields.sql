@@ -0,0 +1,58 @@
+-- Migration: Add task planning columns
+-- priority, estimated/actual hours, progress and tags used to live in
+-- custom_fields JSON. They become real columns so they can be validated,
+-- filtered and sorted. Values are moved out of custom_fields when they are
+-- valid; anything else is left in custom_fields untouched.
+
+ALTER TABLE tasks ADD COLUMN priority VARCHAR(10) NOT NULL DEFAULT 'medium';
+ALTER TABLE tasks ADD COLUMN estimated_hours NUMERIC(8,2) NULL;
+ALTER TABLE tasks ADD COLUMN actual_hours NUMERIC(8,2) NULL;
+ALTER TABLE tasks ADD COLUMN progress INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE tasks ADD COLUMN metadata JSONB NULL;
+
+ALTER TABLE tasks ADD CONSTRAINT chk_tasks_priority
+    CHECK (priority IN ('low', 'medium', 'high'));
+ALTER TABLE tasks ADD CONSTRAINT chk_tasks_estimated_hours
+    CHECK (estimated_hours IS NULL OR estimated_hours >= 0);
+ALTER TABLE tasks ADD CONSTRAINT chk_tasks_actual_hours
+    CHECK (actual_hours IS NULL OR actual_hours >= 0);
+ALTER TABLE tasks ADD CONSTRAINT chk_tasks_progress
+    CHECK (progress BETWEEN 0 AND 100);
+
+-- Backfill from custom_fields without touching updated_at
+ALTER TABLE tasks DISABLE TRIGGER update_tasks_updated_at;
+
+UPDATE tasks
+SET priority = custom_fields->>'priority',
+    custom_fields = custom_fields - 'priority'
+WHERE custom_fields->>'priority' IN ('low', 'medium', 'high');
+
+UPDATE tasks
+SET estimated_hours = (custom_fields->>'estimated_hours')::numeric,
+    custom_fields = custom_fields - 'estimated_hours'
+WHERE jsonb_typeof(custom_fields->'estimated_hours') = 'number'
+  AND (custom_fields->>'estimated_hours')::numeric BETWEEN 0 AND 999999;
+
+UPDATE tasks
+SET actual_hours = (custom_fields->>'actual_hours')::numeric,
+    custom_fields = custom_fields - 'actual_hours'
+WHERE jsonb_typeof(custom_fields->'actual_hours') = 'number'
+  AND (custom_fields->>'actual_hours')::numeric BETWEEN 0 AND 999999;
+
+UPDATE tasks
+SET progress = ROUND((custom_fields->>'progress')::numeric)::int,
+    custom_fields = custom_fields - 'progress'
+WHERE jsonb_typeof(custom_fields->'progress') = 'number'
+  AND (custom_fields->>'progress')::numeric BETWEEN 0 AND 100;
+
+UPDATE tasks
+SET tags = ARRAY(SELECT jsonb_array_elements_text(custom_fields->'tags')),
+    custom_fields = custom_fields - 'tags'
+WHERE jsonb_typeof(custom_fields->'tags') = 'array';
+
+ALTER TABLE tasks ENABLE TRIGGER update_tasks_updated_at;
+
+-- Indexes for filtering
+CREATE INDEX idx_tasks_priority ON tasks(project_id, priority) WHERE deleted_at IS NULL;
+CREATE INDEX idx_tasks_tags ON tasks USING GIN (tags);
@@ -0,0 +1,27 @@
+-- Migration: Add search support for projects and tasks
+-- Titles and descriptions are written in both Chinese and English. The
+-- search_vector columns ('simple' configuration, no stemming) rank English
+-- words; trigram indexes serve substring matches, which also cover Chinese
+-- text that the full-text parser does not split into words.
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE projects ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(description, '')), 'B')
+    ) STORED;
+
+ALTER TABLE tasks ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(description, '')), 'B')
+    ) STORED;
+
+CREATE INDEX idx_projects_search_vector ON projects USING GIN (search_vector);
+CREATE INDEX idx_tasks_search_vector ON tasks USING GIN (search_vector);
+
+CREATE INDEX idx_projects_name_trgm ON projects USING GIN (name gin_trgm_ops);
+CREATE INDEX idx_projects_description_trgm ON projects USING GIN (description gin_trgm_ops);
+CREATE INDEX idx_tasks_title_trgm ON tasks USING GIN (title gin_trgm_ops);
+CREATE INDEX idx_tasks_description_trgm ON tasks USING GIN (description gin_trgm_ops);
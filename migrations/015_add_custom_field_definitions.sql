@@ -0,0 +1,28 @@
+-- Migration: Add per-project custom field definitions
+-- A definition types one key of tasks.custom_fields in a project. Keys
+-- without a definition stay untyped. enum fields list their allowed values
+-- in options; user fields hold a user ID.
+
+CREATE TABLE custom_field_definitions (
+    id SERIAL PRIMARY KEY,
+    project_id INTEGER NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    field_type VARCHAR(20) NOT NULL,
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    default_value JSONB,
+    options TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (project_id, name)
+);
+
+ALTER TABLE custom_field_definitions ADD CONSTRAINT chk_custom_field_definitions_type
+    CHECK (field_type IN ('string', 'number', 'bool', 'date', 'enum', 'user'));
+
+CREATE TRIGGER update_custom_field_definitions_updated_at BEFORE UPDATE ON custom_field_definitions
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Custom field definition changes are recorded in the audit log
+ALTER TABLE system_audit_log DROP CONSTRAINT chk_audit_entity_type;
+ALTER TABLE system_audit_log ADD CONSTRAINT chk_audit_entity_type
+    CHECK (entity_type IN ('project', 'task', 'user', 'system', 'custom_field'));
@@ -0,0 +1,101 @@
+-- Migration: Add per-project status workflows
+-- A project without workflow_statuses rows uses the default workflow:
+-- todo (todo), in_progress (doing), completed (done) and cancelled (done)
+-- with every transition allowed. A custom workflow lists its statuses, each
+-- mapped to a category, and the transitions allowed between them. Task
+-- statuses are checked against the workflow by the application, so the
+-- fixed status constraint is dropped.
+
+ALTER TABLE tasks DROP CONSTRAINT chk_tasks_status;
+
+CREATE TABLE workflow_statuses (
+    project_id INTEGER NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    key VARCHAR(20) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    category VARCHAR(10) NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (project_id, key)
+);
+
+ALTER TABLE workflow_statuses ADD CONSTRAINT chk_workflow_statuses_category
+    CHECK (category IN ('todo', 'doing', 'done'));
+
+CREATE TABLE workflow_transitions (
+    project_id INTEGER NOT NULL,
+    from_status VARCHAR(20) NOT NULL,
+    to_status VARCHAR(20) NOT NULL,
+    PRIMARY KEY (project_id, from_status, to_status),
+    FOREIGN KEY (project_id, from_status) REFERENCES workflow_statuses(project_id, key) ON DELETE CASCADE,
+    FOREIGN KEY (project_id, to_status) REFERENCES workflow_statuses(project_id, key) ON DELETE CASCADE
+);
+
+-- Category of a task status, falling back to the default workflow
+CREATE OR REPLACE FUNCTION task_status_category(p_project_id INTEGER, p_status VARCHAR)
+RETURNS VARCHAR AS $$
+    SELECT COALESCE(
+        (SELECT category FROM workflow_statuses WHERE project_id = p_project_id AND key = p_status),
+        CASE p_status
+            WHEN 'todo' THEN 'todo'
+            WHEN 'in_progress' THEN 'doing'
+            ELSE 'done'
+        END
+    )
+$$ LANGUAGE sql STABLE;
+
+-- Statistics views count by category instead of fixed statuses
+CREATE OR REPLACE VIEW project_task_stats AS
+SELECT 
+    p.id as project_id,
+    p.name as project_name,
+    p.owner_id,
+    u.username as owner_username,
+    COUNT(t.id) as total_tasks,
+    COUNT(CASE WHEN task_status_category(t.project_id, t.status) = 'done' THEN 1 END) as completed_tasks,
+    COUNT(CASE WHEN task_status_category(t.project_id, t.status) = 'doing' THEN 1 END) as in_progress_tasks,
+    COUNT(CASE WHEN task_status_category(t.project_id, t.status) = 'todo' THEN 1 END) as todo_tasks,
+    ROUND(
+        COUNT(CASE WHEN task_status_category(t.project_id, t.status) = 'done' THEN 1 END) * 100.0 / 
+        NULLIF(COUNT(t.id), 0), 2
+    ) as completion_percentage
+FROM projects p
+LEFT JOIN tasks t ON p.id = t.project_id AND t.deleted_at IS NULL
+LEFT JOIN users u ON p.owner_id = u.id
+WHERE p.deleted_at IS NULL
+GROUP BY p.id, p.name, p.owner_id, u.username;
+
+CREATE OR REPLACE VIEW user_task_assignments AS
+SELECT 
+    u.id as user_id,
+    u.username,
+    u.role,
+    COUNT(t.id) as assigned_tasks,
+    COUNT(CASE WHEN task_status_category(t.project_id, t.status) = 'done' THEN 1 END) as completed_tasks,
+    COUNT(CASE WHEN task_status_category(t.project_id, t.status) = 'doing' THEN 1 END) as in_progress_tasks,
+    COUNT(CASE WHEN task_status_category(t.project_id, t.status) = 'todo' THEN 1 END) as todo_tasks
+FROM users u
+LEFT JOIN tasks t ON u.id = t.assignee_id AND t.deleted_at IS NULL
+GROUP BY u.id, u.username, u.role;
+
+CREATE OR REPLACE VIEW overdue_tasks AS
+SELECT 
+    t.id,
+    t.title,
+    t.description,
+    t.status,
+    t.due_date,
+    t.created_at,
+    p.name as project_name,
+    u.username as assignee_username,
+    CURRENT_DATE - t.due_date as days_overdue
+FROM tasks t
+JOIN projects p ON t.project_id = p.id AND p.deleted_at IS NULL
+LEFT JOIN users u ON t.assignee_id = u.id
+WHERE t.due_date < CURRENT_DATE 
+  AND task_status_category(t.project_id, t.status) <> 'done'
+  AND t.deleted_at IS NULL
+ORDER BY t.due_date ASC;
+
+-- Workflow changes are recorded in the audit log
+ALTER TABLE system_audit_log DROP CONSTRAINT chk_audit_entity_type;
+ALTER TABLE system_audit_log ADD CONSTRAINT chk_audit_entity_type
+    CHECK (entity_type IN ('project', 'task', 'user', 'system', 'custom_field', 'workflow'));
@@ -0,0 +1,41 @@
+-- Migration: Add task dependencies
+-- A dependency makes task_id wait for depends_on_id. Finish-to-start (FS)
+-- dependencies hold a task until its blocker is done; start-to-start (SS)
+-- dependencies hold it until the blocker has started. Projects with strict
+-- dependencies reject starting a task with open blockers instead of warning.
+
+ALTER TABLE projects ADD COLUMN strict_dependencies BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE task_dependencies (
+    id SERIAL PRIMARY KEY,
+    task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    depends_on_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    dependency_type VARCHAR(2) NOT NULL DEFAULT 'FS',
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (task_id, depends_on_id)
+);
+
+ALTER TABLE task_dependencies ADD CONSTRAINT chk_task_dependencies_type
+    CHECK (dependency_type IN ('FS', 'SS'));
+ALTER TABLE task_dependencies ADD CONSTRAINT chk_task_dependencies_self
+    CHECK (task_id <> depends_on_id);
+
+CREATE INDEX idx_task_dependencies_depends_on_id ON task_dependencies(depends_on_id);
+
+-- Link the sample machine learning tasks in their natural order
+INSERT INTO task_dependencies (task_id, depends_on_id, dependency_type)
+SELECT t.id, d.id, 'FS'
+FROM (VALUES
+    ('模型架构设计', '数据收集与预处理'),
+    ('模型训练与优化', '模型架构设计'),
+    ('模型评估与验证', '模型训练与优化')
+) AS chain(task_title, depends_on_title)
+JOIN tasks t ON t.title = chain.task_title
+JOIN tasks d ON d.title = chain.depends_on_title AND d.project_id = t.project_id
+ON CONFLICT (task_id, depends_on_id) DO NOTHING;
+
+-- Dependency changes are recorded in the audit log
+ALTER TABLE system_audit_log DROP CONSTRAINT chk_audit_entity_type;
+ALTER TABLE system_audit_log ADD CONSTRAINT chk_audit_entity_type
+    CHECK (entity_type IN ('project', 'task', 'user', 'system', 'custom_field', 'workflow', 'task_dependency'));
@@ -0,0 +1,34 @@
+-- Migration: Add project milestones
+-- A milestone marks a release or delivery point of a project with a target
+-- date. Tasks may belong to one milestone of their project; deleting the
+-- milestone detaches its tasks.
+
+CREATE TABLE milestones (
+    id SERIAL PRIMARY KEY,
+    project_id INTEGER NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    target_date DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE milestones ADD CONSTRAINT chk_milestones_status
+    CHECK (status IN ('open', 'completed', 'cancelled'));
+
+CREATE INDEX idx_milestones_project_id ON milestones(project_id, target_date);
+
+CREATE TRIGGER update_milestones_updated_at BEFORE UPDATE ON milestones
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE tasks ADD COLUMN milestone_id INTEGER REFERENCES milestones(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_tasks_milestone_id ON tasks(milestone_id) WHERE milestone_id IS NOT NULL;
+
+-- Milestone changes are recorded in the audit log and shown in the timeline
+ALTER TABLE system_audit_log DROP CONSTRAINT chk_audit_entity_type;
+ALTER TABLE system_audit_log ADD CONSTRAINT chk_audit_entity_type
+    CHECK (entity_type IN ('project', 'task', 'user', 'system', 'custom_field', 'workflow', 'task_dependency',
+                           'milestone'));
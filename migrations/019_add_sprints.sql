@@ -0,0 +1,46 @@
+-- Migration: Add project sprints
+-- A sprint is a time-boxed iteration of a project that moves from planned to
+-- active to closed; a project has at most one active sprint. Tasks without a
+-- sprint form the project backlog. The hours committed when a sprint starts
+-- and the hours and tasks completed when it closes are kept on the sprint
+-- for velocity reports.
+
+CREATE TABLE sprints (
+    id SERIAL PRIMARY KEY,
+    project_id INTEGER NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    goal TEXT,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    state VARCHAR(20) NOT NULL DEFAULT 'planned',
+    committed_hours NUMERIC(10,2),
+    completed_hours NUMERIC(10,2),
+    completed_tasks INTEGER,
+    carried_over_tasks INTEGER,
+    started_at TIMESTAMPTZ,
+    closed_at TIMESTAMPTZ,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE sprints ADD CONSTRAINT chk_sprints_state
+    CHECK (state IN ('planned', 'active', 'closed'));
+ALTER TABLE sprints ADD CONSTRAINT chk_sprints_dates
+    CHECK (end_date >= start_date);
+
+CREATE INDEX idx_sprints_project_id ON sprints(project_id, start_date);
+CREATE UNIQUE INDEX idx_sprints_one_active ON sprints(project_id) WHERE state = 'active';
+
+CREATE TRIGGER update_sprints_updated_at BEFORE UPDATE ON sprints
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE tasks ADD COLUMN sprint_id INTEGER REFERENCES sprints(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_tasks_sprint_id ON tasks(sprint_id) WHERE sprint_id IS NOT NULL;
+
+-- Sprint changes are recorded in the audit log
+ALTER TABLE system_audit_log DROP CONSTRAINT chk_audit_entity_type;
+ALTER TABLE system_audit_log ADD CONSTRAINT chk_audit_entity_type
+    CHECK (entity_type IN ('project', 'task', 'user', 'system', 'custom_field', 'workflow', 'task_dependency',
+                           'milestone', 'sprint'));